@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"codebase-app/internal/adapter"
+	storage "codebase-app/internal/integration/filestorage"
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/internal/module/shop/repository"
+	"codebase-app/internal/module/shop/service"
+	"context"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RunExport writes the catalog of a shop as CSV or JSON Lines to a file or stdout,
+// streaming it the same way as the export endpoint.
+func RunExport(cmd *flag.FlagSet, args []string) {
+	var (
+		req = &entity.ExportProductsRequest{}
+		out = cmd.String("out", "", "file to write to, stdout when empty")
+	)
+
+	cmd.StringVar(&req.ShopID, "shop", "", "id of the shop to export")
+	cmd.StringVar(&req.UserID, "user", "", "id of the shop owner")
+	cmd.StringVar(&req.Format, "format", entity.ImportFormatCSV, "csv or jsonl")
+	cmd.StringVar(&req.Filter.Q, "q", "", "full-text search")
+	cmd.StringVar(&req.Filter.Kategori, "kategori", "", "category name")
+	cmd.StringVar(&req.Filter.Name, "name", "", "product name")
+	cmd.StringVar(&req.Filter.Merek, "merek", "", "brand")
+	cmd.Int64Var(&req.Filter.MinHarga, "min-harga", 0, "lowest price in minor units")
+	cmd.Int64Var(&req.Filter.MaxHarga, "max-harga", 0, "highest price in minor units")
+	cmd.IntVar(&req.Filter.Penilaian, "rating", 0, "rating")
+	cmd.StringVar(&req.Filter.Sort, "sort", "", "price_asc, price_desc, newest, rating, stock, best_match or best_selling")
+
+	if err := cmd.Parse(args); err != nil {
+		log.Fatal().Err(err).Msg("Error while parsing flags")
+	}
+
+	if req.ShopID == "" || req.UserID == "" {
+		log.Fatal().Msg("shop and user are required")
+	}
+
+	if req.Format != entity.ImportFormatCSV && req.Format != entity.ImportFormatJSONL {
+		log.Fatal().Str("format", req.Format).Msg("format must be csv or jsonl")
+	}
+
+	req.SetDefault()
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Error while creating export file")
+		}
+		defer f.Close()
+		w = f
+	}
+
+	adapter.Adapters.Sync(
+		adapter.WithShopeefunPostgres(),
+	)
+	defer func() {
+		if err := adapter.Adapters.Unsync(); err != nil {
+			log.Fatal().Err(err).Msg("Error while closing database connection")
+		}
+	}()
+
+	var (
+		repo    = repository.NewShopRepository(adapter.Adapters.ShopeefunPostgres)
+		service = service.NewShopService(repo, storage.NewFileStorageIntegration())
+	)
+
+	if err := service.ExportProducts(context.Background(), req, w); err != nil {
+		log.Error().Err(err).Msg("Error while exporting products")
+		return
+	}
+
+	log.Info().Str("shop_id", req.ShopID).Msg("Export finished")
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"codebase-app/internal/adapter"
+	storage "codebase-app/internal/integration/filestorage"
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/internal/module/shop/repository"
+	"codebase-app/internal/module/shop/service"
+	"codebase-app/pkg/validator"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RunImport imports the products of a CSV or JSON Lines file into a shop and prints the
+// per row report to stdout. It runs the same import as the product import worker.
+func RunImport(cmd *flag.FlagSet, args []string) {
+	var (
+		file   = cmd.String("file", "", "CSV or JSON Lines file to import")
+		shopID = cmd.String("shop", "", "id of the shop to import into")
+		userID = cmd.String("user", "", "id of the shop owner")
+		format = cmd.String("format", "", "csv or jsonl, taken from the file extension when empty")
+		dryRun = cmd.Bool("dry-run", false, "only validate the rows")
+	)
+
+	if err := cmd.Parse(args); err != nil {
+		log.Fatal().Err(err).Msg("Error while parsing flags")
+	}
+
+	if *file == "" || *shopID == "" || *userID == "" {
+		log.Fatal().Msg("file, shop and user are required")
+	}
+
+	if *format == "" {
+		*format = entity.ImportFormatOf(*file)
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error while opening import file")
+	}
+	defer f.Close()
+
+	adapter.Adapters.Sync(
+		adapter.WithShopeefunPostgres(),
+		adapter.WithValidator(validator.NewValidator()),
+	)
+	defer func() {
+		if err := adapter.Adapters.Unsync(); err != nil {
+			log.Fatal().Err(err).Msg("Error while closing database connection")
+		}
+	}()
+
+	var (
+		repo    = repository.NewShopRepository(adapter.Adapters.ShopeefunPostgres)
+		service = service.NewShopService(repo, storage.NewFileStorageIntegration())
+		job     = &entity.ProductImport{UserID: *userID, ShopID: *shopID, Format: *format, DryRun: *dryRun}
+	)
+
+	report, err := service.ImportProducts(context.Background(), job, f)
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Error().Err(err).Msg("Error while writing import report")
+	}
+
+	log.Info().
+		Bool("dry_run", *dryRun).
+		Int("created", report.Count(entity.ImportRowCreated)).
+		Int("updated", report.Count(entity.ImportRowUpdated)).
+		Int("failed", report.Count(entity.ImportRowFailed)).
+		Msg("Import finished")
+
+	if err != nil {
+		log.Error().Err(err).Msg("Import stopped before the end of the file")
+	}
+}
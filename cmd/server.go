@@ -4,8 +4,14 @@ import (
 	"codebase-app/internal/adapter"
 	"codebase-app/internal/infrastructure"
 	"codebase-app/internal/infrastructure/config"
+	storage "codebase-app/internal/integration/filestorage"
+	localstorage "codebase-app/internal/integration/localstorage"
+	workerFlashSale "codebase-app/internal/module/flashsale/handler/worker"
+	workerOrder "codebase-app/internal/module/order/handler/worker"
+	workerShop "codebase-app/internal/module/shop/handler/worker"
 	"codebase-app/internal/route"
 	"codebase-app/pkg/validator"
+	"context"
 	"flag"
 	"os"
 	"os/signal"
@@ -43,7 +49,10 @@ func RunServer(cmd *flag.FlagSet, args []string) {
 		SERVER_PORT = *flagAppPort
 	}
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		// leaves room for product image uploads
+		BodyLimit: 10 << 20,
+	})
 
 	// Application Middlewares
 	if envs.App.Environtment == "production" {
@@ -60,16 +69,35 @@ func RunServer(cmd *flag.FlagSet, args []string) {
 	}))
 	// End Application Middlewares
 
-	adapter.Adapters.Sync(
+	adapters := []adapter.Option{
 		adapter.WithRestServer(app),
 		adapter.WithShopeefunPostgres(),
 		adapter.WithValidator(validator.NewValidator()),
-	)
+	}
+
+	// uploaded files either go to the S3 compatible bucket or are served from local disk
+	if envs.App.StorageDriver == storage.DriverS3 {
+		adapters = append(adapters, adapter.WithDigihubStorage())
+	} else {
+		app.Static(localstorage.PublicPrefix, envs.App.LocalStoragePublicPath)
+	}
+
+	adapter.Adapters.Sync(adapters...)
 
 	infrastructure.InitializeLogger(envs.App.Environtment, envs.App.LogFile, logLevel)
 	app.Get("/metrics", monitor.New(monitor.Config{Title: config.Envs.App.Name + config.Envs.App.Environtment + " Metrics"}))
 	route.SetupRoutes(app)
 
+	// Background workers
+	ctx, stopWorkers := context.WithCancel(context.Background())
+	go workerShop.NewImageVariantWorker(envs.App.ImageWorkers).Run(ctx)
+	go workerShop.NewReservationSweeper().Run(ctx)
+	go workerShop.NewProductImportWorker().Run(ctx)
+	go workerShop.NewPublishScheduler().Run(ctx)
+	go workerFlashSale.NewClaimSweeper().Run(ctx)
+	go workerOrder.NewOrderSweeper().Run(ctx)
+	// End Background workers
+
 	// print all routes that are registered
 	// for _, route := range app.Stack() {
 	// 	for _, handler := range route {
@@ -98,6 +126,8 @@ func RunServer(cmd *flag.FlagSet, args []string) {
 	<-quit
 	log.Info().Msg("Server is shutting down ...")
 
+	stopWorkers()
+
 	err = adapter.Adapters.Unsync()
 	if err != nil {
 		log.Error().Msgf("Error while closing adapters: %v", err)
@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS product_options
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    product_id uuid NOT NULL,
+    name character varying(100) COLLATE pg_catalog."default" NOT NULL,
+    option_values text[] NOT NULL DEFAULT '{}',
+    position integer NOT NULL DEFAULT 0,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT product_options_pkey PRIMARY KEY (id),
+    CONSTRAINT product_options_product_id_name_key UNIQUE (product_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS product_variants
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    product_id uuid NOT NULL,
+    code character varying(100) COLLATE pg_catalog."default" NOT NULL,
+    options jsonb NOT NULL DEFAULT '{}',
+    harga bigint NOT NULL,
+    stok integer NOT NULL DEFAULT 0,
+    position integer NOT NULL DEFAULT 0,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    deleted_at timestamp with time zone,
+    CONSTRAINT product_variants_pkey PRIMARY KEY (id),
+    CONSTRAINT product_variants_product_id_code_key UNIQUE (product_id, code),
+    CONSTRAINT product_variants_stok_check CHECK (stok >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS product_variants_product_id_idx
+    ON product_variants (product_id)
+    WHERE deleted_at IS NULL;
+
+ALTER TABLE IF EXISTS product_options
+    ADD CONSTRAINT product_options_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS product_variants
+    ADD CONSTRAINT product_variants_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS kategori
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    product_id uuid NOT NULL,
+    name character varying(255) COLLATE pg_catalog."default" NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    deleted_at timestamp with time zone,
+    CONSTRAINT kategori_pkey PRIMARY KEY (id)
+);
+
+ALTER TABLE IF EXISTS kategori
+    ADD CONSTRAINT kategori_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+INSERT INTO kategori (product_id, name)
+SELECT pc.product_id, c.name
+FROM product_categories pc
+JOIN categories c ON c.id = pc.category_id;
+
+DROP TABLE IF EXISTS product_categories;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,66 @@
+CREATE TABLE IF NOT EXISTS categories
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    parent_id uuid,
+    name character varying(255) COLLATE pg_catalog."default" NOT NULL,
+    slug character varying(255) COLLATE pg_catalog."default" NOT NULL,
+    position integer NOT NULL DEFAULT 0,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    deleted_at timestamp with time zone,
+    CONSTRAINT categories_pkey PRIMARY KEY (id),
+    CONSTRAINT categories_slug_key UNIQUE (slug)
+);
+
+CREATE INDEX IF NOT EXISTS categories_parent_id_idx ON categories (parent_id);
+
+CREATE TABLE IF NOT EXISTS product_categories
+(
+    product_id uuid NOT NULL,
+    category_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT product_categories_pkey PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS product_categories_category_id_idx ON product_categories (category_id);
+
+ALTER TABLE IF EXISTS categories
+    ADD CONSTRAINT categories_parent_id_fkey FOREIGN KEY (parent_id)
+    REFERENCES categories (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE RESTRICT;
+
+ALTER TABLE IF EXISTS product_categories
+    ADD CONSTRAINT product_categories_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS product_categories
+    ADD CONSTRAINT product_categories_category_id_fkey FOREIGN KEY (category_id)
+    REFERENCES categories (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+-- move the free-text kategori rows into the shared taxonomy, merging names that only differ by case or punctuation
+INSERT INTO categories (name, slug)
+SELECT DISTINCT ON (slug) name, slug
+FROM (
+    SELECT
+        trim(name) AS name,
+        trim(both '-' from regexp_replace(lower(trim(name)), '[^a-z0-9]+', '-', 'g')) AS slug
+    FROM kategori
+    WHERE deleted_at IS NULL AND trim(name) <> ''
+) k
+WHERE slug <> ''
+ORDER BY slug, name
+ON CONFLICT (slug) DO NOTHING;
+
+INSERT INTO product_categories (product_id, category_id)
+SELECT k.product_id, c.id
+FROM kategori k
+JOIN categories c ON c.slug = trim(both '-' from regexp_replace(lower(trim(k.name)), '[^a-z0-9]+', '-', 'g'))
+WHERE k.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+DROP TABLE IF EXISTS kategori;
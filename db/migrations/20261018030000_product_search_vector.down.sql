@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS categories_search_vector_update ON categories;
+DROP TRIGGER IF EXISTS product_categories_search_vector_update ON product_categories;
+DROP TRIGGER IF EXISTS product_search_vector_update ON product;
+DROP FUNCTION IF EXISTS categories_search_vector_trigger();
+DROP FUNCTION IF EXISTS product_categories_search_vector_trigger();
+DROP FUNCTION IF EXISTS product_search_vector_trigger();
+DROP FUNCTION IF EXISTS product_search_document(uuid, text, text, text);
+DROP INDEX IF EXISTS product_search_vector_idx;
+ALTER TABLE IF EXISTS product DROP COLUMN IF EXISTS search_vector;
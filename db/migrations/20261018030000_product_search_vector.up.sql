@@ -0,0 +1,61 @@
+ALTER TABLE IF EXISTS product
+    ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+-- name weighs most, then merek and category names, then the description
+CREATE OR REPLACE FUNCTION product_search_document(p_id uuid, p_name text, p_description text, p_merek text)
+RETURNS tsvector AS $$
+    SELECT
+        setweight(to_tsvector('simple', coalesce(p_name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(p_merek, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce((
+            SELECT string_agg(categories.name, ' ')
+            FROM product_categories
+            JOIN categories ON categories.id = product_categories.category_id
+            WHERE product_categories.product_id = p_id AND categories.deleted_at IS NULL
+        ), '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(p_description, '')), 'C')
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION product_search_vector_trigger()
+RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := product_search_document(NEW.id, NEW.name, NEW.description, NEW.merek);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_search_vector_update
+    BEFORE INSERT OR UPDATE OF name, description, merek ON product
+    FOR EACH ROW EXECUTE FUNCTION product_search_vector_trigger();
+
+CREATE OR REPLACE FUNCTION product_categories_search_vector_trigger()
+RETURNS trigger AS $$
+BEGIN
+    UPDATE product
+    SET search_vector = product_search_document(id, name, description, merek)
+    WHERE id = COALESCE(NEW.product_id, OLD.product_id);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_categories_search_vector_update
+    AFTER INSERT OR DELETE ON product_categories
+    FOR EACH ROW EXECUTE FUNCTION product_categories_search_vector_trigger();
+
+CREATE OR REPLACE FUNCTION categories_search_vector_trigger()
+RETURNS trigger AS $$
+BEGIN
+    UPDATE product
+    SET search_vector = product_search_document(id, name, description, merek)
+    WHERE id IN (SELECT product_id FROM product_categories WHERE category_id = NEW.id);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER categories_search_vector_update
+    AFTER UPDATE OF name, deleted_at ON categories
+    FOR EACH ROW EXECUTE FUNCTION categories_search_vector_trigger();
+
+UPDATE product SET search_vector = product_search_document(id, name, description, merek);
+
+CREATE INDEX IF NOT EXISTS product_search_vector_idx ON product USING GIN (search_vector);
@@ -0,0 +1,11 @@
+ALTER TABLE IF EXISTS product_variants
+    DROP CONSTRAINT IF EXISTS product_variants_harga_check,
+    DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE IF EXISTS product
+    DROP CONSTRAINT IF EXISTS product_harga_check,
+    DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE IF EXISTS product
+    ALTER COLUMN harga TYPE text COLLATE pg_catalog."default"
+    USING harga::text;
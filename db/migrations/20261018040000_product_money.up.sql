@@ -0,0 +1,59 @@
+-- harga was free text; prices are now whole minor units with an ISO 4217 currency.
+-- Rupiah is counted in whole rupiah (exponent 0, see types.CurrencyExponent), not in
+-- the 1/100 unit ISO 4217 gives it, so cents are rounded away below.
+-- Every price so far was rupiah, written with an optional Rp or IDR prefix and dots
+-- (or commas) as thousands separators, e.g. "Rp 15.000" or "15,000". A price that
+-- still cannot be read fails the migration, to be fixed by hand, instead of being
+-- stored wrong or made free.
+CREATE OR REPLACE FUNCTION migration_parse_rupiah(p_harga text) RETURNS bigint
+LANGUAGE plpgsql IMMUTABLE AS $$
+DECLARE
+    v text := regexp_replace(btrim(p_harga), '^(rp\.?|idr)\s*', '', 'i');
+BEGIN
+    IF v ~ '^[0-9]+$' THEN
+        RETURN v::bigint;
+    ELSIF v ~ '^[0-9]{1,3}(\.[0-9]{3})+(,[0-9]{1,2})?$' THEN
+        -- 15.000 or 15.000,50
+        RETURN round(replace(replace(v, '.', ''), ',', '.')::numeric)::bigint;
+    ELSIF v ~ '^[0-9]{1,3}(,[0-9]{3})+(\.[0-9]{1,2})?$' THEN
+        -- 15,000 or 15,000.50
+        RETURN round(replace(v, ',', '')::numeric)::bigint;
+    ELSIF v ~ '^[0-9]+[.,][0-9]{1,2}$' THEN
+        -- 15000,50 or 15000.50
+        RETURN round(replace(v, ',', '.')::numeric)::bigint;
+    END IF;
+
+    RETURN NULL;
+END
+$$;
+
+DO $$
+DECLARE
+    bad record;
+BEGIN
+    SELECT id, harga INTO bad FROM product WHERE migration_parse_rupiah(harga) IS NULL LIMIT 1;
+    IF FOUND THEN
+        RAISE EXCEPTION 'product %: harga % is not a rupiah amount, fix it before migrating',
+            bad.id, quote_nullable(bad.harga);
+    END IF;
+END
+$$;
+
+ALTER TABLE IF EXISTS product
+    ALTER COLUMN harga TYPE bigint
+    USING migration_parse_rupiah(harga);
+
+DROP FUNCTION IF EXISTS migration_parse_rupiah(text);
+
+ALTER TABLE IF EXISTS product
+    ADD COLUMN IF NOT EXISTS currency character(3) NOT NULL DEFAULT 'IDR',
+    ADD CONSTRAINT product_harga_check CHECK (harga >= 0);
+
+ALTER TABLE IF EXISTS product_variants
+    ADD COLUMN IF NOT EXISTS currency character(3) NOT NULL DEFAULT 'IDR',
+    ADD CONSTRAINT product_variants_harga_check CHECK (harga >= 0);
+
+UPDATE product_variants
+SET currency = product.currency
+FROM product
+WHERE product.id = product_variants.product_id;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS product_images
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    product_id uuid NOT NULL,
+    storage_key character varying(255) COLLATE pg_catalog."default" NOT NULL,
+    url text COLLATE pg_catalog."default" NOT NULL,
+    position integer NOT NULL DEFAULT 0,
+    is_primary boolean NOT NULL DEFAULT false,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT product_images_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS product_images_product_id_idx
+    ON product_images (product_id, position);
+
+-- at most one primary image per product
+CREATE UNIQUE INDEX IF NOT EXISTS product_images_primary_key
+    ON product_images (product_id)
+    WHERE is_primary;
+
+ALTER TABLE IF EXISTS product_images
+    ADD CONSTRAINT product_images_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS product_images_variants_status_idx;
+
+ALTER TABLE IF EXISTS product_images
+    DROP CONSTRAINT IF EXISTS product_images_variants_status_check,
+    DROP COLUMN IF EXISTS variants_attempts,
+    DROP COLUMN IF EXISTS variants_status,
+    DROP COLUMN IF EXISTS variants;
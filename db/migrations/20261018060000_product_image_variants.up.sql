@@ -0,0 +1,12 @@
+-- resized copies are produced in the background; variants_status tracks the job
+-- and existing images start out pending so they get variants too
+ALTER TABLE IF EXISTS product_images
+    ADD COLUMN IF NOT EXISTS variants jsonb NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS variants_status character varying(16) NOT NULL DEFAULT 'pending',
+    ADD COLUMN IF NOT EXISTS variants_attempts integer NOT NULL DEFAULT 0,
+    ADD CONSTRAINT product_images_variants_status_check
+        CHECK (variants_status IN ('pending', 'processing', 'ready', 'failed'));
+
+CREATE INDEX IF NOT EXISTS product_images_variants_status_idx
+    ON product_images (created_at)
+    WHERE variants_status IN ('pending', 'processing');
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS stock_reservations;
+
+ALTER TABLE IF EXISTS product
+    DROP CONSTRAINT IF EXISTS product_stok_check;
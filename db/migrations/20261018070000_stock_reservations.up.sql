@@ -0,0 +1,43 @@
+-- stock can only be taken out through confirmed reservations from now on
+UPDATE product SET stok = 0 WHERE stok < 0;
+
+ALTER TABLE IF EXISTS product
+    ADD CONSTRAINT product_stok_check CHECK (stok >= 0);
+
+CREATE TABLE IF NOT EXISTS stock_reservations
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    product_id uuid NOT NULL,
+    variant_id uuid,
+    holder character varying(255) COLLATE pg_catalog."default" NOT NULL,
+    quantity integer NOT NULL,
+    status character varying(16) NOT NULL DEFAULT 'active',
+    expires_at timestamp with time zone NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT stock_reservations_pkey PRIMARY KEY (id),
+    CONSTRAINT stock_reservations_quantity_check CHECK (quantity > 0),
+    CONSTRAINT stock_reservations_status_check
+        CHECK (status IN ('active', 'confirmed', 'released', 'expired'))
+);
+
+-- a holder has at most one active hold per product or SKU, reserving again replaces it
+CREATE UNIQUE INDEX IF NOT EXISTS stock_reservations_active_holder_key
+    ON stock_reservations (product_id, (COALESCE(variant_id, '00000000-0000-0000-0000-000000000000'::uuid)), holder)
+    WHERE status = 'active';
+
+CREATE INDEX IF NOT EXISTS stock_reservations_active_expires_idx
+    ON stock_reservations (expires_at)
+    WHERE status = 'active';
+
+ALTER TABLE IF EXISTS stock_reservations
+    ADD CONSTRAINT stock_reservations_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS stock_reservations
+    ADD CONSTRAINT stock_reservations_variant_id_fkey FOREIGN KEY (variant_id)
+    REFERENCES product_variants (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
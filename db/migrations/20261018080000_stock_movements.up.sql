@@ -0,0 +1,43 @@
+-- every change of product or SKU stock is recorded here; per product (and per SKU)
+-- the sum of delta equals the current stok and balance is the stok after the change
+CREATE TABLE IF NOT EXISTS stock_movements
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    product_id uuid NOT NULL,
+    variant_id uuid,
+    delta integer NOT NULL,
+    balance integer NOT NULL,
+    reason character varying(16) NOT NULL,
+    actor character varying(255) COLLATE pg_catalog."default" NOT NULL,
+    reference character varying(255) COLLATE pg_catalog."default",
+    note text COLLATE pg_catalog."default",
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT stock_movements_pkey PRIMARY KEY (id),
+    CONSTRAINT stock_movements_delta_check CHECK (delta <> 0),
+    CONSTRAINT stock_movements_reason_check
+        CHECK (reason IN ('restock', 'sale', 'adjustment', 'return'))
+);
+
+CREATE INDEX IF NOT EXISTS stock_movements_product_id_idx
+    ON stock_movements (product_id, created_at DESC, id DESC);
+
+ALTER TABLE IF EXISTS stock_movements
+    ADD CONSTRAINT stock_movements_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+-- opening balances so the ledger adds up for existing stock
+INSERT INTO stock_movements (product_id, variant_id, delta, balance, reason, actor, note)
+SELECT product_id, id, stok, stok, 'adjustment', 'system', 'saldo awal'
+FROM product_variants
+WHERE deleted_at IS NULL AND stok <> 0;
+
+INSERT INTO stock_movements (product_id, delta, balance, reason, actor, note)
+SELECT id, stok, stok, 'adjustment', 'system', 'saldo awal'
+FROM product
+WHERE stok <> 0
+    AND NOT EXISTS (
+        SELECT 1 FROM product_variants
+        WHERE product_variants.product_id = product.id AND product_variants.deleted_at IS NULL
+    );
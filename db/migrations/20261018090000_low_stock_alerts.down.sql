@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS stock_alerts;
+
+ALTER TABLE IF EXISTS product
+    DROP CONSTRAINT IF EXISTS product_low_stock_threshold_check,
+    DROP COLUMN IF EXISTS low_stock_alerted,
+    DROP COLUMN IF EXISTS low_stock_threshold;
@@ -0,0 +1,25 @@
+-- 0 turns the alert off; low_stock_alerted remembers that the current dip was reported
+ALTER TABLE IF EXISTS product
+    ADD COLUMN IF NOT EXISTS low_stock_threshold integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS low_stock_alerted boolean NOT NULL DEFAULT false,
+    ADD CONSTRAINT product_low_stock_threshold_check CHECK (low_stock_threshold >= 0);
+
+CREATE TABLE IF NOT EXISTS stock_alerts
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    shop_id uuid NOT NULL,
+    product_id uuid NOT NULL,
+    stok integer NOT NULL,
+    threshold integer NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT stock_alerts_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS stock_alerts_shop_id_idx
+    ON stock_alerts (shop_id, created_at, id);
+
+ALTER TABLE IF EXISTS stock_alerts
+    ADD CONSTRAINT stock_alerts_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS product_imports
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    shop_id uuid NOT NULL,
+    format character varying(8) NOT NULL,
+    dry_run boolean NOT NULL DEFAULT false,
+    status character varying(16) NOT NULL DEFAULT 'pending',
+    -- the uploaded file, dropped once the import has run
+    source bytea,
+    total_rows integer NOT NULL DEFAULT 0,
+    created_rows integer NOT NULL DEFAULT 0,
+    updated_rows integer NOT NULL DEFAULT 0,
+    failed_rows integer NOT NULL DEFAULT 0,
+    report jsonb NOT NULL DEFAULT '[]',
+    error text,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    finished_at timestamp with time zone,
+    CONSTRAINT product_imports_pkey PRIMARY KEY (id),
+    CONSTRAINT product_imports_format_check CHECK (format IN ('csv', 'jsonl')),
+    CONSTRAINT product_imports_status_check
+        CHECK (status IN ('pending', 'processing', 'done', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS product_imports_pending_idx
+    ON product_imports (created_at)
+    WHERE status IN ('pending', 'processing');
+
+ALTER TABLE IF EXISTS product_imports
+    ADD CONSTRAINT product_imports_shop_id_fkey FOREIGN KEY (shop_id)
+    REFERENCES shops (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
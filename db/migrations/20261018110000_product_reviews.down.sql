@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS product_reviews;
+
+ALTER TABLE IF EXISTS product
+    DROP COLUMN IF EXISTS rating_distribution,
+    DROP COLUMN IF EXISTS review_count,
+    ALTER COLUMN penilaian TYPE integer USING ROUND(penilaian);
@@ -0,0 +1,35 @@
+-- penilaian becomes the average of the visible reviews; the old hand set values have
+-- no reviews behind them and are dropped
+ALTER TABLE IF EXISTS product
+    ALTER COLUMN penilaian TYPE numeric(3,2) USING NULL,
+    ADD COLUMN IF NOT EXISTS review_count integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rating_distribution integer[] NOT NULL DEFAULT '{0,0,0,0,0}';
+
+CREATE TABLE IF NOT EXISTS product_reviews
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    product_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    rating smallint NOT NULL,
+    body text COLLATE pg_catalog."default" NOT NULL,
+    photos jsonb NOT NULL DEFAULT '[]',
+    reply text COLLATE pg_catalog."default",
+    replied_at timestamp with time zone,
+    hidden_at timestamp with time zone,
+    hidden_by uuid,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT product_reviews_pkey PRIMARY KEY (id),
+    CONSTRAINT product_reviews_rating_check CHECK (rating BETWEEN 1 AND 5),
+    CONSTRAINT product_reviews_product_id_user_id_key UNIQUE (product_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS product_reviews_visible_idx
+    ON product_reviews (product_id, created_at DESC)
+    WHERE hidden_at IS NULL;
+
+ALTER TABLE IF EXISTS product_reviews
+    ADD CONSTRAINT product_reviews_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS product_terjual_idx;
+
+ALTER TABLE IF EXISTS shops
+    DROP CONSTRAINT IF EXISTS shops_terjual_check,
+    ALTER COLUMN terjual DROP NOT NULL,
+    ALTER COLUMN terjual DROP DEFAULT,
+    ALTER COLUMN terjual TYPE character varying(255) USING terjual::text;
+
+ALTER TABLE IF EXISTS product
+    DROP CONSTRAINT IF EXISTS product_terjual_check,
+    DROP COLUMN IF EXISTS terjual;
@@ -0,0 +1,33 @@
+-- terjual counts units sold, it is raised in the same transaction that records a sale;
+-- the old free text values of shops.terjual were never written and are dropped
+ALTER TABLE IF EXISTS product
+    ADD COLUMN IF NOT EXISTS terjual bigint NOT NULL DEFAULT 0,
+    ADD CONSTRAINT product_terjual_check CHECK (terjual >= 0);
+
+ALTER TABLE IF EXISTS shops
+    ALTER COLUMN terjual TYPE bigint USING 0,
+    ALTER COLUMN terjual SET DEFAULT 0,
+    ALTER COLUMN terjual SET NOT NULL,
+    ADD CONSTRAINT shops_terjual_check CHECK (terjual >= 0);
+
+-- sales recorded so far are the confirmed reservations
+UPDATE product SET terjual = sold.quantity
+FROM (
+    SELECT product_id, SUM(quantity) AS quantity
+    FROM stock_reservations
+    WHERE status = 'confirmed'
+    GROUP BY product_id
+) sold
+WHERE product.id = sold.product_id;
+
+UPDATE shops SET terjual = sold.quantity
+FROM (
+    SELECT shop_id, SUM(terjual) AS quantity
+    FROM product
+    GROUP BY shop_id
+) sold
+WHERE shops.id = sold.shop_id;
+
+CREATE INDEX IF NOT EXISTS product_terjual_idx
+    ON product (terjual DESC, id DESC)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,12 @@
+UPDATE stock_reservations SET status = 'confirmed' WHERE status = 'returned';
+
+ALTER TABLE IF EXISTS stock_reservations
+    DROP CONSTRAINT IF EXISTS stock_reservations_status_check,
+    ADD CONSTRAINT stock_reservations_status_check
+        CHECK (status IN ('active', 'confirmed', 'released', 'expired'));
+
+DROP TABLE IF EXISTS order_status_history;
+
+DROP TABLE IF EXISTS order_items;
+
+DROP TABLE IF EXISTS orders;
@@ -0,0 +1,92 @@
+CREATE TABLE IF NOT EXISTS orders
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    number character varying(32) COLLATE pg_catalog."default" NOT NULL,
+    buyer_id uuid NOT NULL,
+    shop_id uuid NOT NULL,
+    status character varying(16) NOT NULL DEFAULT 'pending',
+    total bigint NOT NULL,
+    currency character(3) NOT NULL DEFAULT 'IDR',
+    note text COLLATE pg_catalog."default",
+    expires_at timestamp with time zone NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT orders_pkey PRIMARY KEY (id),
+    CONSTRAINT orders_number_key UNIQUE (number),
+    CONSTRAINT orders_total_check CHECK (total >= 0),
+    CONSTRAINT orders_status_check
+        CHECK (status IN ('pending', 'paid', 'processing', 'shipped', 'completed', 'cancelled', 'refunded'))
+);
+
+CREATE INDEX IF NOT EXISTS orders_buyer_id_idx
+    ON orders (buyer_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS orders_shop_id_idx
+    ON orders (shop_id, status, created_at DESC, id DESC);
+
+-- items keep the product as it was sold, so later edits do not change past orders
+CREATE TABLE IF NOT EXISTS order_items
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    order_id uuid NOT NULL,
+    product_id uuid,
+    variant_id uuid,
+    product_name character varying(255) COLLATE pg_catalog."default" NOT NULL,
+    variant_code character varying(100) COLLATE pg_catalog."default",
+    harga bigint NOT NULL,
+    quantity integer NOT NULL,
+    reservation_id uuid,
+    position integer NOT NULL DEFAULT 0,
+    CONSTRAINT order_items_pkey PRIMARY KEY (id),
+    CONSTRAINT order_items_harga_check CHECK (harga >= 0),
+    CONSTRAINT order_items_quantity_check CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS order_items_order_id_idx
+    ON order_items (order_id, position);
+
+CREATE TABLE IF NOT EXISTS order_status_history
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    order_id uuid NOT NULL,
+    from_status character varying(16),
+    to_status character varying(16) NOT NULL,
+    -- actor is empty for changes made by the system, e.g. expiring an unpaid order
+    actor uuid,
+    note text COLLATE pg_catalog."default",
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT order_status_history_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS order_status_history_order_id_idx
+    ON order_status_history (order_id, created_at);
+
+ALTER TABLE IF EXISTS orders
+    ADD CONSTRAINT orders_shop_id_fkey FOREIGN KEY (shop_id)
+    REFERENCES shops (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE RESTRICT;
+
+ALTER TABLE IF EXISTS order_items
+    ADD CONSTRAINT order_items_order_id_fkey FOREIGN KEY (order_id)
+    REFERENCES orders (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS order_items
+    ADD CONSTRAINT order_items_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE SET NULL;
+
+ALTER TABLE IF EXISTS order_status_history
+    ADD CONSTRAINT order_status_history_order_id_fkey FOREIGN KEY (order_id)
+    REFERENCES orders (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+-- a refunded order gives back the stock its holds took
+ALTER TABLE IF EXISTS stock_reservations
+    DROP CONSTRAINT IF EXISTS stock_reservations_status_check,
+    ADD CONSTRAINT stock_reservations_status_check
+        CHECK (status IN ('active', 'confirmed', 'released', 'expired', 'returned'));
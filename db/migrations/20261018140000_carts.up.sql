@@ -0,0 +1,44 @@
+-- a cart belongs to a user or, before login, to a guest holding its token; only a hash
+-- of the token is kept
+CREATE TABLE IF NOT EXISTS carts
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    user_id uuid,
+    token_hash character(64) COLLATE pg_catalog."default",
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT carts_pkey PRIMARY KEY (id),
+    CONSTRAINT carts_user_id_key UNIQUE (user_id),
+    CONSTRAINT carts_token_hash_key UNIQUE (token_hash),
+    CONSTRAINT carts_owner_check CHECK (num_nonnulls(user_id, token_hash) = 1)
+);
+
+-- harga is the price the line was added at, to tell the buyer when it changed
+CREATE TABLE IF NOT EXISTS cart_items
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    cart_id uuid NOT NULL,
+    product_id uuid NOT NULL,
+    variant_id uuid,
+    quantity integer NOT NULL,
+    harga bigint NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT cart_items_pkey PRIMARY KEY (id),
+    CONSTRAINT cart_items_quantity_check CHECK (quantity > 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS cart_items_cart_id_product_key
+    ON cart_items (cart_id, product_id, (COALESCE(variant_id, '00000000-0000-0000-0000-000000000000'::uuid)));
+
+ALTER TABLE IF EXISTS cart_items
+    ADD CONSTRAINT cart_items_cart_id_fkey FOREIGN KEY (cart_id)
+    REFERENCES carts (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS cart_items
+    ADD CONSTRAINT cart_items_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
@@ -0,0 +1,10 @@
+ALTER TABLE IF EXISTS orders
+    DROP COLUMN IF EXISTS vouchers,
+    DROP COLUMN IF EXISTS discount,
+    DROP COLUMN IF EXISTS subtotal;
+
+DROP TABLE IF EXISTS voucher_redemptions;
+
+DROP TABLE IF EXISTS voucher_products;
+
+DROP TABLE IF EXISTS vouchers;
@@ -0,0 +1,104 @@
+-- shop_id is NULL for platform vouchers, code is NULL for promotions applied on their own
+CREATE TABLE IF NOT EXISTS vouchers
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    shop_id uuid,
+    code character varying(32) COLLATE pg_catalog."default",
+    name character varying(255) COLLATE pg_catalog."default" NOT NULL,
+    discount_type character varying(16) NOT NULL,
+    discount_value bigint NOT NULL,
+    max_discount bigint,
+    currency character(3) NOT NULL DEFAULT 'IDR',
+    scope character varying(16) NOT NULL DEFAULT 'shop',
+    min_spend bigint NOT NULL DEFAULT 0,
+    usage_limit integer,
+    per_user_limit integer,
+    used_count integer NOT NULL DEFAULT 0,
+    stackable boolean NOT NULL DEFAULT false,
+    starts_at timestamp with time zone NOT NULL,
+    ends_at timestamp with time zone NOT NULL,
+    created_by uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    deleted_at timestamp with time zone,
+    CONSTRAINT vouchers_pkey PRIMARY KEY (id),
+    CONSTRAINT vouchers_discount_type_check CHECK (discount_type IN ('percentage', 'fixed')),
+    CONSTRAINT vouchers_discount_value_check CHECK (
+        discount_value > 0 AND (discount_type <> 'percentage' OR discount_value <= 100)
+    ),
+    CONSTRAINT vouchers_scope_check CHECK (scope IN ('shop', 'product')),
+    CONSTRAINT vouchers_window_check CHECK (ends_at > starts_at),
+    CONSTRAINT vouchers_used_count_check CHECK (
+        used_count >= 0 AND (usage_limit IS NULL OR used_count <= usage_limit)
+    )
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS vouchers_code_key
+    ON vouchers (upper(code))
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS vouchers_shop_id_idx
+    ON vouchers (shop_id, ends_at)
+    WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS voucher_products
+(
+    voucher_id uuid NOT NULL,
+    product_id uuid NOT NULL,
+    CONSTRAINT voucher_products_pkey PRIMARY KEY (voucher_id, product_id)
+);
+
+-- reference is what the voucher was spent on, e.g. an order number
+CREATE TABLE IF NOT EXISTS voucher_redemptions
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    voucher_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    reference character varying(255) COLLATE pg_catalog."default" NOT NULL,
+    discount bigint NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    released_at timestamp with time zone,
+    CONSTRAINT voucher_redemptions_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS voucher_redemptions_voucher_id_user_id_idx
+    ON voucher_redemptions (voucher_id, user_id)
+    WHERE released_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS voucher_redemptions_reference_idx
+    ON voucher_redemptions (reference);
+
+ALTER TABLE IF EXISTS vouchers
+    ADD CONSTRAINT vouchers_shop_id_fkey FOREIGN KEY (shop_id)
+    REFERENCES shops (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS voucher_products
+    ADD CONSTRAINT voucher_products_voucher_id_fkey FOREIGN KEY (voucher_id)
+    REFERENCES vouchers (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS voucher_products
+    ADD CONSTRAINT voucher_products_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS voucher_redemptions
+    ADD CONSTRAINT voucher_redemptions_voucher_id_fkey FOREIGN KEY (voucher_id)
+    REFERENCES vouchers (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+-- orders keep the discount they were placed with; total is what is left to pay
+ALTER TABLE IF EXISTS orders
+    ADD COLUMN IF NOT EXISTS subtotal bigint,
+    ADD COLUMN IF NOT EXISTS discount bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS vouchers jsonb NOT NULL DEFAULT '[]';
+
+UPDATE orders SET subtotal = total WHERE subtotal IS NULL;
+
+ALTER TABLE IF EXISTS orders
+    ALTER COLUMN subtotal SET NOT NULL;
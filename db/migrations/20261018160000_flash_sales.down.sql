@@ -0,0 +1,10 @@
+ALTER TABLE IF EXISTS order_items
+    DROP COLUMN IF EXISTS flash_sale_item_id;
+
+DROP VIEW IF EXISTS active_flash_sale_items;
+
+DROP TABLE IF EXISTS flash_sale_claims;
+
+DROP TABLE IF EXISTS flash_sale_items;
+
+DROP TABLE IF EXISTS flash_sales;
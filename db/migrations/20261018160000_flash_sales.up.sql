@@ -0,0 +1,114 @@
+CREATE TABLE IF NOT EXISTS flash_sales
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    shop_id uuid NOT NULL,
+    name character varying(255) COLLATE pg_catalog."default" NOT NULL,
+    starts_at timestamp with time zone NOT NULL,
+    ends_at timestamp with time zone NOT NULL,
+    created_by uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    deleted_at timestamp with time zone,
+    CONSTRAINT flash_sales_pkey PRIMARY KEY (id),
+    CONSTRAINT flash_sales_window_check CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS flash_sales_shop_id_idx
+    ON flash_sales (shop_id, starts_at DESC)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS flash_sales_window_idx
+    ON flash_sales (starts_at, ends_at)
+    WHERE deleted_at IS NULL;
+
+-- claimed counts the units held or sold at the flash price; it never passes quota
+CREATE TABLE IF NOT EXISTS flash_sale_items
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    flash_sale_id uuid NOT NULL,
+    product_id uuid NOT NULL,
+    harga bigint NOT NULL,
+    currency character(3) NOT NULL DEFAULT 'IDR',
+    quota integer NOT NULL,
+    claimed integer NOT NULL DEFAULT 0,
+    CONSTRAINT flash_sale_items_pkey PRIMARY KEY (id),
+    CONSTRAINT flash_sale_items_product_key UNIQUE (flash_sale_id, product_id),
+    CONSTRAINT flash_sale_items_harga_check CHECK (harga >= 0),
+    CONSTRAINT flash_sale_items_quota_check CHECK (quota > 0),
+    CONSTRAINT flash_sale_items_claimed_check CHECK (claimed >= 0 AND claimed <= quota)
+);
+
+CREATE INDEX IF NOT EXISTS flash_sale_items_product_id_idx
+    ON flash_sale_items (product_id);
+
+-- a claim takes quota for a holder, e.g. an order number, like a stock reservation
+CREATE TABLE IF NOT EXISTS flash_sale_claims
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    flash_sale_item_id uuid NOT NULL,
+    holder character varying(255) COLLATE pg_catalog."default" NOT NULL,
+    quantity integer NOT NULL,
+    status character varying(16) NOT NULL DEFAULT 'active',
+    expires_at timestamp with time zone NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT flash_sale_claims_pkey PRIMARY KEY (id),
+    CONSTRAINT flash_sale_claims_quantity_check CHECK (quantity > 0),
+    CONSTRAINT flash_sale_claims_status_check CHECK (status IN ('active', 'confirmed', 'released', 'expired'))
+);
+
+CREATE INDEX IF NOT EXISTS flash_sale_claims_holder_idx
+    ON flash_sale_claims (holder);
+
+CREATE INDEX IF NOT EXISTS flash_sale_claims_active_idx
+    ON flash_sale_claims (expires_at)
+    WHERE status = 'active';
+
+ALTER TABLE IF EXISTS flash_sales
+    ADD CONSTRAINT flash_sales_shop_id_fkey FOREIGN KEY (shop_id)
+    REFERENCES shops (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS flash_sale_items
+    ADD CONSTRAINT flash_sale_items_flash_sale_id_fkey FOREIGN KEY (flash_sale_id)
+    REFERENCES flash_sales (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS flash_sale_items
+    ADD CONSTRAINT flash_sale_items_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS flash_sale_claims
+    ADD CONSTRAINT flash_sale_claims_flash_sale_item_id_fkey FOREIGN KEY (flash_sale_item_id)
+    REFERENCES flash_sale_items (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+-- the sale running for each product right now; sales of a product never overlap, the
+-- DISTINCT ON only guards the readers against bad rows
+CREATE OR REPLACE VIEW active_flash_sale_items AS
+SELECT DISTINCT ON (flash_sale_items.product_id)
+    flash_sale_items.id,
+    flash_sale_items.flash_sale_id,
+    flash_sale_items.product_id,
+    flash_sales.name,
+    flash_sale_items.harga,
+    flash_sale_items.currency,
+    flash_sale_items.quota,
+    flash_sale_items.quota - flash_sale_items.claimed AS remaining,
+    flash_sales.starts_at,
+    flash_sales.ends_at
+FROM flash_sale_items
+JOIN flash_sales ON flash_sales.id = flash_sale_items.flash_sale_id
+WHERE flash_sales.deleted_at IS NULL
+    AND flash_sales.starts_at <= now()
+    AND flash_sales.ends_at > now()
+ORDER BY flash_sale_items.product_id, flash_sales.starts_at, flash_sale_items.id;
+
+-- order items remember the flash sale they were priced by
+ALTER TABLE IF EXISTS order_items
+    ADD COLUMN IF NOT EXISTS flash_sale_item_id uuid;
@@ -0,0 +1,46 @@
+-- every buyer gets a default list on first use; named lists are optional
+CREATE TABLE IF NOT EXISTS wishlists
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    name character varying(100) COLLATE pg_catalog."default" NOT NULL,
+    is_default boolean NOT NULL DEFAULT false,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT wishlists_pkey PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS wishlists_user_id_name_key
+    ON wishlists (user_id, lower(name));
+
+CREATE UNIQUE INDEX IF NOT EXISTS wishlists_user_id_default_key
+    ON wishlists (user_id)
+    WHERE is_default;
+
+-- harga is the price when the product was saved, in the product currency
+CREATE TABLE IF NOT EXISTS wishlist_items
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    wishlist_id uuid NOT NULL,
+    product_id uuid NOT NULL,
+    harga bigint NOT NULL,
+    currency character(3) NOT NULL DEFAULT 'IDR',
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT wishlist_items_pkey PRIMARY KEY (id),
+    CONSTRAINT wishlist_items_product_key UNIQUE (wishlist_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS wishlist_items_wishlist_id_idx
+    ON wishlist_items (wishlist_id, created_at DESC, id DESC);
+
+ALTER TABLE IF EXISTS wishlist_items
+    ADD CONSTRAINT wishlist_items_wishlist_id_fkey FOREIGN KEY (wishlist_id)
+    REFERENCES wishlists (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS wishlist_items
+    ADD CONSTRAINT wishlist_items_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS shop_events;
+DROP TABLE IF EXISTS shop_followers;
+
+ALTER TABLE IF EXISTS product
+    DROP COLUMN IF EXISTS out_of_stock;
+
+ALTER TABLE IF EXISTS shops
+    DROP CONSTRAINT IF EXISTS shops_follower_count_check,
+    DROP COLUMN IF EXISTS follower_count;
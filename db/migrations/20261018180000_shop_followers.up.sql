@@ -0,0 +1,54 @@
+-- follower_count mirrors shop_followers, it is changed in the same transaction as a follow
+ALTER TABLE IF EXISTS shops
+    ADD COLUMN IF NOT EXISTS follower_count bigint NOT NULL DEFAULT 0,
+    ADD CONSTRAINT shops_follower_count_check CHECK (follower_count >= 0);
+
+CREATE TABLE IF NOT EXISTS shop_followers
+(
+    shop_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT shop_followers_pkey PRIMARY KEY (shop_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS shop_followers_user_id_idx
+    ON shop_followers (user_id);
+
+ALTER TABLE IF EXISTS shop_followers
+    ADD CONSTRAINT shop_followers_shop_id_fkey FOREIGN KEY (shop_id)
+    REFERENCES shops (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+-- out_of_stock remembers that the product ran out, so coming back in stock is raised once
+ALTER TABLE IF EXISTS product
+    ADD COLUMN IF NOT EXISTS out_of_stock boolean NOT NULL DEFAULT false;
+
+UPDATE product SET out_of_stock = true WHERE stok = 0;
+
+CREATE TABLE IF NOT EXISTS shop_events
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    shop_id uuid NOT NULL,
+    product_id uuid NOT NULL,
+    kind character varying(20) NOT NULL,
+    stok integer NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT shop_events_pkey PRIMARY KEY (id),
+    CONSTRAINT shop_events_kind_check CHECK (kind IN ('published', 'restocked'))
+);
+
+CREATE INDEX IF NOT EXISTS shop_events_shop_id_idx
+    ON shop_events (shop_id, created_at DESC, id DESC);
+
+ALTER TABLE IF EXISTS shop_events
+    ADD CONSTRAINT shop_events_shop_id_fkey FOREIGN KEY (shop_id)
+    REFERENCES shops (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
+
+ALTER TABLE IF EXISTS shop_events
+    ADD CONSTRAINT shop_events_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
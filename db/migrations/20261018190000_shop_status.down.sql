@@ -0,0 +1,10 @@
+DROP FUNCTION IF EXISTS shop_is_open(text, timestamptz, jsonb, text, timestamptz);
+
+ALTER TABLE IF EXISTS shops
+    DROP CONSTRAINT IF EXISTS shops_vacation_until_check,
+    DROP CONSTRAINT IF EXISTS shops_status_check,
+    DROP COLUMN IF EXISTS opening_hours,
+    DROP COLUMN IF EXISTS timezone,
+    DROP COLUMN IF EXISTS closed_reason,
+    DROP COLUMN IF EXISTS vacation_until,
+    DROP COLUMN IF EXISTS status;
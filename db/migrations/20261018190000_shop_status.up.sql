@@ -0,0 +1,27 @@
+-- a vacation ends on its own at vacation_until; a shop closed by an admin stays closed
+-- until an admin opens it again
+ALTER TABLE IF EXISTS shops
+    ADD COLUMN IF NOT EXISTS status character varying(20) NOT NULL DEFAULT 'open',
+    ADD COLUMN IF NOT EXISTS vacation_until timestamp with time zone,
+    ADD COLUMN IF NOT EXISTS closed_reason text,
+    ADD COLUMN IF NOT EXISTS timezone character varying(40) NOT NULL DEFAULT 'Asia/Jakarta',
+    ADD COLUMN IF NOT EXISTS opening_hours jsonb NOT NULL DEFAULT '[]',
+    ADD CONSTRAINT shops_status_check CHECK (status IN ('open', 'vacation', 'closed')),
+    ADD CONSTRAINT shops_vacation_until_check CHECK (status <> 'vacation' OR vacation_until IS NOT NULL);
+
+-- opening_hours holds {"day": 0-6 from Sunday, "open": "HH:MM", "close": "HH:MM"} windows
+-- in the shop timezone; a shop without any is open all week
+CREATE OR REPLACE FUNCTION shop_is_open(p_status text, p_vacation_until timestamptz, p_hours jsonb, p_timezone text, p_at timestamptz)
+RETURNS boolean AS $$
+    SELECT CASE
+        WHEN p_status = 'closed' THEN false
+        WHEN p_status = 'vacation' AND p_vacation_until > p_at THEN false
+        WHEN jsonb_array_length(p_hours) = 0 THEN true
+        ELSE EXISTS (
+            SELECT 1 FROM jsonb_array_elements(p_hours) w
+            WHERE (w->>'day')::int = EXTRACT(DOW FROM p_at AT TIME ZONE p_timezone)
+                AND (p_at AT TIME ZONE p_timezone)::time >= (w->>'open')::time
+                AND (p_at AT TIME ZONE p_timezone)::time < (w->>'close')::time
+        )
+    END
+$$ LANGUAGE sql STABLE;
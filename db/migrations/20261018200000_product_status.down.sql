@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS product_scheduled_idx;
+DROP INDEX IF EXISTS product_published_at_idx;
+
+ALTER TABLE IF EXISTS product
+    DROP CONSTRAINT IF EXISTS product_published_at_check,
+    DROP CONSTRAINT IF EXISTS product_status_check,
+    DROP COLUMN IF EXISTS published_at,
+    DROP COLUMN IF EXISTS status;
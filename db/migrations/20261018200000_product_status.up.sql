@@ -0,0 +1,22 @@
+-- only published products are shown to buyers and sold. published_at is when a product
+-- went live, or for a draft the time it is scheduled to; the publish worker makes
+-- scheduled drafts live once that time has come
+ALTER TABLE IF EXISTS product
+    ADD COLUMN IF NOT EXISTS status character varying(20) NOT NULL DEFAULT 'published',
+    ADD COLUMN IF NOT EXISTS published_at timestamp with time zone;
+
+-- products so far went live when they were created
+UPDATE product SET published_at = created_at;
+
+ALTER TABLE IF EXISTS product
+    ALTER COLUMN status SET DEFAULT 'draft',
+    ADD CONSTRAINT product_status_check CHECK (status IN ('draft', 'published', 'archived')),
+    ADD CONSTRAINT product_published_at_check CHECK (status <> 'published' OR published_at IS NOT NULL);
+
+CREATE INDEX IF NOT EXISTS product_published_at_idx
+    ON product (published_at DESC, id DESC)
+    WHERE deleted_at IS NULL AND status = 'published';
+
+CREATE INDEX IF NOT EXISTS product_scheduled_idx
+    ON product (published_at)
+    WHERE deleted_at IS NULL AND status = 'draft' AND published_at IS NOT NULL;
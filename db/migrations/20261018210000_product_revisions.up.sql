@@ -0,0 +1,21 @@
+-- every change to a product is kept as a numbered, immutable snapshot. reverted_from is
+-- set when the revision was made by rolling back to an earlier one
+CREATE TABLE IF NOT EXISTS product_revisions
+(
+    id uuid NOT NULL DEFAULT gen_random_uuid(),
+    product_id uuid NOT NULL,
+    revision integer NOT NULL,
+    snapshot jsonb NOT NULL,
+    actor uuid,
+    reverted_from integer,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    CONSTRAINT product_revisions_pkey PRIMARY KEY (id),
+    CONSTRAINT product_revisions_product_id_revision_key UNIQUE (product_id, revision),
+    CONSTRAINT product_revisions_revision_check CHECK (revision > 0)
+);
+
+ALTER TABLE IF EXISTS product_revisions
+    ADD CONSTRAINT product_revisions_product_id_fkey FOREIGN KEY (product_id)
+    REFERENCES product (id) MATCH SIMPLE
+    ON UPDATE NO ACTION
+    ON DELETE CASCADE;
@@ -15,6 +15,7 @@ require (
 	github.com/rs/zerolog v1.32.0
 	github.com/stretchr/testify v1.9.0
 	golang.org/x/crypto v0.26.0
+	golang.org/x/image v0.19.0
 	golang.org/x/oauth2 v0.22.0
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
 )
@@ -24,6 +24,8 @@ type Config struct {
 		LogFileWs               string `env:"APP_LOG_FILE_WS" env-default:"./logs/ws.log"`
 		LocalStoragePublicPath  string `env:"LOCAL_STORAGE_PUBLIC_PATH" env-default:"./storage/public"`
 		LocalStoragePrivatePath string `env:"LOCAL_STORAGE_PRIVATE_PATH" env-default:"./storage/private"`
+		StorageDriver           string `env:"APP_STORAGE_DRIVER" env-default:"local" env-description:"local or s3"`
+		ImageWorkers            int    `env:"APP_IMAGE_WORKERS" env-default:"2" env-description:"goroutines resizing uploaded images"`
 	}
 	DB struct {
 		ConnectionTimeout int `env:"DB_CONN_TIMEOUT" env-default:"30" env-description:"database timeout in seconds"`
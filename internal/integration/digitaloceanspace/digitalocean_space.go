@@ -7,6 +7,7 @@ import (
 	"codebase-app/pkg"
 	"codebase-app/pkg/errmsg"
 	"context"
+	"io"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
@@ -19,6 +20,9 @@ type DigitaloceanSpaceContract interface {
 	UploadFile(ctx context.Context, req *entity.UploadFileRequest) (entity.UploadFileResponse, error)
 	DeleteFile(ctx context.Context, req *entity.DeleteFileRequest) error
 	ListFiles(ctx context.Context) ([]types.Object, error)
+	Put(ctx context.Context, key string, body io.Reader, contentType string) (url string, err error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
 }
 
 type dospace struct {
@@ -80,6 +84,40 @@ func (d *dospace) DeleteFile(ctx context.Context, req *entity.DeleteFileRequest)
 	return nil
 }
 
+// Put uploads body under key as a public object and returns its URL.
+func (d *dospace) Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error) {
+	result, err := manager.NewUploader(d.storage).Upload(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(config.Envs.ShopeefunStorage.Bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+		ACL:         types.ObjectCannedACLPublicRead,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("integration::dospace-Put Error while uploading file")
+		return "", err
+	}
+
+	return result.Location, nil
+}
+
+func (d *dospace) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	result, err := d.storage.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(config.Envs.ShopeefunStorage.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("integration::dospace-Get Error while downloading file")
+		return nil, err
+	}
+
+	return result.Body, nil
+}
+
+func (d *dospace) Delete(ctx context.Context, key string) error {
+	return d.DeleteFile(ctx, &entity.DeleteFileRequest{FileName: key})
+}
+
 func (d *dospace) ListFiles(ctx context.Context) ([]types.Object, error) {
 	objects := []types.Object{}
 	input := &s3.ListObjectsV2Input{
@@ -0,0 +1,32 @@
+package integration
+
+import (
+	"codebase-app/internal/infrastructure/config"
+	dospace "codebase-app/internal/integration/digitaloceanspace"
+	localstorage "codebase-app/internal/integration/localstorage"
+	"context"
+	"io"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// FileStorageContract stores public files under a key such as "products/<id>/<file>.jpg"
+// and returns the URL they can be fetched from.
+type FileStorageContract interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) (url string, err error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewFileStorageIntegration returns the integration selected by APP_STORAGE_DRIVER:
+// the DigitalOcean Space bucket for s3, local disk otherwise.
+func NewFileStorageIntegration() FileStorageContract {
+	if config.Envs.App.StorageDriver == DriverS3 {
+		return dospace.NewDigitalOceanSpaceIntegration()
+	}
+
+	return localstorage.NewLocalStorageIntegration()
+}
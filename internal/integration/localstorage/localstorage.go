@@ -1,9 +1,13 @@
 package integration
 
 import (
+	"bytes"
+	"codebase-app/internal/infrastructure/config"
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"strings"
@@ -13,8 +17,14 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// PublicPrefix is the route the public storage directory is served from.
+const PublicPrefix = "/storage"
+
 type LocalStorageContract interface {
 	Save(base64String, path string) (fullpath string, err error)
+	Put(ctx context.Context, key string, body io.Reader, contentType string) (url string, err error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
 }
 
 var (
@@ -59,14 +69,48 @@ func (l *localstorage) Save(base64String, path string) (fullpath string, err err
 
 	// Save file to local storage
 	fullpath = fmt.Sprintf("%s/%s", path, filename)
-	if err := l.saveFile(fullpath, fileContent); err != nil {
+	if err := l.saveFile(fullpath, bytes.NewReader(fileContent)); err != nil {
 		return "", err
 	}
 
 	return fullpath, nil
 }
 
-func (l *localstorage) saveFile(fullpath string, data []byte) error {
+// Put stores body under key in the public storage directory and returns the URL it is
+// served from.
+func (l *localstorage) Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error) {
+	if err := l.saveFile(l.publicPath(key), body); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(config.Envs.App.BaseURL, "/") + PublicPrefix + "/" + key, nil
+}
+
+func (l *localstorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(l.publicPath(key))
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Msg("localstorage: failed to open file")
+		return nil, fmt.Errorf("localstorage: %w", err)
+	}
+
+	return file, nil
+}
+
+// Delete removes the file stored under key. A file that is already gone is not an error.
+func (l *localstorage) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(l.publicPath(key)); err != nil && !os.IsNotExist(err) {
+		log.Error().Err(err).Str("key", key).Msg("localstorage: failed to delete file")
+		return fmt.Errorf("localstorage: %w", err)
+	}
+
+	return nil
+}
+
+func (l *localstorage) publicPath(key string) string {
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(config.Envs.App.LocalStoragePublicPath, "/"), key)
+}
+
+func (l *localstorage) saveFile(fullpath string, data io.Reader) error {
 	path := strings.Split(fullpath, "/")         // Split path by "/"
 	dir := strings.Join(path[:len(path)-1], "/") // Join path except the last element
 
@@ -83,7 +127,7 @@ func (l *localstorage) saveFile(fullpath string, data []byte) error {
 	}
 	defer file.Close() // Close file after function ends
 
-	if _, err := file.Write(data); err != nil { // Write data to file
+	if _, err := io.Copy(file, data); err != nil { // Write data to file
 		log.Error().Err(err).Msg("localstorage: failed to write data to file")
 		return fmt.Errorf("localstorage: %w", err)
 	}
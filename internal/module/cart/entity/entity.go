@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+// CartOwner picks a cart: the one of UserID when it is set, otherwise the guest cart
+// of Token. Both come from the X-USER-ID and X-CART-TOKEN headers.
+type CartOwner struct {
+	UserID string `prop:"user_id" validate:"omitempty,uuid"`
+	Token  string `prop:"cart_token" validate:"omitempty,max=128"`
+}
+
+type CartRequest struct {
+	CartOwner
+}
+
+// AddCartItemRequest adds Quantity units to the cart, on top of what is already there.
+// A guest without a cart gets a new one, its token comes back with the cart.
+type AddCartItemRequest struct {
+	CartOwner
+	ProductID string `json:"product_id" validate:"required,uuid"`
+	VariantID string `json:"variant_id" validate:"omitempty,uuid"`
+	Quantity  int    `json:"quantity" validate:"required,min=1,max=999"`
+}
+
+type UpdateCartItemRequest struct {
+	CartOwner
+	Id       string `params:"id" validate:"uuid"`
+	Quantity int    `json:"quantity" validate:"required,min=1,max=999"`
+}
+
+type CartItemRequest struct {
+	CartOwner
+	Id string `params:"id" validate:"uuid"`
+}
+
+// MergeCartRequest moves the guest cart of Token into the cart of UserID, adding up
+// the quantities of lines found in both.
+type MergeCartRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	Token  string `prop:"cart_token" validate:"required,max=128"`
+}
+
+// CartLine is a cart item read together with the current state of its product.
+type CartLine struct {
+	ID          string      `db:"id"`
+	ProductID   string      `db:"product_id"`
+	VariantID   *string     `db:"variant_id"`
+	ShopID      string      `db:"shop_id"`
+	ShopName    string      `db:"shop_name"`
+	ProductName string      `db:"product_name"`
+	VariantCode *string     `db:"variant_code"`
+	Quantity    int         `db:"quantity"`
+	AddedHarga  types.Money `db:"added_harga"`
+	Harga       types.Money `db:"harga"`
+	Stok        int         `db:"stok"`
+	Deleted     bool        `db:"deleted"`
+	Available   bool        `db:"available"`
+	CreatedAt   time.Time   `db:"created_at"`
+}
+
+// CartItem is a line of the cart priced at the current price. Deleted lines can no
+// longer be bought, unavailable ones not right now (a draft or archived product, a
+// closed shop); both are left out of the totals.
+type CartItem struct {
+	ID                string       `json:"id"`
+	ProductID         string       `json:"product_id"`
+	VariantID         *string      `json:"variant_id"`
+	ProductName       string       `json:"product_name"`
+	VariantCode       *string      `json:"variant_code"`
+	Quantity          int          `json:"quantity"`
+	Harga             types.Money  `json:"harga"`
+	PreviousHarga     *types.Money `json:"previous_harga,omitempty"`
+	Subtotal          types.Money  `json:"subtotal"`
+	Stok              int          `json:"stok"`
+	Deleted           bool         `json:"deleted"`
+	Available         bool         `json:"available"`
+	PriceChanged      bool         `json:"price_changed"`
+	InsufficientStock bool         `json:"insufficient_stock"`
+}
+
+// CartShop groups the lines of a cart sold by one shop.
+type CartShop struct {
+	ShopID   string      `json:"shop_id"`
+	ShopName string      `json:"shop_name"`
+	Items    []CartItem  `json:"items"`
+	Total    types.Money `json:"total"`
+}
+
+type CartResponse struct {
+	// Token is only returned when a guest cart was just created.
+	Token     string     `json:"token,omitempty"`
+	Shops     []CartShop `json:"shops"`
+	ItemCount int        `json:"item_count"`
+}
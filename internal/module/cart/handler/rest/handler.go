@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/middleware"
+	"codebase-app/internal/module/cart/entity"
+	"codebase-app/internal/module/cart/ports"
+	"codebase-app/internal/module/cart/repository"
+	"codebase-app/internal/module/cart/service"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type cartHandler struct {
+	service ports.CartService
+}
+
+func NewCartHandler() *cartHandler {
+	var (
+		handler = new(cartHandler)
+		repo    = repository.NewCartRepository(adapter.Adapters.ShopeefunPostgres)
+		service = service.NewCartService(repo)
+	)
+	handler.service = service
+
+	return handler
+}
+
+// Register mounts the cart routes. They serve both users and guests, so the owner is
+// read from the headers rather than required by a middleware; see cartOwner.
+func (h *cartHandler) Register(router fiber.Router) {
+	router.Get("/cart", h.GetCart)
+	router.Post("/cart/items", h.AddCartItem)
+	router.Patch("/cart/items/:id", h.UpdateCartItem)
+	router.Delete("/cart/items/:id", h.DeleteCartItem)
+	router.Post("/cart/merge", middleware.UserIdHeader, h.MergeCart)
+}
+
+func cartOwner(c *fiber.Ctx) entity.CartOwner {
+	return entity.CartOwner{
+		UserID: c.Get("X-USER-ID"),
+		Token:  c.Get("X-CART-TOKEN"),
+	}
+}
+
+func (h *cartHandler) GetCart(c *fiber.Ctx) error {
+	var (
+		req = new(entity.CartRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	req.CartOwner = cartOwner(c)
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetCart - Validate request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetCart(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
+
+func (h *cartHandler) AddCartItem(c *fiber.Ctx) error {
+	var (
+		req = new(entity.AddCartItemRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::AddCartItem - Parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.CartOwner = cartOwner(c)
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::AddCartItem - Validate request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.AddCartItem(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
+
+func (h *cartHandler) UpdateCartItem(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdateCartItemRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateCartItem - Parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.CartOwner = cartOwner(c)
+	req.Id = c.Params("id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::UpdateCartItem - Validate request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.UpdateCartItem(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
+
+func (h *cartHandler) DeleteCartItem(c *fiber.Ctx) error {
+	var (
+		req = new(entity.CartItemRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	req.CartOwner = cartOwner(c)
+	req.Id = c.Params("id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::DeleteCartItem - Validate request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.DeleteCartItem(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
+
+func (h *cartHandler) MergeCart(c *fiber.Ctx) error {
+	var (
+		req = new(entity.MergeCartRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserID = l.UserId
+	req.Token = c.Get("X-CART-TOKEN")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Msg("handler::MergeCart - Validate request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.MergeCart(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"codebase-app/internal/module/cart/entity"
+	"context"
+)
+
+type CartRepository interface {
+	FindCart(ctx context.Context, userID, tokenHash string) (string, error)
+	CreateCart(ctx context.Context, userID, tokenHash string) (string, error)
+	AddCartItem(ctx context.Context, cartID string, req *entity.AddCartItemRequest) error
+	UpdateCartItem(ctx context.Context, cartID string, req *entity.UpdateCartItemRequest) error
+	DeleteCartItem(ctx context.Context, cartID, id string) error
+	GetCartLines(ctx context.Context, cartID string) ([]entity.CartLine, error)
+	MergeCarts(ctx context.Context, fromID, toID string) error
+}
+
+type CartService interface {
+	GetCart(ctx context.Context, req *entity.CartRequest) (*entity.CartResponse, error)
+	AddCartItem(ctx context.Context, req *entity.AddCartItemRequest) (*entity.CartResponse, error)
+	UpdateCartItem(ctx context.Context, req *entity.UpdateCartItemRequest) (*entity.CartResponse, error)
+	DeleteCartItem(ctx context.Context, req *entity.CartItemRequest) (*entity.CartResponse, error)
+	MergeCart(ctx context.Context, req *entity.MergeCartRequest) (*entity.CartResponse, error)
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"codebase-app/internal/module/cart/entity"
+	"codebase-app/internal/module/cart/ports"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.CartRepository = &cartRepository{}
+
+type cartRepository struct {
+	db *sqlx.DB
+}
+
+func NewCartRepository(db *sqlx.DB) *cartRepository {
+	return &cartRepository{
+		db: db,
+	}
+}
+
+// FindCart returns the id of the cart of userID, or of the guest cart of tokenHash when
+// userID is empty. It returns an empty id when there is none.
+func (r *cartRepository) FindCart(ctx context.Context, userID, tokenHash string) (string, error) {
+	var id string
+
+	query := `SELECT id FROM carts WHERE user_id = CAST(NULLIF(?, '') AS uuid) OR (? = '' AND token_hash = ?)`
+	err := r.db.GetContext(ctx, &id, r.db.Rebind(query), userID, userID, tokenHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("repository::FindCart - Failed to get cart")
+		return "", err
+	}
+
+	return id, nil
+}
+
+// CreateCart opens the cart of userID, or a guest cart for tokenHash, and returns its
+// id. A user cart that already exists is returned as is.
+func (r *cartRepository) CreateCart(ctx context.Context, userID, tokenHash string) (string, error) {
+	var id string
+
+	query := `
+		INSERT INTO carts (user_id, token_hash)
+		VALUES (CAST(NULLIF(?, '') AS uuid), NULLIF(?, ''))
+		ON CONFLICT (user_id) DO UPDATE SET updated_at = NOW()
+		RETURNING id
+	`
+	if err := r.db.GetContext(ctx, &id, r.db.Rebind(query), userID, tokenHash); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("repository::CreateCart - Failed to create cart")
+		return "", err
+	}
+
+	return id, nil
+}
+
+// AddCartItem adds a product to a cart at its current price, the flash price while a
+// flash sale has quota left. Adding a product that is already in the cart raises its
+// quantity.
+func (r *cartRepository) AddCartItem(ctx context.Context, cartID string, req *entity.AddCartItemRequest) error {
+	var product struct {
+		Harga       int64 `db:"harga"`
+		HasVariants bool  `db:"has_variants"`
+		HasVariant  bool  `db:"has_variant"`
+	}
+
+	queryProduct := `
+		SELECT
+			COALESCE(flash.harga, product_variants.harga, product.harga) AS harga,
+			EXISTS (
+				SELECT 1 FROM product_variants
+				WHERE product_variants.product_id = product.id AND product_variants.deleted_at IS NULL
+			) AS has_variants,
+			product_variants.id IS NOT NULL AS has_variant
+		FROM product
+		LEFT JOIN product_variants
+			ON product_variants.product_id = product.id
+			AND product_variants.id = CAST(NULLIF(?, '') AS uuid)
+			AND product_variants.deleted_at IS NULL
+		-- flash sales only price products without SKUs
+		LEFT JOIN active_flash_sale_items flash
+			ON flash.product_id = product.id AND flash.remaining > 0 AND product_variants.id IS NULL
+		WHERE product.id = ? AND product.deleted_at IS NULL AND product.status = 'published'
+	`
+	err := r.db.GetContext(ctx, &product, r.db.Rebind(queryProduct), req.VariantID, req.ProductID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Produk tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::AddCartItem - Failed to get product")
+		return err
+	}
+
+	if req.VariantID != "" && !product.HasVariant {
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Varian produk tidak ditemukan"))
+	}
+	if req.VariantID == "" && product.HasVariants {
+		return errmsg.NewCustomErrors(400, errmsg.WithErrors("variant_id", "varian harus dipilih."))
+	}
+
+	query := `
+		INSERT INTO cart_items (cart_id, product_id, variant_id, quantity, harga)
+		VALUES (?, ?, CAST(NULLIF(?, '') AS uuid), ?, ?)
+		ON CONFLICT (cart_id, product_id, (COALESCE(variant_id, '00000000-0000-0000-0000-000000000000'::uuid)))
+		DO UPDATE SET
+			quantity = LEAST(cart_items.quantity + EXCLUDED.quantity, 999),
+			harga = EXCLUDED.harga,
+			updated_at = NOW()
+	`
+	_, err = r.db.ExecContext(ctx, r.db.Rebind(query), cartID, req.ProductID, req.VariantID, req.Quantity, product.Harga)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::AddCartItem - Failed to save cart item")
+		return err
+	}
+
+	return touchCart(ctx, r.db, cartID)
+}
+
+// UpdateCartItem sets the quantity of a cart line; the line takes the current price,
+// since the buyer has seen it.
+func (r *cartRepository) UpdateCartItem(ctx context.Context, cartID string, req *entity.UpdateCartItemRequest) error {
+	query := `
+		UPDATE cart_items SET
+			quantity = ?,
+			harga = COALESCE(
+				(
+					SELECT harga FROM active_flash_sale_items flash
+					WHERE flash.product_id = cart_items.product_id AND flash.remaining > 0 AND cart_items.variant_id IS NULL
+				),
+				(SELECT harga FROM product_variants WHERE product_variants.id = cart_items.variant_id),
+				(SELECT harga FROM product WHERE product.id = cart_items.product_id)
+			),
+			updated_at = NOW()
+		WHERE id = ? AND cart_id = ?
+	`
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(query), req.Quantity, req.Id, cartID)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateCartItem - Failed to update cart item")
+		return err
+	}
+
+	if err := checkAffected(res, "repository::UpdateCartItem"); err != nil {
+		return err
+	}
+
+	return touchCart(ctx, r.db, cartID)
+}
+
+func (r *cartRepository) DeleteCartItem(ctx context.Context, cartID, id string) error {
+	query := `DELETE FROM cart_items WHERE id = ? AND cart_id = ?`
+
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(query), id, cartID)
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("repository::DeleteCartItem - Failed to delete cart item")
+		return err
+	}
+
+	if err := checkAffected(res, "repository::DeleteCartItem"); err != nil {
+		return err
+	}
+
+	return touchCart(ctx, r.db, cartID)
+}
+
+// GetCartLines reads the lines of a cart with the current price, flash sales included,
+// and stock of their product, grouped by shop and oldest first within a shop. A line is
+// available while its product is published and its shop open, as checkout requires.
+func (r *cartRepository) GetCartLines(ctx context.Context, cartID string) ([]entity.CartLine, error) {
+	var resp []entity.CartLine
+
+	query := `
+		SELECT
+			cart_items.id,
+			cart_items.product_id,
+			cart_items.variant_id,
+			product.shop_id,
+			shops.name AS shop_name,
+			product.name AS product_name,
+			product_variants.code AS variant_code,
+			cart_items.quantity,
+			ROW(cart_items.harga, COALESCE(product_variants.currency, product.currency)) AS added_harga,
+			ROW(COALESCE(flash.harga, product_variants.harga, product.harga), COALESCE(product_variants.currency, product.currency)) AS harga,
+			COALESCE(product_variants.stok, product.stok) AS stok,
+			(
+				product.deleted_at IS NOT NULL
+				OR shops.deleted_at IS NOT NULL
+				OR product_variants.deleted_at IS NOT NULL
+			) AS deleted,
+			(
+				product.status = 'published'
+				AND shop_is_open(shops.status, shops.vacation_until, shops.opening_hours, shops.timezone, NOW())
+			) AS available,
+			cart_items.created_at
+		FROM cart_items
+		JOIN product ON product.id = cart_items.product_id
+		JOIN shops ON shops.id = product.shop_id
+		LEFT JOIN product_variants ON product_variants.id = cart_items.variant_id
+		LEFT JOIN active_flash_sale_items flash
+			ON flash.product_id = cart_items.product_id AND flash.remaining > 0 AND cart_items.variant_id IS NULL
+		WHERE cart_items.cart_id = ?
+		ORDER BY shops.name, product.shop_id, cart_items.created_at, cart_items.id
+	`
+	if err := r.db.SelectContext(ctx, &resp, r.db.Rebind(query), cartID); err != nil {
+		log.Error().Err(err).Str("cart_id", cartID).Msg("repository::GetCartLines - Failed to get cart items")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// MergeCarts moves the lines of cart fromID into cart toID and drops fromID. Lines of
+// the same product add up.
+func (r *cartRepository) MergeCarts(ctx context.Context, fromID, toID string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Str("from", fromID).Str("to", toID).Msg("repository::MergeCarts - Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO cart_items (cart_id, product_id, variant_id, quantity, harga, created_at)
+		SELECT ?, product_id, variant_id, quantity, harga, created_at
+		FROM cart_items
+		WHERE cart_id = ?
+		ON CONFLICT (cart_id, product_id, (COALESCE(variant_id, '00000000-0000-0000-0000-000000000000'::uuid)))
+		DO UPDATE SET
+			quantity = LEAST(cart_items.quantity + EXCLUDED.quantity, 999),
+			updated_at = NOW()
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), toID, fromID); err != nil {
+		log.Error().Err(err).Str("from", fromID).Str("to", toID).Msg("repository::MergeCarts - Failed to move cart items")
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM carts WHERE id = ?`), fromID); err != nil {
+		log.Error().Err(err).Str("from", fromID).Msg("repository::MergeCarts - Failed to delete guest cart")
+		return err
+	}
+
+	if err := touchCart(ctx, tx, toID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Str("from", fromID).Str("to", toID).Msg("repository::MergeCarts - Failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
+
+func touchCart(ctx context.Context, db sqlx.ExtContext, cartID string) error {
+	if _, err := db.ExecContext(ctx, db.Rebind(`UPDATE carts SET updated_at = NOW() WHERE id = ?`), cartID); err != nil {
+		log.Error().Err(err).Str("cart_id", cartID).Msg("repository::touchCart - Failed to update cart")
+		return err
+	}
+
+	return nil
+}
+
+func checkAffected(res sql.Result, scope string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Msg(scope + " - Failed to read affected rows")
+		return err
+	}
+	if n == 0 {
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Item keranjang tidak ditemukan"))
+	}
+
+	return nil
+}
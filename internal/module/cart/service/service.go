@@ -0,0 +1,220 @@
+package service
+
+import (
+	"codebase-app/internal/module/cart/entity"
+	"codebase-app/internal/module/cart/ports"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/types"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.CartService = &cartService{}
+
+type cartService struct {
+	repo ports.CartRepository
+}
+
+func NewCartService(repo ports.CartRepository) *cartService {
+	return &cartService{
+		repo: repo,
+	}
+}
+
+func (s *cartService) GetCart(ctx context.Context, req *entity.CartRequest) (*entity.CartResponse, error) {
+	cartID, err := s.findCart(ctx, req.CartOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.cart(ctx, cartID)
+}
+
+func (s *cartService) AddCartItem(ctx context.Context, req *entity.AddCartItemRequest) (*entity.CartResponse, error) {
+	var token string
+
+	cartID, err := s.findCart(ctx, req.CartOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	if cartID == "" {
+		// guests get a cart on their first item, the token is the only way back to it
+		if req.UserID == "" {
+			if token, err = newCartToken(); err != nil {
+				log.Error().Err(err).Msg("service::AddCartItem - Failed to generate cart token")
+				return nil, err
+			}
+		}
+
+		cartID, err = s.repo.CreateCart(ctx, req.UserID, hashCartToken(token))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.AddCartItem(ctx, cartID, req); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.cart(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+	resp.Token = token
+
+	return resp, nil
+}
+
+func (s *cartService) UpdateCartItem(ctx context.Context, req *entity.UpdateCartItemRequest) (*entity.CartResponse, error) {
+	cartID, err := s.requireCart(ctx, req.CartOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateCartItem(ctx, cartID, req); err != nil {
+		return nil, err
+	}
+
+	return s.cart(ctx, cartID)
+}
+
+func (s *cartService) DeleteCartItem(ctx context.Context, req *entity.CartItemRequest) (*entity.CartResponse, error) {
+	cartID, err := s.requireCart(ctx, req.CartOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.DeleteCartItem(ctx, cartID, req.Id); err != nil {
+		return nil, err
+	}
+
+	return s.cart(ctx, cartID)
+}
+
+// MergeCart moves a guest cart into the cart of the user who just logged in. A token
+// without a cart, e.g. one merged before, leaves the user cart as it is.
+func (s *cartService) MergeCart(ctx context.Context, req *entity.MergeCartRequest) (*entity.CartResponse, error) {
+	guestID, err := s.repo.FindCart(ctx, "", hashCartToken(req.Token))
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := s.repo.CreateCart(ctx, req.UserID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if guestID != "" {
+		if err := s.repo.MergeCarts(ctx, guestID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.cart(ctx, userID)
+}
+
+// findCart returns the cart of owner, or an empty id when it has none yet.
+func (s *cartService) findCart(ctx context.Context, owner entity.CartOwner) (string, error) {
+	if owner.UserID == "" && owner.Token == "" {
+		return "", nil
+	}
+
+	return s.repo.FindCart(ctx, owner.UserID, hashCartToken(owner.Token))
+}
+
+// requireCart is findCart for changes to existing lines, which need a cart to exist.
+func (s *cartService) requireCart(ctx context.Context, owner entity.CartOwner) (string, error) {
+	cartID, err := s.findCart(ctx, owner)
+	if err != nil {
+		return "", err
+	}
+
+	if cartID == "" {
+		return "", errmsg.NewCustomErrors(404, errmsg.WithMessage("Item keranjang tidak ditemukan"))
+	}
+
+	return cartID, nil
+}
+
+// cart reads a cart and checks every line against its product as it is now: lines of
+// deleted or unavailable products are flagged and left out of the totals, and lines
+// whose price changed or that ask for more than is in stock are flagged too.
+func (s *cartService) cart(ctx context.Context, cartID string) (*entity.CartResponse, error) {
+	var resp = &entity.CartResponse{Shops: make([]entity.CartShop, 0)}
+
+	if cartID == "" {
+		return resp, nil
+	}
+
+	lines, err := s.repo.GetCartLines(ctx, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	// lines come grouped by shop
+	for _, line := range lines {
+		if n := len(resp.Shops); n == 0 || resp.Shops[n-1].ShopID != line.ShopID {
+			resp.Shops = append(resp.Shops, entity.CartShop{
+				ShopID:   line.ShopID,
+				ShopName: line.ShopName,
+				Items:    make([]entity.CartItem, 0),
+				Total:    types.NewMoney(0, line.Harga.Currency),
+			})
+		}
+		shop := &resp.Shops[len(resp.Shops)-1]
+
+		item := entity.CartItem{
+			ID:                line.ID,
+			ProductID:         line.ProductID,
+			VariantID:         line.VariantID,
+			ProductName:       line.ProductName,
+			VariantCode:       line.VariantCode,
+			Quantity:          line.Quantity,
+			Harga:             line.Harga,
+			Subtotal:          types.NewMoney(line.Harga.Amount*int64(line.Quantity), line.Harga.Currency),
+			Stok:              line.Stok,
+			Deleted:           line.Deleted,
+			Available:         line.Available,
+			PriceChanged:      line.AddedHarga.Amount != line.Harga.Amount,
+			InsufficientStock: line.Quantity > line.Stok,
+		}
+		if item.PriceChanged {
+			previous := line.AddedHarga
+			item.PreviousHarga = &previous
+		}
+
+		shop.Items = append(shop.Items, item)
+		resp.ItemCount += line.Quantity
+
+		if !item.Deleted && item.Available {
+			shop.Total.Amount += item.Subtotal.Amount
+		}
+	}
+
+	return resp, nil
+}
+
+// newCartToken returns a random token for a guest cart.
+func newCartToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// hashCartToken is what is stored of a guest cart token, empty for no token.
+func hashCartToken(token string) string {
+	if token == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+type Category struct {
+	Id       string  `json:"id" db:"id"`
+	ParentId *string `json:"parent_id" db:"parent_id"`
+	Name     string  `json:"name" db:"name"`
+	Slug     string  `json:"slug" db:"slug"`
+	Position int     `json:"position" db:"position"`
+
+	Children []*Category `json:"children" db:"-"`
+}
+
+type CategoryTreeResponse struct {
+	Items []*Category `json:"items"`
+}
+
+type CreateCategoryRequest struct {
+	ParentId *string `json:"parent_id" validate:"omitempty,uuid" db:"parent_id"`
+	Name     string  `json:"name" validate:"required,max=255" db:"name"`
+	Slug     string  `json:"slug" validate:"omitempty,max=255" db:"slug"`
+	Position int     `json:"position" validate:"min=0" db:"position"`
+}
+
+type UpdateCategoryRequest struct {
+	Id       string  `params:"id" validate:"uuid" db:"id"`
+	ParentId *string `json:"parent_id" validate:"omitempty,uuid" db:"parent_id"`
+	Name     string  `json:"name" validate:"required,max=255" db:"name"`
+	Slug     string  `json:"slug" validate:"omitempty,max=255" db:"slug"`
+	Position int     `json:"position" validate:"min=0" db:"position"`
+}
+
+type DeleteCategoryRequest struct {
+	Id string `params:"id" validate:"uuid" db:"id"`
+}
+
+type CategoryProductsRequest struct {
+	// Category is either the category id or its slug.
+	Category string `params:"category" validate:"required"`
+	Page     int    `query:"page" validate:"required"`
+	Paginate int    `query:"paginate" validate:"required"`
+}
+
+func (r *CategoryProductsRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type CategoryProductItem struct {
+	Id        string      `json:"id" db:"id"`
+	UserId    string      `json:"user_id" db:"user_id"`
+	ShopName  string      `json:"shop_name" db:"shop_name"`
+	Name      string      `json:"name" db:"name"`
+	Harga     types.Money `json:"harga" db:"harga"`
+	Stok      int         `json:"stok" db:"stok"`
+	Penilaian float64     `json:"penilaian" db:"penilaian"`
+	Terjual   int         `json:"terjual" db:"terjual"`
+	Merek     string      `json:"merek" db:"merek"`
+
+	// HargaEfektif is the flash price while FlashSale has quota left, harga otherwise.
+	HargaEfektif types.Money        `json:"harga_efektif" db:"harga_efektif"`
+	FlashSale    *CategoryFlashSale `json:"flash_sale" db:"-"`
+}
+
+// CategoryFlashSale is the flash sale a listed product is in right now.
+type CategoryFlashSale struct {
+	ID        string      `json:"id"`
+	Harga     types.Money `json:"harga"`
+	Kuota     int         `json:"kuota"`
+	SisaKuota int         `json:"sisa_kuota"`
+	EndsAt    time.Time   `json:"ends_at"`
+}
+
+type CategoryProductsResponse struct {
+	Category Category              `json:"category"`
+	Items    []CategoryProductItem `json:"items"`
+	Meta     types.Meta            `json:"meta"`
+}
@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"codebase-app/internal/module/category/entity"
+	"context"
+)
+
+type CategoryRepository interface {
+	GetCategories(ctx context.Context) ([]entity.Category, error)
+	GetCategory(ctx context.Context, idOrSlug string) (*entity.Category, error)
+	CreateCategory(ctx context.Context, req *entity.CreateCategoryRequest) (*entity.Category, error)
+	UpdateCategory(ctx context.Context, req *entity.UpdateCategoryRequest) (*entity.Category, error)
+	DeleteCategory(ctx context.Context, req *entity.DeleteCategoryRequest) error
+	IsDescendant(ctx context.Context, ancestorId, id string) (bool, error)
+	GetCategoryProducts(ctx context.Context, categoryId string, req *entity.CategoryProductsRequest) ([]entity.CategoryProductItem, int, error)
+}
+
+type CategoryService interface {
+	GetCategoryTree(ctx context.Context) (*entity.CategoryTreeResponse, error)
+	CreateCategory(ctx context.Context, req *entity.CreateCategoryRequest) (*entity.Category, error)
+	UpdateCategory(ctx context.Context, req *entity.UpdateCategoryRequest) (*entity.Category, error)
+	DeleteCategory(ctx context.Context, req *entity.DeleteCategoryRequest) error
+	GetCategoryProducts(ctx context.Context, req *entity.CategoryProductsRequest) (*entity.CategoryProductsResponse, error)
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"codebase-app/internal/module/category/entity"
+	"codebase-app/internal/module/category/ports"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/types"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.CategoryRepository = &categoryRepository{}
+
+type categoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewCategoryRepository(db *sqlx.DB) *categoryRepository {
+	return &categoryRepository{
+		db: db,
+	}
+}
+
+func (r *categoryRepository) GetCategories(ctx context.Context) ([]entity.Category, error) {
+	var data = make([]entity.Category, 0)
+
+	query := `
+		SELECT id, parent_id, name, slug, position
+		FROM categories
+		WHERE deleted_at IS NULL
+		ORDER BY position, name
+	`
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query))
+	if err != nil {
+		log.Error().Err(err).Msg("repository::GetCategories - Failed to get categories")
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (r *categoryRepository) GetCategory(ctx context.Context, idOrSlug string) (*entity.Category, error) {
+	var resp = new(entity.Category)
+
+	query := `
+		SELECT id, parent_id, name, slug, position
+		FROM categories
+		WHERE (id::text = ? OR slug = ?) AND deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, resp, r.db.Rebind(query), idOrSlug, idOrSlug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Err(err).Str("category", idOrSlug).Msg("repository::GetCategory - Category not found")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Kategori tidak ditemukan"))
+		}
+		log.Error().Err(err).Str("category", idOrSlug).Msg("repository::GetCategory - Failed to get category")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (r *categoryRepository) CreateCategory(ctx context.Context, req *entity.CreateCategoryRequest) (*entity.Category, error) {
+	var resp = new(entity.Category)
+
+	query := `
+		INSERT INTO categories (parent_id, name, slug, position)
+		VALUES (?, ?, ?, ?)
+		RETURNING id, parent_id, name, slug, position
+	`
+
+	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query),
+		req.ParentId,
+		req.Name,
+		req.Slug,
+		req.Position).StructScan(resp)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CreateCategory - Failed to create category")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (r *categoryRepository) UpdateCategory(ctx context.Context, req *entity.UpdateCategoryRequest) (*entity.Category, error) {
+	var resp = new(entity.Category)
+
+	query := `
+		UPDATE categories
+		SET parent_id = ?, name = ?, slug = ?, position = ?, updated_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL
+		RETURNING id, parent_id, name, slug, position
+	`
+
+	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query),
+		req.ParentId,
+		req.Name,
+		req.Slug,
+		req.Position,
+		req.Id).StructScan(resp)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Warn().Err(err).Any("payload", req).Msg("repository::UpdateCategory - Category not found")
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Kategori tidak ditemukan"))
+		}
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateCategory - Failed to update category")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (r *categoryRepository) DeleteCategory(ctx context.Context, req *entity.DeleteCategoryRequest) error {
+	var children int
+
+	queryChildren := `SELECT COUNT(id) FROM categories WHERE parent_id = ? AND deleted_at IS NULL`
+	if err := r.db.GetContext(ctx, &children, r.db.Rebind(queryChildren), req.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::DeleteCategory - Failed to count children")
+		return err
+	}
+
+	if children > 0 {
+		log.Warn().Any("payload", req).Msg("repository::DeleteCategory - Category still has children")
+		return errmsg.NewCustomErrors(409, errmsg.WithMessage("Kategori masih memiliki subkategori"))
+	}
+
+	query := `UPDATE categories SET deleted_at = NOW() WHERE id = ? AND deleted_at IS NULL`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), req.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::DeleteCategory - Failed to delete category")
+		return err
+	}
+
+	return nil
+}
+
+// IsDescendant reports whether id is ancestorId itself or lies somewhere below it in the tree.
+func (r *categoryRepository) IsDescendant(ctx context.Context, ancestorId, id string) (bool, error) {
+	var found bool
+
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = ?
+			UNION ALL
+			SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+		)
+		SELECT EXISTS (SELECT 1 FROM subtree WHERE id = ?)
+	`
+
+	err := r.db.GetContext(ctx, &found, r.db.Rebind(query), ancestorId, id)
+	if err != nil {
+		log.Error().Err(err).Str("ancestor_id", ancestorId).Str("id", id).Msg("repository::IsDescendant - Failed to walk category tree")
+		return false, err
+	}
+
+	return found, nil
+}
+
+func (r *categoryRepository) GetCategoryProducts(ctx context.Context, categoryId string, req *entity.CategoryProductsRequest) ([]entity.CategoryProductItem, int, error) {
+	type dao struct {
+		TotalData      int        `db:"total_data"`
+		FlashSaleID    *string    `db:"flash_sale_id"`
+		FlashHarga     *int64     `db:"flash_harga"`
+		FlashQuota     *int       `db:"flash_quota"`
+		FlashRemaining *int       `db:"flash_remaining"`
+		FlashEndsAt    *time.Time `db:"flash_ends_at"`
+		entity.CategoryProductItem
+	}
+
+	var (
+		data  = make([]dao, 0, req.Paginate)
+		items = make([]entity.CategoryProductItem, 0, req.Paginate)
+		total int
+	)
+
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id WHERE c.deleted_at IS NULL
+		)
+		SELECT
+			COUNT(product.id) OVER() AS total_data,
+			product.id,
+			product.user_id,
+			shops.name AS shop_name,
+			product.name,
+			ROW(product.harga, product.currency) AS harga,
+			ROW(CASE WHEN flash.remaining > 0 THEN flash.harga ELSE product.harga END, product.currency) AS harga_efektif,
+			flash.flash_sale_id,
+			flash.harga AS flash_harga,
+			flash.quota AS flash_quota,
+			flash.remaining AS flash_remaining,
+			flash.ends_at AS flash_ends_at,
+			product.stok,
+			COALESCE(product.penilaian, 0) AS penilaian,
+			product.terjual,
+			COALESCE(product.merek, '') AS merek
+		FROM product
+		JOIN shops ON shops.id = product.shop_id
+		LEFT JOIN active_flash_sale_items flash ON flash.product_id = product.id
+		WHERE
+			product.deleted_at IS NULL
+			AND product.status = 'published'
+			AND EXISTS (
+				SELECT 1 FROM product_categories
+				WHERE product_categories.product_id = product.id
+					AND product_categories.category_id IN (SELECT id FROM subtree)
+			)
+		ORDER BY product.created_at DESC, product.id
+		LIMIT ? OFFSET ?
+	`
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query),
+		categoryId,
+		req.Paginate,
+		req.Paginate*(req.Page-1),
+	)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::GetCategoryProducts - Failed to get products")
+		return nil, 0, err
+	}
+
+	if len(data) > 0 {
+		total = data[0].TotalData
+	}
+
+	for _, d := range data {
+		item := d.CategoryProductItem
+		if d.FlashSaleID != nil {
+			item.FlashSale = &entity.CategoryFlashSale{
+				ID:        *d.FlashSaleID,
+				Harga:     types.NewMoney(*d.FlashHarga, item.Harga.Currency),
+				Kuota:     *d.FlashQuota,
+				SisaKuota: *d.FlashRemaining,
+				EndsAt:    *d.FlashEndsAt,
+			}
+		}
+		items = append(items, item)
+	}
+
+	return items, total, nil
+}
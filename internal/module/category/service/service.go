@@ -0,0 +1,127 @@
+package service
+
+import (
+	"codebase-app/internal/module/category/entity"
+	"codebase-app/internal/module/category/ports"
+	"codebase-app/pkg"
+	"codebase-app/pkg/errmsg"
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.CategoryService = &categoryService{}
+
+type categoryService struct {
+	repo ports.CategoryRepository
+}
+
+func NewCategoryService(repo ports.CategoryRepository) *categoryService {
+	return &categoryService{
+		repo: repo,
+	}
+}
+
+func (s *categoryService) GetCategoryTree(ctx context.Context) (*entity.CategoryTreeResponse, error) {
+	var resp = new(entity.CategoryTreeResponse)
+
+	categories, err := s.repo.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*entity.Category, len(categories))
+	for i := range categories {
+		categories[i].Children = make([]*entity.Category, 0)
+		nodes[categories[i].Id] = &categories[i]
+	}
+
+	// categories are already ordered by position, so appending keeps siblings in order
+	resp.Items = make([]*entity.Category, 0)
+	for i := range categories {
+		node := &categories[i]
+		if node.ParentId == nil {
+			resp.Items = append(resp.Items, node)
+			continue
+		}
+
+		parent, ok := nodes[*node.ParentId]
+		if !ok {
+			log.Warn().Any("category", node).Msg("service::GetCategoryTree - Parent category not found, treating as root")
+			resp.Items = append(resp.Items, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+
+	return resp, nil
+}
+
+func (s *categoryService) CreateCategory(ctx context.Context, req *entity.CreateCategoryRequest) (*entity.Category, error) {
+	req.Slug = slugOrName(req.Slug, req.Name)
+	if req.Slug == "" {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("slug", "slug tidak valid."))
+	}
+
+	if req.ParentId != nil {
+		if _, err := s.repo.GetCategory(ctx, *req.ParentId); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.CreateCategory(ctx, req)
+}
+
+func (s *categoryService) UpdateCategory(ctx context.Context, req *entity.UpdateCategoryRequest) (*entity.Category, error) {
+	req.Slug = slugOrName(req.Slug, req.Name)
+	if req.Slug == "" {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("slug", "slug tidak valid."))
+	}
+
+	if req.ParentId != nil {
+		// moving a category under itself or one of its children would detach the whole branch
+		cyclic, err := s.repo.IsDescendant(ctx, req.Id, *req.ParentId)
+		if err != nil {
+			return nil, err
+		}
+		if cyclic {
+			log.Warn().Any("payload", req).Msg("service::UpdateCategory - Parent is a descendant of the category")
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("parent_id", "parent tidak boleh kategori itu sendiri atau turunannya."))
+		}
+	}
+
+	return s.repo.UpdateCategory(ctx, req)
+}
+
+func (s *categoryService) DeleteCategory(ctx context.Context, req *entity.DeleteCategoryRequest) error {
+	return s.repo.DeleteCategory(ctx, req)
+}
+
+func (s *categoryService) GetCategoryProducts(ctx context.Context, req *entity.CategoryProductsRequest) (*entity.CategoryProductsResponse, error) {
+	var resp = new(entity.CategoryProductsResponse)
+
+	category, err := s.repo.GetCategory(ctx, req.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	items, total, err := s.repo.GetCategoryProducts(ctx, category.Id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Category = *category
+	resp.Items = items
+	resp.Meta.TotalData = total
+	resp.Meta.CountTotalPage(req.Page, req.Paginate, resp.Meta.TotalData)
+
+	return resp, nil
+}
+
+func slugOrName(slug, name string) string {
+	if slug != "" {
+		return pkg.Slugify(slug)
+	}
+
+	return pkg.Slugify(name)
+}
@@ -0,0 +1,125 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+// Where a flash sale stands, worked out from its window when it is read.
+const (
+	FlashSaleScheduled = "scheduled"
+	FlashSaleActive    = "active"
+	FlashSaleEnded     = "ended"
+)
+
+const (
+	ClaimActive    = "active"
+	ClaimConfirmed = "confirmed"
+	ClaimReleased  = "released"
+	ClaimExpired   = "expired"
+)
+
+type FlashSaleItemRequest struct {
+	ProductID string      `json:"product_id" validate:"required,uuid"`
+	Harga     types.Money `json:"harga" validate:"required"`
+	Quota     int         `json:"quota" validate:"required,min=1"`
+}
+
+// FlashSaleRequest carries the editable fields of a flash sale; updates replace all of them.
+type FlashSaleRequest struct {
+	Name     string                 `json:"name" validate:"required,max=255"`
+	StartsAt time.Time              `json:"starts_at" validate:"required"`
+	EndsAt   time.Time              `json:"ends_at" validate:"required"`
+	Items    []FlashSaleItemRequest `json:"items" validate:"required,min=1,max=100,dive"`
+}
+
+type CreateFlashSaleRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	ShopID string `params:"id" validate:"uuid"`
+	FlashSaleRequest
+}
+
+// UpdateFlashSaleRequest reschedules a flash sale; only sales that have not started
+// yet may change.
+type UpdateFlashSaleRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	ShopID string `params:"id" validate:"uuid"`
+	Id     string `params:"flash_sale_id" validate:"uuid"`
+	FlashSaleRequest
+}
+
+// DeleteFlashSaleRequest cancels a flash sale. A running sale stops at once; what was
+// already claimed keeps its price.
+type DeleteFlashSaleRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	ShopID string `params:"id" validate:"uuid"`
+	Id     string `params:"flash_sale_id" validate:"uuid"`
+}
+
+// FlashSalesRequest lists the flash sales of ShopID for its owner, or the running and
+// upcoming ones of every shop when ShopID is empty.
+type FlashSalesRequest struct {
+	UserID   string `prop:"user_id" validate:"omitempty,uuid"`
+	ShopID   string `params:"id" validate:"omitempty,uuid"`
+	Page     int    `query:"page" validate:"required"`
+	Paginate int    `query:"paginate" validate:"required,max=100"`
+}
+
+func (r *FlashSalesRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+// FlashSale sells its items at a special price between StartsAt and EndsAt, for as
+// long as their quota lasts.
+type FlashSale struct {
+	ID        string          `json:"id" db:"id"`
+	ShopID    string          `json:"shop_id" db:"shop_id"`
+	Name      string          `json:"name" db:"name"`
+	Status    string          `json:"status" db:"-"`
+	StartsAt  time.Time       `json:"starts_at" db:"starts_at"`
+	EndsAt    time.Time       `json:"ends_at" db:"ends_at"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+	Items     []FlashSaleItem `json:"items" db:"-"`
+}
+
+// SetStatus fills Status for the time now.
+func (f *FlashSale) SetStatus(now time.Time) {
+	switch {
+	case now.Before(f.StartsAt):
+		f.Status = FlashSaleScheduled
+	case now.Before(f.EndsAt):
+		f.Status = FlashSaleActive
+	default:
+		f.Status = FlashSaleEnded
+	}
+}
+
+type FlashSaleItem struct {
+	ID          string      `json:"id" db:"id"`
+	ProductID   string      `json:"product_id" db:"product_id"`
+	ProductName string      `json:"product_name" db:"product_name"`
+	Harga       types.Money `json:"harga" db:"harga"`
+	Kuota       int         `json:"kuota" db:"quota"`
+	SisaKuota   int         `json:"sisa_kuota" db:"remaining"`
+}
+
+type FlashSalesResponse struct {
+	Items []FlashSale `json:"items"`
+	Meta  types.Meta  `json:"meta"`
+}
+
+// ClaimQuotaRequest takes Quantity units of the quota of a flash sale item for Holder
+// (e.g. an order number) during TTL.
+type ClaimQuotaRequest struct {
+	FlashSaleItemID string
+	Holder          string
+	Quantity        int
+	TTL             time.Duration
+}
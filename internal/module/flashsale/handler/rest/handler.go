@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/middleware"
+	"codebase-app/internal/module/flashsale/entity"
+	"codebase-app/internal/module/flashsale/ports"
+	"codebase-app/internal/module/flashsale/repository"
+	"codebase-app/internal/module/flashsale/service"
+	shopRepository "codebase-app/internal/module/shop/repository"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type flashSaleHandler struct {
+	service ports.FlashSaleService
+}
+
+func NewFlashSaleHandler() *flashSaleHandler {
+	var (
+		handler = new(flashSaleHandler)
+		repo    = repository.NewFlashSaleRepository(adapter.Adapters.ShopeefunPostgres)
+		shop    = shopRepository.NewShopRepository(adapter.Adapters.ShopeefunPostgres)
+		service = service.NewFlashSaleService(repo, shop)
+	)
+	handler.service = service
+
+	return handler
+}
+
+func (h *flashSaleHandler) Register(router fiber.Router) {
+	router.Get("/flash-sales", h.GetFlashSales)
+	router.Post("/shops/:id/flash-sales", middleware.UserIdHeader, h.CreateFlashSale)
+	router.Get("/shops/:id/flash-sales", middleware.UserIdHeader, h.GetFlashSales)
+	router.Patch("/shops/:id/flash-sales/:flash_sale_id", middleware.UserIdHeader, h.UpdateFlashSale)
+	router.Delete("/shops/:id/flash-sales/:flash_sale_id", middleware.UserIdHeader, h.DeleteFlashSale)
+}
+
+func (h *flashSaleHandler) CreateFlashSale(c *fiber.Ctx) error {
+	var (
+		req = new(entity.CreateFlashSaleRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::CreateFlashSale - Parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserID = l.UserId
+	req.ShopID = c.Params("id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::CreateFlashSale - Validate request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.CreateFlashSale(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(resp, ""))
+}
+
+func (h *flashSaleHandler) GetFlashSales(c *fiber.Ctx) error {
+	var (
+		req = new(entity.FlashSalesRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetFlashSales - Parse request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserID = l.UserId
+	req.ShopID = c.Params("id")
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetFlashSales - Validate request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetFlashSales(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
+
+func (h *flashSaleHandler) UpdateFlashSale(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdateFlashSaleRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateFlashSale - Parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserID = l.UserId
+	req.ShopID = c.Params("id")
+	req.Id = c.Params("flash_sale_id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::UpdateFlashSale - Validate request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.UpdateFlashSale(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
+
+func (h *flashSaleHandler) DeleteFlashSale(c *fiber.Ctx) error {
+	var (
+		req = new(entity.DeleteFlashSaleRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserID = l.UserId
+	req.ShopID = c.Params("id")
+	req.Id = c.Params("flash_sale_id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::DeleteFlashSale - Validate request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := h.service.DeleteFlashSale(ctx, req); err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(nil, ""))
+}
@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/flashsale/ports"
+	"codebase-app/internal/module/flashsale/repository"
+	"codebase-app/internal/module/flashsale/service"
+	shopRepository "codebase-app/internal/module/shop/repository"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// claimSweepInterval is how often the quota of unpaid flash sale claims is given back.
+const claimSweepInterval = time.Minute
+
+type claimSweeper struct {
+	service ports.FlashSaleService
+}
+
+// NewClaimSweeper builds the background job expiring flash sale claims past their TTL.
+func NewClaimSweeper() *claimSweeper {
+	var (
+		worker  = new(claimSweeper)
+		repo    = repository.NewFlashSaleRepository(adapter.Adapters.ShopeefunPostgres)
+		shop    = shopRepository.NewShopRepository(adapter.Adapters.ShopeefunPostgres)
+		service = service.NewFlashSaleService(repo, shop)
+	)
+	worker.service = service
+
+	return worker
+}
+
+// Run sweeps until ctx is cancelled.
+func (w *claimSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(claimSweepInterval)
+	defer ticker.Stop()
+
+	log.Info().Msg("worker::ClaimSweeper - Started")
+
+	for {
+		if err := w.service.ExpireClaims(ctx); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("worker::ClaimSweeper - Failed to expire claims")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("worker::ClaimSweeper - Stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package ports
+
+import (
+	"codebase-app/internal/module/flashsale/entity"
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type FlashSaleRepository interface {
+	CreateFlashSale(ctx context.Context, req *entity.CreateFlashSaleRequest) (*entity.FlashSale, error)
+	UpdateFlashSale(ctx context.Context, req *entity.UpdateFlashSaleRequest) (*entity.FlashSale, error)
+	DeleteFlashSale(ctx context.Context, req *entity.DeleteFlashSaleRequest) error
+	GetFlashSales(ctx context.Context, req *entity.FlashSalesRequest) (*entity.FlashSalesResponse, error)
+	ClaimQuota(ctx context.Context, req *entity.ClaimQuotaRequest) error
+	ConfirmClaims(ctx context.Context, tx *sqlx.Tx, holder string) error
+	ReleaseClaims(ctx context.Context, holder string) error
+	ExpireClaims(ctx context.Context) (int64, error)
+}
+
+// ShopRepository is the part of the shop module flash sales rely on.
+type ShopRepository interface {
+	CheckShopOwner(ctx context.Context, shopID, userID string) error
+}
+
+type FlashSaleService interface {
+	CreateFlashSale(ctx context.Context, req *entity.CreateFlashSaleRequest) (*entity.FlashSale, error)
+	UpdateFlashSale(ctx context.Context, req *entity.UpdateFlashSaleRequest) (*entity.FlashSale, error)
+	DeleteFlashSale(ctx context.Context, req *entity.DeleteFlashSaleRequest) error
+	GetFlashSales(ctx context.Context, req *entity.FlashSalesRequest) (*entity.FlashSalesResponse, error)
+	ExpireClaims(ctx context.Context) error
+}
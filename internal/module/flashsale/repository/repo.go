@@ -0,0 +1,491 @@
+package repository
+
+import (
+	"codebase-app/internal/module/flashsale/entity"
+	"codebase-app/internal/module/flashsale/ports"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.FlashSaleRepository = &flashSaleRepository{}
+
+type flashSaleRepository struct {
+	db *sqlx.DB
+}
+
+func NewFlashSaleRepository(db *sqlx.DB) *flashSaleRepository {
+	return &flashSaleRepository{
+		db: db,
+	}
+}
+
+const flashSaleColumns = `id, shop_id, name, starts_at, ends_at, created_at, updated_at`
+
+func (r *flashSaleRepository) CreateFlashSale(ctx context.Context, req *entity.CreateFlashSaleRequest) (*entity.FlashSale, error) {
+	var id string
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CreateFlashSale - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO flash_sales (shop_id, name, starts_at, ends_at, created_by)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	err = tx.GetContext(ctx, &id, tx.Rebind(query), req.ShopID, req.Name, req.StartsAt, req.EndsAt, req.UserID)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CreateFlashSale - Failed to insert flash sale")
+		return nil, err
+	}
+
+	if err := setItems(ctx, tx, id, req.ShopID, &req.FlashSaleRequest); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CreateFlashSale - Failed to commit transaction")
+		return nil, err
+	}
+
+	return r.getFlashSale(ctx, id)
+}
+
+func (r *flashSaleRepository) UpdateFlashSale(ctx context.Context, req *entity.UpdateFlashSaleRequest) (*entity.FlashSale, error) {
+	var startsAt time.Time
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateFlashSale - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	queryLock := `
+		SELECT starts_at FROM flash_sales
+		WHERE id = ? AND shop_id = ? AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	err = tx.GetContext(ctx, &startsAt, tx.Rebind(queryLock), req.Id, req.ShopID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Flash sale tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateFlashSale - Failed to lock flash sale")
+		return nil, err
+	}
+
+	// quota may already be claimed once a sale runs
+	if !time.Now().Before(startsAt) {
+		return nil, errmsg.NewCustomErrors(409, errmsg.WithMessage("Flash sale yang sudah dimulai tidak dapat diubah"))
+	}
+
+	query := `
+		UPDATE flash_sales SET name = ?, starts_at = ?, ends_at = ?, updated_at = NOW()
+		WHERE id = ?
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), req.Name, req.StartsAt, req.EndsAt, req.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateFlashSale - Failed to update flash sale")
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM flash_sale_items WHERE flash_sale_id = ?`), req.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateFlashSale - Failed to clear flash sale items")
+		return nil, err
+	}
+
+	if err := setItems(ctx, tx, req.Id, req.ShopID, &req.FlashSaleRequest); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateFlashSale - Failed to commit transaction")
+		return nil, err
+	}
+
+	return r.getFlashSale(ctx, req.Id)
+}
+
+// setItems saves the items of a flash sale. Each product is locked while it is checked,
+// so two sales cannot both take it for overlapping windows. A flash sale has one price
+// per product, so products with SKUs, each priced on its own, cannot be put in one. The
+// flash price must be below the regular price of the product, in the same currency.
+func setItems(ctx context.Context, tx *sqlx.Tx, flashSaleID, shopID string, req *entity.FlashSaleRequest) error {
+	// products are locked in a fixed order so two sales cannot deadlock each other
+	order := make([]int, len(req.Items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return req.Items[order[a]].ProductID < req.Items[order[b]].ProductID })
+
+	for _, i := range order {
+		var (
+			item    = req.Items[i]
+			field   = fmt.Sprintf("items[%d]", i)
+			product struct {
+				Harga    int64  `db:"harga"`
+				Currency string `db:"currency"`
+			}
+			hasVariants bool
+			overlaps    bool
+		)
+
+		queryProduct := `
+			SELECT harga, currency
+			FROM product
+			WHERE id = ? AND shop_id = ? AND deleted_at IS NULL
+			FOR UPDATE
+		`
+		err := tx.GetContext(ctx, &product, tx.Rebind(queryProduct), item.ProductID, shopID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errmsg.NewCustomErrors(400, errmsg.WithErrors(field+".product_id", "produk tidak ditemukan di toko ini."))
+		}
+		if err != nil {
+			log.Error().Err(err).Any("payload", item).Msg("repository::setItems - Failed to lock product")
+			return err
+		}
+
+		// read after the lock, SKUs are only added while the product is held
+		queryVariants := `
+			SELECT EXISTS (
+				SELECT 1 FROM product_variants
+				WHERE product_id = ? AND deleted_at IS NULL
+			)
+		`
+		if err := tx.GetContext(ctx, &hasVariants, tx.Rebind(queryVariants), item.ProductID); err != nil {
+			log.Error().Err(err).Any("payload", item).Msg("repository::setItems - Failed to check variants")
+			return err
+		}
+		if hasVariants {
+			return errmsg.NewCustomErrors(400, errmsg.WithErrors(field+".product_id", "produk dengan varian tidak dapat masuk flash sale."))
+		}
+
+		if item.Harga.CurrencyCode() != product.Currency {
+			return errmsg.NewCustomErrors(400, errmsg.WithErrors(field+".harga", "mata uang harus sama dengan produk."))
+		}
+		if item.Harga.Amount >= product.Harga {
+			return errmsg.NewCustomErrors(400, errmsg.WithErrors(field+".harga", "harga flash sale harus lebih murah dari harga produk."))
+		}
+
+		queryOverlap := `
+			SELECT EXISTS (
+				SELECT 1 FROM flash_sale_items
+				JOIN flash_sales ON flash_sales.id = flash_sale_items.flash_sale_id
+				WHERE flash_sale_items.product_id = ?
+					AND flash_sales.id <> ?
+					AND flash_sales.deleted_at IS NULL
+					AND flash_sales.starts_at < ?
+					AND flash_sales.ends_at > ?
+			)
+		`
+		err = tx.GetContext(ctx, &overlaps, tx.Rebind(queryOverlap), item.ProductID, flashSaleID, req.EndsAt, req.StartsAt)
+		if err != nil {
+			log.Error().Err(err).Any("payload", item).Msg("repository::setItems - Failed to check overlapping flash sales")
+			return err
+		}
+		if overlaps {
+			return errmsg.NewCustomErrors(409, errmsg.WithErrors(field+".product_id", "produk sudah ada di flash sale lain pada waktu yang sama."))
+		}
+
+		queryInsert := `
+			INSERT INTO flash_sale_items (flash_sale_id, product_id, harga, currency, quota)
+			VALUES (?, ?, ?, ?, ?)
+		`
+		_, err = tx.ExecContext(ctx, tx.Rebind(queryInsert), flashSaleID, item.ProductID, item.Harga.Amount, product.Currency, item.Quota)
+		if err != nil {
+			log.Error().Err(err).Any("payload", item).Msg("repository::setItems - Failed to insert flash sale item")
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *flashSaleRepository) DeleteFlashSale(ctx context.Context, req *entity.DeleteFlashSaleRequest) error {
+	query := `
+		UPDATE flash_sales SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = ? AND shop_id = ? AND deleted_at IS NULL
+	`
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(query), req.Id, req.ShopID)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::DeleteFlashSale - Failed to delete flash sale")
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::DeleteFlashSale - Failed to read affected rows")
+		return err
+	}
+	if n == 0 {
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Flash sale tidak ditemukan"))
+	}
+
+	return nil
+}
+
+// GetFlashSales lists every flash sale of a shop, latest first, or the running and
+// upcoming ones of all shops, soonest first, when req.ShopID is empty.
+func (r *flashSaleRepository) GetFlashSales(ctx context.Context, req *entity.FlashSalesRequest) (*entity.FlashSalesResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.FlashSale
+	}
+
+	var (
+		data []dao
+		args []any
+		resp = &entity.FlashSalesResponse{Items: make([]entity.FlashSale, 0, req.Paginate)}
+	)
+
+	query := `
+		SELECT COUNT(id) OVER() AS total_data, ` + flashSaleColumns + `
+		FROM flash_sales
+		WHERE deleted_at IS NULL AND ends_at > NOW()
+		ORDER BY starts_at, id
+		LIMIT ? OFFSET ?
+	`
+	if req.ShopID != "" {
+		query = `
+			SELECT COUNT(id) OVER() AS total_data, ` + flashSaleColumns + `
+			FROM flash_sales
+			WHERE shop_id = ? AND deleted_at IS NULL
+			ORDER BY starts_at DESC, id DESC
+			LIMIT ? OFFSET ?
+		`
+		args = append(args, req.ShopID)
+	}
+	args = append(args, req.Paginate, req.Paginate*(req.Page-1))
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::GetFlashSales - Failed to get flash sales")
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(data))
+	for _, d := range data {
+		ids = append(ids, d.ID)
+	}
+
+	items, err := r.getItems(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, d := range data {
+		sale := d.FlashSale
+		sale.Items = items[sale.ID]
+		sale.SetStatus(now)
+		resp.Items = append(resp.Items, sale)
+	}
+
+	if len(data) > 0 {
+		resp.Meta.TotalData = data[0].TotalData
+	}
+	resp.Meta.CountTotalPage(req.Page, req.Paginate, resp.Meta.TotalData)
+
+	return resp, nil
+}
+
+func (r *flashSaleRepository) getFlashSale(ctx context.Context, id string) (*entity.FlashSale, error) {
+	var resp = new(entity.FlashSale)
+
+	query := `SELECT ` + flashSaleColumns + ` FROM flash_sales WHERE id = ?`
+	err := r.db.GetContext(ctx, resp, r.db.Rebind(query), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Flash sale tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("repository::getFlashSale - Failed to get flash sale")
+		return nil, err
+	}
+
+	items, err := r.getItems(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	resp.Items = items[id]
+	resp.SetStatus(time.Now())
+
+	return resp, nil
+}
+
+func (r *flashSaleRepository) getItems(ctx context.Context, flashSaleIDs []string) (map[string][]entity.FlashSaleItem, error) {
+	type dao struct {
+		FlashSaleID string `db:"flash_sale_id"`
+		entity.FlashSaleItem
+	}
+
+	var (
+		data []dao
+		resp = make(map[string][]entity.FlashSaleItem, len(flashSaleIDs))
+	)
+
+	if len(flashSaleIDs) == 0 {
+		return resp, nil
+	}
+
+	query := `
+		SELECT
+			flash_sale_items.flash_sale_id,
+			flash_sale_items.id,
+			flash_sale_items.product_id,
+			product.name AS product_name,
+			ROW(flash_sale_items.harga, flash_sale_items.currency) AS harga,
+			flash_sale_items.quota,
+			flash_sale_items.quota - flash_sale_items.claimed AS remaining
+		FROM flash_sale_items
+		JOIN product ON product.id = flash_sale_items.product_id
+		WHERE flash_sale_items.flash_sale_id = ANY(?)
+		ORDER BY flash_sale_items.flash_sale_id, product.name, flash_sale_items.id
+	`
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), pq.Array(flashSaleIDs)); err != nil {
+		log.Error().Err(err).Any("payload", flashSaleIDs).Msg("repository::getItems - Failed to get flash sale items")
+		return nil, err
+	}
+
+	for _, d := range data {
+		resp[d.FlashSaleID] = append(resp[d.FlashSaleID], d.FlashSaleItem)
+	}
+
+	return resp, nil
+}
+
+// ClaimQuota takes quota of a running flash sale item in a single statement: the
+// conditional increment of claimed is the only check, so concurrent buyers can never
+// claim more than the quota between them.
+func (r *flashSaleRepository) ClaimQuota(ctx context.Context, req *entity.ClaimQuotaRequest) error {
+	var id string
+
+	query := `
+		WITH item AS (
+			UPDATE flash_sale_items SET claimed = claimed + ?
+			WHERE id = ?
+				AND claimed + ? <= quota
+				AND EXISTS (
+					SELECT 1 FROM flash_sales
+					WHERE flash_sales.id = flash_sale_items.flash_sale_id
+						AND flash_sales.deleted_at IS NULL
+						AND flash_sales.starts_at <= NOW()
+						AND flash_sales.ends_at > NOW()
+				)
+			RETURNING id
+		)
+		INSERT INTO flash_sale_claims (flash_sale_item_id, holder, quantity, expires_at)
+		SELECT id, ?, ?, NOW() + CAST(? AS interval) FROM item
+		RETURNING id
+	`
+	err := r.db.GetContext(ctx, &id, r.db.Rebind(query),
+		req.Quantity,
+		req.FlashSaleItemID,
+		req.Quantity,
+		req.Holder,
+		req.Quantity,
+		fmt.Sprintf("%d seconds", int(req.TTL.Seconds())),
+	)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Error().Err(err).Any("payload", req).Msg("repository::ClaimQuota - Failed to claim quota")
+		return err
+	}
+
+	var remaining int
+
+	queryRemaining := `SELECT COALESCE((SELECT remaining FROM active_flash_sale_items WHERE id = ?), 0)`
+	if err := r.db.GetContext(ctx, &remaining, r.db.Rebind(queryRemaining), req.FlashSaleItemID); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::ClaimQuota - Failed to read remaining quota")
+		return err
+	}
+
+	return errmsg.NewCustomErrors(409,
+		errmsg.WithMessage("Kuota flash sale tidak mencukupi"),
+		errmsg.WithErrors("quantity", fmt.Sprintf("sisa kuota flash sale %d.", remaining)),
+	)
+}
+
+// ConfirmClaims keeps the quota claimed by holder for good. It runs inside tx, the
+// transaction of the change that sells the claimed items, and fails when one of the
+// claims has lapsed, since its quota may have gone to someone else.
+func (r *flashSaleRepository) ConfirmClaims(ctx context.Context, tx *sqlx.Tx, holder string) error {
+	var lapsed bool
+
+	query := `
+		UPDATE flash_sale_claims SET status = ?, updated_at = NOW()
+		WHERE holder = ? AND status = ? AND expires_at > NOW()
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), entity.ClaimConfirmed, holder, entity.ClaimActive); err != nil {
+		log.Error().Err(err).Str("holder", holder).Msg("repository::ConfirmClaims - Failed to confirm claims")
+		return err
+	}
+
+	queryLapsed := `SELECT EXISTS (SELECT 1 FROM flash_sale_claims WHERE holder = ? AND status <> ?)`
+	if err := tx.GetContext(ctx, &lapsed, tx.Rebind(queryLapsed), holder, entity.ClaimConfirmed); err != nil {
+		log.Error().Err(err).Str("holder", holder).Msg("repository::ConfirmClaims - Failed to check claims")
+		return err
+	}
+	if lapsed {
+		return errmsg.NewCustomErrors(409, errmsg.WithMessage("Kuota flash sale sudah tidak aktif"))
+	}
+
+	return nil
+}
+
+// ReleaseClaims gives back the quota of every live claim of holder.
+func (r *flashSaleRepository) ReleaseClaims(ctx context.Context, holder string) error {
+	query := `
+		WITH released AS (
+			UPDATE flash_sale_claims SET status = ?, updated_at = NOW()
+			WHERE holder = ? AND status = ?
+			RETURNING flash_sale_item_id, quantity
+		)
+		UPDATE flash_sale_items SET claimed = claimed - released.quantity
+		FROM (SELECT flash_sale_item_id, SUM(quantity) AS quantity FROM released GROUP BY flash_sale_item_id) released
+		WHERE flash_sale_items.id = released.flash_sale_item_id
+	`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), entity.ClaimReleased, holder, entity.ClaimActive); err != nil {
+		log.Error().Err(err).Str("holder", holder).Msg("repository::ReleaseClaims - Failed to release claims")
+		return err
+	}
+
+	return nil
+}
+
+// ExpireClaims gives back the quota of the claims whose TTL has passed. Unlike stock
+// holds they count until this runs, since the quota lives in a counter.
+func (r *flashSaleRepository) ExpireClaims(ctx context.Context) (int64, error) {
+	var n int64
+
+	query := `
+		WITH expired AS (
+			UPDATE flash_sale_claims SET status = ?, updated_at = NOW()
+			WHERE status = ? AND expires_at <= NOW()
+			RETURNING flash_sale_item_id, quantity
+		), counted AS (
+			UPDATE flash_sale_items SET claimed = claimed - expired.quantity
+			FROM (SELECT flash_sale_item_id, SUM(quantity) AS quantity FROM expired GROUP BY flash_sale_item_id) expired
+			WHERE flash_sale_items.id = expired.flash_sale_item_id
+		)
+		SELECT COUNT(*) FROM expired
+	`
+	if err := r.db.GetContext(ctx, &n, r.db.Rebind(query), entity.ClaimExpired, entity.ClaimActive); err != nil {
+		log.Error().Err(err).Msg("repository::ExpireClaims - Failed to expire claims")
+		return 0, err
+	}
+
+	return n, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"codebase-app/internal/module/flashsale/entity"
+	"codebase-app/internal/module/flashsale/ports"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.FlashSaleService = &flashSaleService{}
+
+type flashSaleService struct {
+	repo ports.FlashSaleRepository
+	shop ports.ShopRepository
+}
+
+func NewFlashSaleService(repo ports.FlashSaleRepository, shop ports.ShopRepository) *flashSaleService {
+	return &flashSaleService{
+		repo: repo,
+		shop: shop,
+	}
+}
+
+func (s *flashSaleService) CreateFlashSale(ctx context.Context, req *entity.CreateFlashSaleRequest) (*entity.FlashSale, error) {
+	if err := checkFlashSale(&req.FlashSaleRequest); err != nil {
+		return nil, err
+	}
+
+	if err := s.shop.CheckShopOwner(ctx, req.ShopID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateFlashSale(ctx, req)
+}
+
+func (s *flashSaleService) UpdateFlashSale(ctx context.Context, req *entity.UpdateFlashSaleRequest) (*entity.FlashSale, error) {
+	if err := checkFlashSale(&req.FlashSaleRequest); err != nil {
+		return nil, err
+	}
+
+	if err := s.shop.CheckShopOwner(ctx, req.ShopID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateFlashSale(ctx, req)
+}
+
+func (s *flashSaleService) DeleteFlashSale(ctx context.Context, req *entity.DeleteFlashSaleRequest) error {
+	if err := s.shop.CheckShopOwner(ctx, req.ShopID, req.UserID); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteFlashSale(ctx, req)
+}
+
+// GetFlashSales lists the sales of a shop for its owner, or the public listing of
+// running and upcoming sales when no shop is given.
+func (s *flashSaleService) GetFlashSales(ctx context.Context, req *entity.FlashSalesRequest) (*entity.FlashSalesResponse, error) {
+	if req.ShopID != "" {
+		if err := s.shop.CheckShopOwner(ctx, req.ShopID, req.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.GetFlashSales(ctx, req)
+}
+
+func (s *flashSaleService) ExpireClaims(ctx context.Context) error {
+	n, err := s.repo.ExpireClaims(ctx)
+	if err != nil {
+		return err
+	}
+
+	if n > 0 {
+		log.Info().Int64("count", n).Msg("service::ExpireClaims - Expired stale flash sale claims")
+	}
+
+	return nil
+}
+
+// checkFlashSale validates what the tags of a flash sale cannot express on their own.
+func checkFlashSale(req *entity.FlashSaleRequest) error {
+	if !req.EndsAt.After(req.StartsAt) {
+		return errmsg.NewCustomErrors(400, errmsg.WithErrors("ends_at", "harus setelah starts_at."))
+	}
+
+	if !req.EndsAt.After(time.Now()) {
+		return errmsg.NewCustomErrors(400, errmsg.WithErrors("ends_at", "harus di masa depan."))
+	}
+
+	seen := make(map[string]bool, len(req.Items))
+	for i, item := range req.Items {
+		if seen[item.ProductID] {
+			return errmsg.NewCustomErrors(400, errmsg.WithErrors(fmt.Sprintf("items[%d].product_id", i), "produk sudah ada di flash sale."))
+		}
+		seen[item.ProductID] = true
+	}
+
+	return nil
+}
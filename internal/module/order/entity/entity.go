@@ -0,0 +1,143 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+type OrderItemRequest struct {
+	ProductID string `json:"product_id" validate:"required,uuid"`
+	VariantID string `json:"variant_id" validate:"omitempty,uuid"`
+	Quantity  int    `json:"quantity" validate:"required,min=1"`
+}
+
+// CreateOrderRequest orders items from a single shop. Prices are taken from the
+// catalog and the stock is held for the order until it is paid or cancelled. The
+// vouchers of VoucherCodes, and the promotions that apply on their own, are redeemed
+// on the order.
+type CreateOrderRequest struct {
+	UserID       string             `prop:"user_id" validate:"uuid"`
+	Items        []OrderItemRequest `json:"items" validate:"required,min=1,max=50,dive"`
+	VoucherCodes []string           `json:"voucher_codes" validate:"max=5,dive,required,max=32"`
+	Note         string             `json:"note" validate:"max=500"`
+}
+
+// OrdersRequest lists the orders of a buyer, newest first.
+type OrdersRequest struct {
+	UserID   string `prop:"user_id" validate:"uuid"`
+	Status   string `query:"status" validate:"omitempty,oneof=pending paid processing shipped completed cancelled refunded"`
+	Page     int    `query:"page" validate:"required"`
+	Paginate int    `query:"paginate" validate:"required,max=100"`
+}
+
+func (r *OrdersRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+// ShopOrdersRequest lists the orders placed at a shop, newest first; only its owner
+// may read them.
+type ShopOrdersRequest struct {
+	UserID   string `prop:"user_id" validate:"uuid"`
+	ShopID   string `params:"id" validate:"uuid"`
+	Status   string `query:"status" validate:"omitempty,oneof=pending paid processing shipped completed cancelled refunded"`
+	Page     int    `query:"page" validate:"required"`
+	Paginate int    `query:"paginate" validate:"required,max=100"`
+}
+
+func (r *ShopOrdersRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type OrderRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	Id     string `params:"id" validate:"uuid"`
+}
+
+type UpdateOrderStatusRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	Id     string `params:"id" validate:"uuid"`
+	Status string `json:"status" validate:"required,oneof=paid processing shipped completed cancelled refunded"`
+	Note   string `json:"note" validate:"max=500"`
+}
+
+// Order is a purchase from one shop. Number is its public reference and also holds
+// the stock of its items while the order waits for payment until ExpiresAt. Total is
+// what is left to pay once Discount, the sum of the Vouchers, is taken off Subtotal.
+type Order struct {
+	ID        string        `json:"id" db:"id"`
+	Number    string        `json:"number" db:"number"`
+	BuyerID   string        `json:"buyer_id" db:"buyer_id"`
+	ShopID    string        `json:"shop_id" db:"shop_id"`
+	ShopName  string        `json:"shop_name" db:"shop_name"`
+	SellerID  string        `json:"-" db:"seller_id"`
+	Status    string        `json:"status" db:"status"`
+	Subtotal  types.Money   `json:"subtotal" db:"subtotal"`
+	Discount  types.Money   `json:"discount" db:"discount"`
+	Total     types.Money   `json:"total" db:"total"`
+	Vouchers  OrderVouchers `json:"vouchers" db:"vouchers"`
+	Note      *string       `json:"note" db:"note"`
+	ExpiresAt time.Time     `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at" db:"updated_at"`
+
+	Items   []OrderItem         `json:"items" db:"-"`
+	History []OrderStatusChange `json:"history,omitempty" db:"-"`
+}
+
+// OrderItem is a product line of an order as it was when the order was placed.
+type OrderItem struct {
+	ID            string      `json:"id" db:"id"`
+	OrderID       string      `json:"-" db:"order_id"`
+	ProductID     *string     `json:"product_id" db:"product_id"`
+	VariantID     *string     `json:"variant_id" db:"variant_id"`
+	ProductName   string      `json:"product_name" db:"product_name"`
+	VariantCode   *string     `json:"variant_code" db:"variant_code"`
+	Harga         types.Money `json:"harga" db:"harga"`
+	Quantity      int         `json:"quantity" db:"quantity"`
+	Subtotal      types.Money `json:"subtotal" db:"-"`
+	ReservationID *string     `json:"-" db:"reservation_id"`
+
+	// FlashSaleItemID is set when the item was sold at a flash price.
+	FlashSaleItemID *string `json:"flash_sale_item_id" db:"flash_sale_item_id"`
+}
+
+// OrderItemSnapshot is the catalog state of a requested item when the order is placed.
+type OrderItemSnapshot struct {
+	ProductID   string      `db:"product_id"`
+	VariantID   *string     `db:"variant_id"`
+	ShopID      string      `db:"shop_id"`
+	SellerID    string      `db:"seller_id"`
+	ProductName string      `db:"product_name"`
+	VariantCode *string     `db:"variant_code"`
+	Harga       types.Money `db:"harga"`
+	HasVariants bool        `db:"has_variants"`
+	ShopOpen    bool        `db:"shop_open"`
+
+	// FlashSaleItemID is set when Harga is a flash price whose quota must be claimed.
+	FlashSaleItemID *string `db:"flash_sale_item_id"`
+}
+
+type OrderStatusChange struct {
+	FromStatus *string   `json:"from_status" db:"from_status"`
+	ToStatus   string    `json:"to_status" db:"to_status"`
+	Actor      *string   `json:"actor" db:"actor"`
+	Note       *string   `json:"note" db:"note"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type OrdersResponse struct {
+	Items []Order    `json:"items"`
+	Meta  types.Meta `json:"meta"`
+}
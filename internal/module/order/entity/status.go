@@ -0,0 +1,51 @@
+package entity
+
+import "slices"
+
+// Statuses of an order.
+const (
+	OrderPending    = "pending"
+	OrderPaid       = "paid"
+	OrderProcessing = "processing"
+	OrderShipped    = "shipped"
+	OrderCompleted  = "completed"
+	OrderCancelled  = "cancelled"
+	OrderRefunded   = "refunded"
+)
+
+// Parties of an order allowed to move it along.
+const (
+	OrderActorBuyer  = "buyer"
+	OrderActorSeller = "seller"
+)
+
+// orderTransitions lists, for every status, the statuses an order may move to next
+// and who may move it there. Completed, cancelled and refunded orders are final.
+var orderTransitions = map[string]map[string][]string{
+	OrderPending: {
+		OrderPaid:      {OrderActorBuyer},
+		OrderCancelled: {OrderActorBuyer, OrderActorSeller},
+	},
+	OrderPaid: {
+		OrderProcessing: {OrderActorSeller},
+		OrderRefunded:   {OrderActorSeller},
+	},
+	OrderProcessing: {
+		OrderShipped:  {OrderActorSeller},
+		OrderRefunded: {OrderActorSeller},
+	},
+	OrderShipped: {
+		OrderCompleted: {OrderActorBuyer},
+	},
+}
+
+// CanTransition reports whether an order in status from may move to status to.
+func CanTransition(from, to string) bool {
+	_, ok := orderTransitions[from][to]
+	return ok
+}
+
+// CanTransitionAs reports whether actor may move an order from status from to status to.
+func CanTransitionAs(from, to, actor string) bool {
+	return slices.Contains(orderTransitions[from][to], actor)
+}
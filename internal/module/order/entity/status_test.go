@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransition(t *testing.T) {
+	valid := [][2]string{
+		{OrderPending, OrderPaid},
+		{OrderPending, OrderCancelled},
+		{OrderPaid, OrderProcessing},
+		{OrderPaid, OrderRefunded},
+		{OrderProcessing, OrderShipped},
+		{OrderProcessing, OrderRefunded},
+		{OrderShipped, OrderCompleted},
+	}
+	for _, tt := range valid {
+		assert.True(t, CanTransition(tt[0], tt[1]), "%s -> %s", tt[0], tt[1])
+	}
+
+	invalid := [][2]string{
+		{OrderPending, OrderShipped},
+		{OrderPending, OrderRefunded},
+		{OrderPaid, OrderCancelled},
+		{OrderPaid, OrderPending},
+		{OrderShipped, OrderRefunded},
+		{OrderCompleted, OrderRefunded},
+		{OrderCancelled, OrderPaid},
+		{OrderRefunded, OrderPending},
+		{"unknown", OrderPaid},
+	}
+	for _, tt := range invalid {
+		assert.False(t, CanTransition(tt[0], tt[1]), "%s -> %s", tt[0], tt[1])
+	}
+}
+
+func TestCanTransitionAs(t *testing.T) {
+	tests := []struct {
+		from, to, actor string
+		want            bool
+	}{
+		{OrderPending, OrderPaid, OrderActorBuyer, true},
+		{OrderPending, OrderPaid, OrderActorSeller, false},
+		{OrderPending, OrderCancelled, OrderActorBuyer, true},
+		{OrderPending, OrderCancelled, OrderActorSeller, true},
+		{OrderPaid, OrderProcessing, OrderActorBuyer, false},
+		{OrderPaid, OrderProcessing, OrderActorSeller, true},
+		{OrderShipped, OrderCompleted, OrderActorBuyer, true},
+		{OrderShipped, OrderCompleted, OrderActorSeller, false},
+		{OrderCompleted, OrderRefunded, OrderActorSeller, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, CanTransitionAs(tt.from, tt.to, tt.actor), "%s -> %s as %s", tt.from, tt.to, tt.actor)
+	}
+}
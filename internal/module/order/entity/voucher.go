@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// OrderVoucher is a voucher an order was placed with and what it took off the order.
+type OrderVoucher struct {
+	VoucherID string      `json:"voucher_id"`
+	Code      *string     `json:"code"`
+	Name      string      `json:"name"`
+	Discount  types.Money `json:"discount"`
+}
+
+// OrderVouchers is stored as jsonb in orders.vouchers.
+type OrderVouchers []OrderVoucher
+
+// Scan implements the sql.Scanner interface.
+func (o *OrderVouchers) Scan(val any) error {
+	switch v := val.(type) {
+	case nil:
+		*o = OrderVouchers{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, o)
+	case string:
+		return json.Unmarshal([]byte(v), o)
+	default:
+		return errors.New("entity: unsupported type for OrderVouchers")
+	}
+}
+
+// Value implements the driver.Valuer interface.
+func (o OrderVouchers) Value() (driver.Value, error) {
+	if o == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(o)
+}
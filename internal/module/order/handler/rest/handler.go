@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/middleware"
+	flashSaleRepository "codebase-app/internal/module/flashsale/repository"
+	"codebase-app/internal/module/order/entity"
+	"codebase-app/internal/module/order/ports"
+	"codebase-app/internal/module/order/repository"
+	"codebase-app/internal/module/order/service"
+	promotionRepository "codebase-app/internal/module/promotion/repository"
+	promotionService "codebase-app/internal/module/promotion/service"
+	shopRepository "codebase-app/internal/module/shop/repository"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type orderHandler struct {
+	service ports.OrderService
+}
+
+func NewOrderHandler() *orderHandler {
+	var (
+		handler   = new(orderHandler)
+		repo      = repository.NewOrderRepository(adapter.Adapters.ShopeefunPostgres)
+		stock     = shopRepository.NewShopRepository(adapter.Adapters.ShopeefunPostgres)
+		flashSale = flashSaleRepository.NewFlashSaleRepository(adapter.Adapters.ShopeefunPostgres)
+		promotion = promotionService.NewPromotionService(promotionRepository.NewPromotionRepository(adapter.Adapters.ShopeefunPostgres), stock)
+		service   = service.NewOrderService(repo, stock, flashSale, promotion)
+	)
+	handler.service = service
+
+	return handler
+}
+
+func (h *orderHandler) Register(router fiber.Router) {
+	router.Post("/orders", middleware.UserIdHeader, h.CreateOrder)
+	router.Get("/orders", middleware.UserIdHeader, h.GetOrders)
+	router.Get("/orders/:id", middleware.UserIdHeader, h.GetOrder)
+	router.Patch("/orders/:id/status", middleware.UserIdHeader, h.UpdateOrderStatus)
+	router.Get("/shops/:id/orders", middleware.UserIdHeader, h.GetShopOrders)
+}
+
+func (h *orderHandler) CreateOrder(c *fiber.Ctx) error {
+	var (
+		req = new(entity.CreateOrderRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::CreateOrder - Parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserID = l.UserId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::CreateOrder - Validate request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.CreateOrder(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(resp, ""))
+}
+
+func (h *orderHandler) GetOrders(c *fiber.Ctx) error {
+	var (
+		req = new(entity.OrdersRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetOrders - Parse request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserID = l.UserId
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetOrders - Validate request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetOrders(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
+
+func (h *orderHandler) GetOrder(c *fiber.Ctx) error {
+	var (
+		req = new(entity.OrderRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserID = l.UserId
+	req.Id = c.Params("id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetOrder - Validate request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetOrder(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
+
+func (h *orderHandler) UpdateOrderStatus(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdateOrderStatusRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateOrderStatus - Parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserID = l.UserId
+	req.Id = c.Params("id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::UpdateOrderStatus - Validate request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.UpdateOrderStatus(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
+
+func (h *orderHandler) GetShopOrders(c *fiber.Ctx) error {
+	var (
+		req = new(entity.ShopOrdersRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetShopOrders - Parse request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserID = l.UserId
+	req.ShopID = c.Params("id")
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetShopOrders - Validate request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetShopOrders(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
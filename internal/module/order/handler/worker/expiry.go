@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"codebase-app/internal/adapter"
+	flashSaleRepository "codebase-app/internal/module/flashsale/repository"
+	"codebase-app/internal/module/order/ports"
+	"codebase-app/internal/module/order/repository"
+	"codebase-app/internal/module/order/service"
+	promotionRepository "codebase-app/internal/module/promotion/repository"
+	promotionService "codebase-app/internal/module/promotion/service"
+	shopRepository "codebase-app/internal/module/shop/repository"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// orderSweepInterval is how often unpaid orders past their payment window are cancelled.
+const orderSweepInterval = time.Minute
+
+type orderSweeper struct {
+	service ports.OrderService
+}
+
+// NewOrderSweeper builds the background job cancelling pending orders past their payment window.
+func NewOrderSweeper() *orderSweeper {
+	var (
+		worker    = new(orderSweeper)
+		repo      = repository.NewOrderRepository(adapter.Adapters.ShopeefunPostgres)
+		stock     = shopRepository.NewShopRepository(adapter.Adapters.ShopeefunPostgres)
+		flashSale = flashSaleRepository.NewFlashSaleRepository(adapter.Adapters.ShopeefunPostgres)
+		promotion = promotionService.NewPromotionService(promotionRepository.NewPromotionRepository(adapter.Adapters.ShopeefunPostgres), stock)
+		service   = service.NewOrderService(repo, stock, flashSale, promotion)
+	)
+	worker.service = service
+
+	return worker
+}
+
+// Run sweeps until ctx is cancelled.
+func (w *orderSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(orderSweepInterval)
+	defer ticker.Stop()
+
+	log.Info().Msg("worker::OrderSweeper - Started")
+
+	for {
+		if err := w.service.ExpireOrders(ctx); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("worker::OrderSweeper - Failed to expire orders")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("worker::OrderSweeper - Stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package ports
+
+import (
+	flashSaleEntity "codebase-app/internal/module/flashsale/entity"
+	"codebase-app/internal/module/order/entity"
+	promotionEntity "codebase-app/internal/module/promotion/entity"
+	shopEntity "codebase-app/internal/module/shop/entity"
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type OrderRepository interface {
+	GetItemSnapshot(ctx context.Context, item *entity.OrderItemRequest) (*entity.OrderItemSnapshot, error)
+	CreateOrder(ctx context.Context, order *entity.Order, ttl time.Duration) (*entity.Order, error)
+	GetOrder(ctx context.Context, id string) (*entity.Order, error)
+	GetOrders(ctx context.Context, req *entity.OrdersRequest) (*entity.OrdersResponse, error)
+	GetShopOrders(ctx context.Context, req *entity.ShopOrdersRequest) (*entity.OrdersResponse, error)
+	UpdateOrderStatus(ctx context.Context, order *entity.Order, req *entity.UpdateOrderStatusRequest, settle func(tx *sqlx.Tx) error) (*entity.Order, error)
+	GetExpiredOrders(ctx context.Context, limit int) ([]entity.Order, error)
+}
+
+// StockRepository is the part of the shop module an order relies on: its stock holds
+// and the ownership of shops. Holds are confirmed in the transaction of the status
+// change that sells them.
+type StockRepository interface {
+	ReserveStock(ctx context.Context, req *shopEntity.ReserveStockRequest) (*shopEntity.StockReservation, error)
+	ConfirmHolds(ctx context.Context, tx *sqlx.Tx, holder, actor string) ([]shopEntity.StockReservation, error)
+	ReturnHolds(ctx context.Context, tx *sqlx.Tx, holder, actor string) ([]shopEntity.StockReservation, error)
+	ReleaseHolds(ctx context.Context, holder string) error
+	CheckShopOwner(ctx context.Context, shopID, userID string) error
+}
+
+// FlashSaleRepository is the part of the flash sale module an order relies on: the
+// quota of the items it sells at a flash price, claimed under the order number.
+type FlashSaleRepository interface {
+	ClaimQuota(ctx context.Context, req *flashSaleEntity.ClaimQuotaRequest) error
+	ConfirmClaims(ctx context.Context, tx *sqlx.Tx, holder string) error
+	ReleaseClaims(ctx context.Context, holder string) error
+}
+
+// PromotionService is the part of the promotion module an order relies on: pricing
+// its vouchers and spending them under the order number. A refund gives them back in
+// the transaction of the status change.
+type PromotionService interface {
+	Apply(ctx context.Context, userID string, lines []promotionEntity.BasketLine, codes []string) (*promotionEntity.Evaluation, error)
+	Redeem(ctx context.Context, userID, reference string, applied []promotionEntity.AppliedVoucher) error
+	ReleaseRedemptions(ctx context.Context, reference string) error
+	RefundRedemptions(ctx context.Context, tx *sqlx.Tx, reference string) error
+}
+
+type OrderService interface {
+	CreateOrder(ctx context.Context, req *entity.CreateOrderRequest) (*entity.Order, error)
+	GetOrder(ctx context.Context, req *entity.OrderRequest) (*entity.Order, error)
+	GetOrders(ctx context.Context, req *entity.OrdersRequest) (*entity.OrdersResponse, error)
+	GetShopOrders(ctx context.Context, req *entity.ShopOrdersRequest) (*entity.OrdersResponse, error)
+	UpdateOrderStatus(ctx context.Context, req *entity.UpdateOrderStatusRequest) (*entity.Order, error)
+	ExpireOrders(ctx context.Context) error
+}
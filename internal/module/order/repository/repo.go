@@ -0,0 +1,361 @@
+package repository
+
+import (
+	"codebase-app/internal/module/order/entity"
+	"codebase-app/internal/module/order/ports"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/types"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.OrderRepository = &orderRepository{}
+
+type orderRepository struct {
+	db *sqlx.DB
+}
+
+func NewOrderRepository(db *sqlx.DB) *orderRepository {
+	return &orderRepository{
+		db: db,
+	}
+}
+
+const orderColumns = `
+	orders.id, orders.number, orders.buyer_id, orders.shop_id, shops.name AS shop_name, shops.user_id AS seller_id,
+	orders.status, ROW(orders.subtotal, orders.currency) AS subtotal, ROW(orders.discount, orders.currency) AS discount,
+	ROW(orders.total, orders.currency) AS total, orders.vouchers, orders.note, orders.expires_at,
+	orders.created_at, orders.updated_at`
+
+// GetItemSnapshot reads the product, and SKU when one is asked for, an order item is
+// about to be sold from. While a product without SKUs is in a flash sale with quota left
+// it is priced at the flash price; a SKU always sells at its own price.
+func (r *orderRepository) GetItemSnapshot(ctx context.Context, item *entity.OrderItemRequest) (*entity.OrderItemSnapshot, error) {
+	var resp = new(entity.OrderItemSnapshot)
+
+	query := `
+		SELECT
+			product.id AS product_id,
+			product_variants.id AS variant_id,
+			product.shop_id,
+			shops.user_id AS seller_id,
+			product.name AS product_name,
+			product_variants.code AS variant_code,
+			ROW(COALESCE(flash.harga, product_variants.harga, product.harga), COALESCE(product_variants.currency, product.currency)) AS harga,
+			flash.id AS flash_sale_item_id,
+			EXISTS (
+				SELECT 1 FROM product_variants
+				WHERE product_variants.product_id = product.id AND product_variants.deleted_at IS NULL
+			) AS has_variants,
+			shop_is_open(shops.status, shops.vacation_until, shops.opening_hours, shops.timezone, NOW()) AS shop_open
+		FROM product
+		JOIN shops ON shops.id = product.shop_id AND shops.deleted_at IS NULL
+		LEFT JOIN product_variants
+			ON product_variants.product_id = product.id
+			AND product_variants.id = CAST(NULLIF(?, '') AS uuid)
+			AND product_variants.deleted_at IS NULL
+		-- flash sales only price products without SKUs
+		LEFT JOIN active_flash_sale_items flash
+			ON flash.product_id = product.id AND flash.remaining > 0 AND product_variants.id IS NULL
+		WHERE product.id = ? AND product.deleted_at IS NULL AND product.status = 'published'
+	`
+	err := r.db.GetContext(ctx, resp, r.db.Rebind(query), item.VariantID, item.ProductID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Produk tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Any("payload", item).Msg("repository::GetItemSnapshot - Failed to get product")
+		return nil, err
+	}
+
+	if item.VariantID != "" && resp.VariantID == nil {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Varian produk tidak ditemukan"))
+	}
+
+	return resp, nil
+}
+
+// CreateOrder saves a pending order with its items. The order can be paid during ttl.
+func (r *orderRepository) CreateOrder(ctx context.Context, order *entity.Order, ttl time.Duration) (*entity.Order, error) {
+	var id string
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Str("number", order.Number).Msg("repository::CreateOrder - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO orders (number, buyer_id, shop_id, status, subtotal, discount, total, currency, vouchers, note, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW() + CAST(? AS interval))
+		RETURNING id
+	`
+	err = tx.GetContext(ctx, &id, tx.Rebind(query),
+		order.Number,
+		order.BuyerID,
+		order.ShopID,
+		entity.OrderPending,
+		order.Subtotal.Amount,
+		order.Discount.Amount,
+		order.Total.Amount,
+		order.Total.CurrencyCode(),
+		order.Vouchers,
+		order.Note,
+		fmt.Sprintf("%d seconds", int(ttl.Seconds())),
+	)
+	if err != nil {
+		log.Error().Err(err).Str("number", order.Number).Msg("repository::CreateOrder - Failed to insert order")
+		return nil, err
+	}
+
+	queryItem := `
+		INSERT INTO order_items (order_id, product_id, variant_id, product_name, variant_code, harga, quantity, reservation_id, flash_sale_item_id, position)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	for i, item := range order.Items {
+		_, err := tx.ExecContext(ctx, tx.Rebind(queryItem),
+			id,
+			item.ProductID,
+			item.VariantID,
+			item.ProductName,
+			item.VariantCode,
+			item.Harga.Amount,
+			item.Quantity,
+			item.ReservationID,
+			item.FlashSaleItemID,
+			i,
+		)
+		if err != nil {
+			log.Error().Err(err).Str("number", order.Number).Msg("repository::CreateOrder - Failed to insert order item")
+			return nil, err
+		}
+	}
+
+	if err := addStatusChange(ctx, tx, id, nil, entity.OrderPending, order.BuyerID, ""); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Str("number", order.Number).Msg("repository::CreateOrder - Failed to commit transaction")
+		return nil, err
+	}
+
+	return r.GetOrder(ctx, id)
+}
+
+// GetOrder loads an order with its items and status history.
+func (r *orderRepository) GetOrder(ctx context.Context, id string) (*entity.Order, error) {
+	var resp = new(entity.Order)
+
+	query := `SELECT ` + orderColumns + ` FROM orders JOIN shops ON shops.id = orders.shop_id WHERE orders.id = ?`
+	err := r.db.GetContext(ctx, resp, r.db.Rebind(query), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Pesanan tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("repository::GetOrder - Failed to get order")
+		return nil, err
+	}
+
+	items, err := r.getItems(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	resp.Items = items[id]
+
+	queryHistory := `
+		SELECT from_status, to_status, actor, note, created_at
+		FROM order_status_history
+		WHERE order_id = ?
+		ORDER BY created_at, id
+	`
+	if err := r.db.SelectContext(ctx, &resp.History, r.db.Rebind(queryHistory), id); err != nil {
+		log.Error().Err(err).Str("id", id).Msg("repository::GetOrder - Failed to get status history")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (r *orderRepository) GetOrders(ctx context.Context, req *entity.OrdersRequest) (*entity.OrdersResponse, error) {
+	return r.listOrders(ctx, "orders.buyer_id = ?", req.UserID, req.Status, req.Page, req.Paginate)
+}
+
+func (r *orderRepository) GetShopOrders(ctx context.Context, req *entity.ShopOrdersRequest) (*entity.OrdersResponse, error) {
+	return r.listOrders(ctx, "orders.shop_id = ?", req.ShopID, req.Status, req.Page, req.Paginate)
+}
+
+// listOrders pages through the orders matching cond, which binds owner, newest first.
+func (r *orderRepository) listOrders(ctx context.Context, cond, owner, status string, page, paginate int) (*entity.OrdersResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.Order
+	}
+
+	var (
+		data []dao
+		resp = &entity.OrdersResponse{Items: make([]entity.Order, 0, paginate)}
+	)
+
+	query := `
+		SELECT COUNT(orders.id) OVER() AS total_data, ` + orderColumns + `
+		FROM orders
+		JOIN shops ON shops.id = orders.shop_id
+		WHERE ` + cond + ` AND (? = '' OR orders.status = ?)
+		ORDER BY orders.created_at DESC, orders.id DESC
+		LIMIT ? OFFSET ?
+	`
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query),
+		owner,
+		status, status,
+		paginate, paginate*(page-1),
+	)
+	if err != nil {
+		log.Error().Err(err).Str("owner", owner).Str("status", status).Msg("repository::listOrders - Failed to get orders")
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(data))
+	for _, d := range data {
+		ids = append(ids, d.ID)
+	}
+
+	items, err := r.getItems(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range data {
+		d.Order.Items = items[d.ID]
+		resp.Items = append(resp.Items, d.Order)
+	}
+
+	if len(data) > 0 {
+		resp.Meta.TotalData = data[0].TotalData
+	}
+	resp.Meta.CountTotalPage(page, paginate, resp.Meta.TotalData)
+
+	return resp, nil
+}
+
+// getItems loads the items of orders keyed by order id, in the order they were placed.
+func (r *orderRepository) getItems(ctx context.Context, orderIDs []string) (map[string][]entity.OrderItem, error) {
+	var (
+		data []entity.OrderItem
+		resp = make(map[string][]entity.OrderItem, len(orderIDs))
+	)
+
+	if len(orderIDs) == 0 {
+		return resp, nil
+	}
+
+	query := `
+		SELECT
+			order_items.id, order_items.order_id, order_items.product_id, order_items.variant_id,
+			order_items.product_name, order_items.variant_code,
+			ROW(order_items.harga, orders.currency) AS harga,
+			order_items.quantity, order_items.reservation_id, order_items.flash_sale_item_id
+		FROM order_items
+		JOIN orders ON orders.id = order_items.order_id
+		WHERE order_items.order_id = ANY(?)
+		ORDER BY order_items.position
+	`
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), pq.Array(orderIDs)); err != nil {
+		log.Error().Err(err).Any("payload", orderIDs).Msg("repository::getItems - Failed to get order items")
+		return nil, err
+	}
+
+	for _, item := range data {
+		item.Subtotal = types.NewMoney(item.Harga.Amount*int64(item.Quantity), item.Harga.Currency)
+		resp[item.OrderID] = append(resp[item.OrderID], item)
+	}
+
+	return resp, nil
+}
+
+// UpdateOrderStatus moves order to req.Status and records the change. It fails when
+// the order left the status it was read in meanwhile. settle, when set, runs in the
+// same transaction once the order is won, so what the change does to stock and quota
+// is kept only together with the new status.
+func (r *orderRepository) UpdateOrderStatus(ctx context.Context, order *entity.Order, req *entity.UpdateOrderStatusRequest, settle func(tx *sqlx.Tx) error) (*entity.Order, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateOrderStatus - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE orders SET status = ?, updated_at = NOW() WHERE id = ? AND status = ?`
+	res, err := tx.ExecContext(ctx, tx.Rebind(query), req.Status, order.ID, order.Status)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateOrderStatus - Failed to update order")
+		return nil, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateOrderStatus - Failed to read affected rows")
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errmsg.NewCustomErrors(409, errmsg.WithMessage("Status pesanan sudah berubah"))
+	}
+
+	if settle != nil {
+		if err := settle(tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := addStatusChange(ctx, tx, order.ID, &order.Status, req.Status, req.UserID, req.Note); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateOrderStatus - Failed to commit transaction")
+		return nil, err
+	}
+
+	return r.GetOrder(ctx, order.ID)
+}
+
+// GetExpiredOrders returns up to limit pending orders whose payment window has passed,
+// oldest first. Only the fields needed to cancel them are loaded.
+func (r *orderRepository) GetExpiredOrders(ctx context.Context, limit int) ([]entity.Order, error) {
+	var resp []entity.Order
+
+	query := `
+		SELECT id, number, status
+		FROM orders
+		WHERE status = ? AND expires_at <= NOW()
+		ORDER BY expires_at, id
+		LIMIT ?
+	`
+	if err := r.db.SelectContext(ctx, &resp, r.db.Rebind(query), entity.OrderPending, limit); err != nil {
+		log.Error().Err(err).Msg("repository::GetExpiredOrders - Failed to get expired orders")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func addStatusChange(ctx context.Context, tx *sqlx.Tx, orderID string, from *string, to, actor, note string) error {
+	query := `
+		INSERT INTO order_status_history (order_id, from_status, to_status, actor, note)
+		VALUES (?, ?, ?, CAST(NULLIF(?, '') AS uuid), NULLIF(?, ''))
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), orderID, from, to, actor, note); err != nil {
+		log.Error().Err(err).Str("order_id", orderID).Str("to", to).Msg("repository::addStatusChange - Failed to record status change")
+		return err
+	}
+
+	return nil
+}
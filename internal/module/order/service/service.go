@@ -0,0 +1,412 @@
+package service
+
+import (
+	flashSaleEntity "codebase-app/internal/module/flashsale/entity"
+	"codebase-app/internal/module/order/entity"
+	"codebase-app/internal/module/order/ports"
+	promotionEntity "codebase-app/internal/module/promotion/entity"
+	shopEntity "codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/types"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// paymentWindow is how long a new order holds its stock while waiting to be paid.
+const paymentWindow = time.Hour
+
+// expireBatch is how many unpaid orders one sweep cancels at most.
+const expireBatch = 100
+
+var _ ports.OrderService = &orderService{}
+
+type orderService struct {
+	repo      ports.OrderRepository
+	stock     ports.StockRepository
+	flashSale ports.FlashSaleRepository
+	promotion ports.PromotionService
+}
+
+func NewOrderService(repo ports.OrderRepository, stock ports.StockRepository, flashSale ports.FlashSaleRepository, promotion ports.PromotionService) *orderService {
+	return &orderService{
+		repo:      repo,
+		stock:     stock,
+		flashSale: flashSale,
+		promotion: promotion,
+	}
+}
+
+// CreateOrder places a pending order. Items are priced from the catalog and their
+// stock is held under the order number, so it cannot be sold twice while the buyer pays.
+// Flash sale quota and vouchers are spent under the same number and come back if the
+// order is cancelled.
+func (s *orderService) CreateOrder(ctx context.Context, req *entity.CreateOrderRequest) (*entity.Order, error) {
+	var (
+		order = &entity.Order{
+			Number:  "ORD-" + ulid.Make().String(),
+			BuyerID: req.UserID,
+			Items:   make([]entity.OrderItem, 0, len(req.Items)),
+		}
+		seen = make(map[string]bool, len(req.Items))
+	)
+
+	if note := strings.TrimSpace(req.Note); note != "" {
+		order.Note = &note
+	}
+
+	for i := range req.Items {
+		item := &req.Items[i]
+		field := fmt.Sprintf("items[%d]", i)
+
+		// a second hold for the same stock under one order would replace the first
+		key := item.ProductID + "/" + item.VariantID
+		if seen[key] {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors(field+".product_id", "produk sudah ada di pesanan."))
+		}
+		seen[key] = true
+
+		snapshot, err := s.repo.GetItemSnapshot(ctx, item)
+		if err != nil {
+			return nil, itemError(err, field+".product_id")
+		}
+
+		if snapshot.HasVariants && snapshot.VariantID == nil {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors(field+".variant_id", "varian harus dipilih."))
+		}
+
+		if !snapshot.ShopOpen {
+			return nil, errmsg.NewCustomErrors(409, errmsg.WithErrors(field+".product_id", "toko sedang tutup."))
+		}
+
+		if snapshot.SellerID == req.UserID {
+			return nil, errmsg.NewCustomErrors(403, errmsg.WithMessage("Tidak dapat membeli produk toko sendiri"))
+		}
+
+		if i == 0 {
+			order.ShopID = snapshot.ShopID
+			order.Total = types.NewMoney(0, snapshot.Harga.Currency)
+		}
+		if snapshot.ShopID != order.ShopID {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors(field+".product_id", "semua produk harus dari toko yang sama."))
+		}
+		if snapshot.Harga.CurrencyCode() != order.Total.CurrencyCode() {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors(field+".product_id", "mata uang produk harus sama."))
+		}
+
+		order.Items = append(order.Items, entity.OrderItem{
+			ProductID:   &snapshot.ProductID,
+			VariantID:   snapshot.VariantID,
+			ProductName: snapshot.ProductName,
+			VariantCode: snapshot.VariantCode,
+			Harga:       snapshot.Harga,
+			Quantity:    item.Quantity,
+
+			FlashSaleItemID: snapshot.FlashSaleItemID,
+		})
+		order.Total.Amount += snapshot.Harga.Amount * int64(item.Quantity)
+	}
+
+	if err := s.applyVouchers(ctx, order, req.VoucherCodes); err != nil {
+		return nil, err
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+
+		hold, err := s.stock.ReserveStock(ctx, &shopEntity.ReserveStockRequest{
+			ProductID: *item.ProductID,
+			VariantID: deref(item.VariantID),
+			Holder:    order.Number,
+			Quantity:  item.Quantity,
+			TTL:       int(paymentWindow.Seconds()),
+		})
+		if err != nil {
+			s.releaseHolds(ctx, order.Number)
+			return nil, itemError(err, fmt.Sprintf("items[%d].quantity", i))
+		}
+		item.ReservationID = &hold.ID
+	}
+
+	for i, item := range order.Items {
+		if item.FlashSaleItemID == nil {
+			continue
+		}
+
+		err := s.flashSale.ClaimQuota(ctx, &flashSaleEntity.ClaimQuotaRequest{
+			FlashSaleItemID: *item.FlashSaleItemID,
+			Holder:          order.Number,
+			Quantity:        item.Quantity,
+			TTL:             paymentWindow,
+		})
+		if err != nil {
+			s.releaseHolds(ctx, order.Number)
+			s.releaseClaims(ctx, order.Number)
+			return nil, itemError(err, fmt.Sprintf("items[%d].quantity", i))
+		}
+	}
+
+	applied := make([]promotionEntity.AppliedVoucher, 0, len(order.Vouchers))
+	for _, v := range order.Vouchers {
+		applied = append(applied, promotionEntity.AppliedVoucher(v))
+	}
+
+	if err := s.promotion.Redeem(ctx, order.BuyerID, order.Number, applied); err != nil {
+		s.releaseHolds(ctx, order.Number)
+		s.releaseClaims(ctx, order.Number)
+		return nil, err
+	}
+
+	resp, err := s.repo.CreateOrder(ctx, order, paymentWindow)
+	if err != nil {
+		s.releaseHolds(ctx, order.Number)
+		s.releaseClaims(ctx, order.Number)
+		s.releaseVouchers(ctx, order.Number)
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// applyVouchers prices order with its vouchers, turning its item total into the
+// subtotal. A code that cannot be used fails the order rather than being dropped; one
+// that only lost to a better promotion does not, the buyer gets the larger discount.
+func (s *orderService) applyVouchers(ctx context.Context, order *entity.Order, codes []string) error {
+	lines := make([]promotionEntity.BasketLine, 0, len(order.Items))
+	for _, item := range order.Items {
+		lines = append(lines, promotionEntity.BasketLine{
+			ProductID: *item.ProductID,
+			VariantID: item.VariantID,
+			ShopID:    order.ShopID,
+			Harga:     item.Harga,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	eval, err := s.promotion.Apply(ctx, order.BuyerID, lines, codes)
+	if err != nil {
+		return err
+	}
+
+	if len(eval.Rejected) > 0 {
+		opts := []errmsg.Option{errmsg.WithMessage("Voucher tidak dapat digunakan")}
+		for _, r := range eval.Rejected {
+			for i, code := range codes {
+				if strings.EqualFold(code, r.Code) {
+					opts = append(opts, errmsg.WithErrors(fmt.Sprintf("voucher_codes[%d]", i), r.Reason))
+				}
+			}
+		}
+
+		return errmsg.NewCustomErrors(400, opts...)
+	}
+
+	order.Subtotal = eval.Subtotal
+	order.Discount = eval.Discount
+	order.Total = eval.Total
+	order.Vouchers = make(entity.OrderVouchers, 0, len(eval.Applied))
+	for _, a := range eval.Applied {
+		order.Vouchers = append(order.Vouchers, entity.OrderVoucher(a))
+	}
+
+	return nil
+}
+
+func (s *orderService) GetOrder(ctx context.Context, req *entity.OrderRequest) (*entity.Order, error) {
+	order, err := s.repo.GetOrder(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := orderActor(order, req.UserID); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (s *orderService) GetOrders(ctx context.Context, req *entity.OrdersRequest) (*entity.OrdersResponse, error) {
+	return s.repo.GetOrders(ctx, req)
+}
+
+func (s *orderService) GetShopOrders(ctx context.Context, req *entity.ShopOrdersRequest) (*entity.OrdersResponse, error) {
+	if err := s.stock.CheckShopOwner(ctx, req.ShopID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetShopOrders(ctx, req)
+}
+
+// UpdateOrderStatus moves an order along its state machine. Paying takes the held stock
+// for good in the transaction of the status change, so a payment losing a race with a
+// cancel takes nothing; cancelling a pending order gives it back, and refunding a paid
+// one puts its stock back, takes it off the sales counters and gives back its vouchers
+// in the same transaction.
+func (s *orderService) UpdateOrderStatus(ctx context.Context, req *entity.UpdateOrderStatusRequest) (*entity.Order, error) {
+	order, err := s.repo.GetOrder(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	actor, err := orderActor(order, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !entity.CanTransition(order.Status, req.Status) {
+		log.Warn().Any("payload", req).Str("status", order.Status).Msg("service::UpdateOrderStatus - Illegal transition")
+		return nil, errmsg.NewCustomErrors(409,
+			errmsg.WithMessage("Status pesanan tidak dapat diubah"),
+			errmsg.WithErrors("status", fmt.Sprintf("pesanan %s tidak dapat menjadi %s.", order.Status, req.Status)),
+		)
+	}
+
+	if !entity.CanTransitionAs(order.Status, req.Status, actor) {
+		log.Warn().Any("payload", req).Str("actor", actor).Msg("service::UpdateOrderStatus - Transition not allowed for actor")
+		return nil, errmsg.NewCustomErrors(403, errmsg.WithMessage("Anda tidak dapat mengubah pesanan ke status ini"))
+	}
+
+	var settle func(tx *sqlx.Tx) error
+
+	if req.Status == entity.OrderPaid {
+		if time.Now().After(order.ExpiresAt) {
+			return nil, errmsg.NewCustomErrors(409, errmsg.WithMessage("Batas waktu pembayaran pesanan sudah habis"))
+		}
+
+		settle = func(tx *sqlx.Tx) error {
+			if _, err := s.stock.ConfirmHolds(ctx, tx, order.Number, req.UserID); err != nil {
+				return err
+			}
+
+			return s.flashSale.ConfirmClaims(ctx, tx, order.Number)
+		}
+	}
+
+	if req.Status == entity.OrderRefunded {
+		settle = func(tx *sqlx.Tx) error {
+			if _, err := s.stock.ReturnHolds(ctx, tx, order.Number, req.UserID); err != nil {
+				return err
+			}
+
+			return s.promotion.RefundRedemptions(ctx, tx, order.Number)
+		}
+	}
+
+	resp, err := s.repo.UpdateOrderStatus(ctx, order, req, settle)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Status == entity.OrderCancelled {
+		s.releaseHolds(ctx, order.Number)
+		s.releaseClaims(ctx, order.Number)
+		s.releaseVouchers(ctx, order.Number)
+	}
+
+	return resp, nil
+}
+
+// ExpireOrders cancels the pending orders whose payment window has passed and gives back
+// what they held. Holds and flash sale claims lapse on their own, but vouchers do not,
+// so without this an abandoned order would use up their limits for good.
+func (s *orderService) ExpireOrders(ctx context.Context) error {
+	orders, err := s.repo.GetExpiredOrders(ctx, expireBatch)
+	if err != nil {
+		return err
+	}
+
+	var n int
+	for i := range orders {
+		order := &orders[i]
+
+		_, err := s.repo.UpdateOrderStatus(ctx, order, &entity.UpdateOrderStatusRequest{
+			Id:     order.ID,
+			Status: entity.OrderCancelled,
+			Note:   "batas waktu pembayaran habis",
+		}, nil)
+		if err != nil {
+			// an order paid or cancelled meanwhile is no longer ours to expire
+			if customErr, ok := err.(*errmsg.CustomError); !ok || customErr.Code != 409 {
+				log.Error().Err(err).Str("number", order.Number).Msg("service::ExpireOrders - Failed to cancel order")
+			}
+			continue
+		}
+
+		s.releaseHolds(ctx, order.Number)
+		s.releaseClaims(ctx, order.Number)
+		s.releaseVouchers(ctx, order.Number)
+		n++
+	}
+
+	if n > 0 {
+		log.Info().Int("count", n).Msg("service::ExpireOrders - Cancelled expired orders")
+	}
+
+	return nil
+}
+
+// releaseHolds gives back the stock held by an order. Holds left behind lapse on their
+// own, so a failure is only logged.
+func (s *orderService) releaseHolds(ctx context.Context, number string) {
+	if err := s.stock.ReleaseHolds(ctx, number); err != nil {
+		log.Warn().Err(err).Str("number", number).Msg("service::releaseHolds - Failed to release order stock")
+	}
+}
+
+// releaseClaims gives back the flash sale quota claimed by an order. Claims left behind
+// lapse with the payment window, so a failure is only logged.
+func (s *orderService) releaseClaims(ctx context.Context, number string) {
+	if err := s.flashSale.ReleaseClaims(ctx, number); err != nil {
+		log.Warn().Err(err).Str("number", number).Msg("service::releaseClaims - Failed to release order flash sale quota")
+	}
+}
+
+// releaseVouchers gives back the vouchers spent on an order. Unlike holds they do not
+// lapse, so a failure is logged as an error to be fixed by hand.
+func (s *orderService) releaseVouchers(ctx context.Context, number string) {
+	if err := s.promotion.ReleaseRedemptions(ctx, number); err != nil {
+		log.Error().Err(err).Str("number", number).Msg("service::releaseVouchers - Failed to release order vouchers")
+	}
+}
+
+// orderActor tells whether userID takes part in order as its buyer or its seller.
+func orderActor(order *entity.Order, userID string) (string, error) {
+	switch userID {
+	case order.BuyerID:
+		return entity.OrderActorBuyer, nil
+	case order.SellerID:
+		return entity.OrderActorSeller, nil
+	default:
+		log.Warn().Str("order_id", order.ID).Str("user_id", userID).Msg("service::orderActor - User is not part of the order")
+		return "", errmsg.NewCustomErrors(403, errmsg.WithMessage("Pesanan bukan milik anda"))
+	}
+}
+
+// itemError files the field errors of a failed item under field, keeping the message.
+func itemError(err error, field string) error {
+	customErr, ok := err.(*errmsg.CustomError)
+	if !ok {
+		return err
+	}
+
+	msgs := []string{strings.ToLower(customErr.Msg) + "."}
+	for _, m := range customErr.Errors {
+		msgs = m
+		break
+	}
+	customErr.Errors = map[string][]string{field: msgs}
+
+	return customErr
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
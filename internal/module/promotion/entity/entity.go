@@ -0,0 +1,113 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+// Kinds of discount a voucher gives.
+const (
+	DiscountPercentage = "percentage"
+	DiscountFixed      = "fixed"
+)
+
+// What a voucher applies to: every product it may cover, or only the listed ones.
+const (
+	ScopeShop    = "shop"
+	ScopeProduct = "product"
+)
+
+// Voucher is a discount run by a shop, or by the platform when ShopID is nil. Vouchers
+// without a Code are promotions applied on their own; the others need their code.
+// Amounts are in minor units of Currency; DiscountValue is a percentage for
+// percentage discounts. UsageLimit caps redemptions overall and PerUserLimit per
+// buyer, nil meaning no cap.
+type Voucher struct {
+	ID            string    `json:"id" db:"id"`
+	ShopID        *string   `json:"shop_id" db:"shop_id"`
+	Code          *string   `json:"code" db:"code"`
+	Name          string    `json:"name" db:"name"`
+	DiscountType  string    `json:"discount_type" db:"discount_type"`
+	DiscountValue int64     `json:"discount_value" db:"discount_value"`
+	MaxDiscount   *int64    `json:"max_discount" db:"max_discount"`
+	Currency      string    `json:"currency" db:"currency"`
+	Scope         string    `json:"scope" db:"scope"`
+	ProductIDs    []string  `json:"product_ids" db:"-"`
+	MinSpend      int64     `json:"min_spend" db:"min_spend"`
+	UsageLimit    *int      `json:"usage_limit" db:"usage_limit"`
+	PerUserLimit  *int      `json:"per_user_limit" db:"per_user_limit"`
+	UsedCount     int       `json:"used_count" db:"used_count"`
+	Stackable     bool      `json:"stackable" db:"stackable"`
+	StartsAt      time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt        time.Time `json:"ends_at" db:"ends_at"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// VoucherRequest carries the editable fields of a voucher; updates replace all of them.
+type VoucherRequest struct {
+	Code          string    `json:"code" validate:"omitempty,alphanum,min=3,max=32"`
+	Name          string    `json:"name" validate:"required,max=255"`
+	DiscountType  string    `json:"discount_type" validate:"required,oneof=percentage fixed"`
+	DiscountValue int64     `json:"discount_value" validate:"required,min=1"`
+	MaxDiscount   *int64    `json:"max_discount" validate:"omitempty,min=1"`
+	Currency      string    `json:"currency" validate:"omitempty,len=3,alpha"`
+	Scope         string    `json:"scope" validate:"required,oneof=shop product"`
+	ProductIDs    []string  `json:"product_ids" validate:"max=100,dive,uuid"`
+	MinSpend      int64     `json:"min_spend" validate:"min=0"`
+	UsageLimit    *int      `json:"usage_limit" validate:"omitempty,min=1"`
+	PerUserLimit  *int      `json:"per_user_limit" validate:"omitempty,min=1"`
+	Stackable     bool      `json:"stackable"`
+	StartsAt      time.Time `json:"starts_at" validate:"required"`
+	EndsAt        time.Time `json:"ends_at" validate:"required"`
+}
+
+// CreateVoucherRequest creates a voucher of ShopID, or a platform voucher when it is empty.
+type CreateVoucherRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	ShopID string `params:"id" validate:"omitempty,uuid"`
+	VoucherRequest
+}
+
+type UpdateVoucherRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	ShopID string `params:"id" validate:"omitempty,uuid"`
+	Id     string `params:"voucher_id" validate:"uuid"`
+	VoucherRequest
+}
+
+type DeleteVoucherRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	ShopID string `params:"id" validate:"omitempty,uuid"`
+	Id     string `params:"voucher_id" validate:"uuid"`
+}
+
+// VouchersRequest lists the vouchers of ShopID, or the platform vouchers when it is empty.
+type VouchersRequest struct {
+	UserID   string `prop:"user_id" validate:"uuid"`
+	ShopID   string `params:"id" validate:"omitempty,uuid"`
+	Page     int    `query:"page" validate:"required"`
+	Paginate int    `query:"paginate" validate:"required,max=100"`
+}
+
+func (r *VouchersRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type VouchersResponse struct {
+	Items []Voucher  `json:"items"`
+	Meta  types.Meta `json:"meta"`
+}
+
+// LiveVoucher is a voucher within its validity window, with how many times the buyer
+// asking for it has redeemed it.
+type LiveVoucher struct {
+	Voucher
+	UsedByUser int `db:"used_by_user"`
+}
@@ -0,0 +1,262 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"fmt"
+	"math/bits"
+	"slices"
+)
+
+type BasketItemRequest struct {
+	ProductID string `json:"product_id" validate:"required,uuid"`
+	VariantID string `json:"variant_id" validate:"omitempty,uuid"`
+	Quantity  int    `json:"quantity" validate:"required,min=1"`
+}
+
+// EvaluateRequest prices a basket with the promotions that apply to it on their own
+// and the vouchers of Codes.
+type EvaluateRequest struct {
+	UserID string              `prop:"user_id" validate:"uuid"`
+	Items  []BasketItemRequest `json:"items" validate:"required,min=1,max=50,dive"`
+	Codes  []string            `json:"codes" validate:"max=5,dive,required,max=32"`
+}
+
+// BasketLine is a priced line of a basket.
+type BasketLine struct {
+	ProductID string      `json:"product_id" db:"product_id"`
+	VariantID *string     `json:"variant_id" db:"variant_id"`
+	ShopID    string      `json:"shop_id" db:"shop_id"`
+	Harga     types.Money `json:"harga" db:"harga"`
+	Quantity  int         `json:"quantity" db:"-"`
+}
+
+func (l BasketLine) Amount() int64 {
+	return l.Harga.Amount * int64(l.Quantity)
+}
+
+type AppliedVoucher struct {
+	VoucherID string      `json:"voucher_id"`
+	Code      *string     `json:"code"`
+	Name      string      `json:"name"`
+	Discount  types.Money `json:"discount"`
+}
+
+// RejectedVoucher is a voucher that was asked for but does not apply, with the reason.
+type RejectedVoucher struct {
+	VoucherID string `json:"voucher_id,omitempty"`
+	Code      string `json:"code"`
+	Reason    string `json:"reason"`
+}
+
+// Evaluation is a priced basket. Rejected vouchers cannot be used on it at all; vouchers
+// NotApplied could be, but lost to a larger discount they cannot be combined with.
+type Evaluation struct {
+	Subtotal   types.Money       `json:"subtotal"`
+	Discount   types.Money       `json:"discount"`
+	Total      types.Money       `json:"total"`
+	Applied    []AppliedVoucher  `json:"applied"`
+	Rejected   []RejectedVoucher `json:"rejected"`
+	NotApplied []RejectedVoucher `json:"not_applied"`
+}
+
+const reasonNotCombinable = "tidak dapat digabung dengan voucher lain."
+
+// Evaluate works out the discount vouchers give on a single currency basket. Vouchers
+// are expected to be live and within their usage caps already.
+//
+// A voucher that is not stackable cannot be combined with any other one. Stackable
+// vouchers apply one after another, in the order given, each on what is left of the
+// price of the lines it covers once the previous ones took their share, so no amount
+// is discounted twice. The basket gets whichever is larger: the best voucher that is
+// not stackable on its own, or all the stackable ones together. The vouchers of the
+// other side are listed as not applied rather than rejected.
+func Evaluate(lines []BasketLine, vouchers []Voucher) *Evaluation {
+	var (
+		currency = types.DefaultCurrency
+		base     = make([]int64, len(lines))
+		subtotal int64
+		resp     = &Evaluation{
+			Applied:    make([]AppliedVoucher, 0),
+			Rejected:   make([]RejectedVoucher, 0),
+			NotApplied: make([]RejectedVoucher, 0),
+		}
+	)
+
+	if len(lines) > 0 {
+		currency = lines[0].Harga.CurrencyCode()
+	}
+	for i, line := range lines {
+		base[i] = line.Amount()
+		subtotal += base[i]
+	}
+
+	type outcome struct {
+		voucher  Voucher
+		discount int64
+	}
+
+	var (
+		exclusive     *outcome
+		exclusiveOK   []Voucher
+		stacked       []outcome
+		stackedAmount int64
+		remaining     = slices.Clone(base)
+	)
+
+	for _, v := range vouchers {
+		if v.Stackable {
+			continue
+		}
+
+		discount, _, reason := v.discount(lines, base, base)
+		if reason != "" {
+			resp.Rejected = append(resp.Rejected, rejected(v, reason))
+			continue
+		}
+
+		exclusiveOK = append(exclusiveOK, v)
+		if exclusive == nil || discount > exclusive.discount {
+			exclusive = &outcome{voucher: v, discount: discount}
+		}
+	}
+
+	for _, v := range vouchers {
+		if !v.Stackable {
+			continue
+		}
+
+		discount, shares, reason := v.discount(lines, base, remaining)
+		if reason != "" {
+			resp.Rejected = append(resp.Rejected, rejected(v, reason))
+			continue
+		}
+
+		for i, share := range shares {
+			remaining[i] -= share
+		}
+		stacked = append(stacked, outcome{voucher: v, discount: discount})
+		stackedAmount += discount
+	}
+
+	if exclusive != nil && exclusive.discount > stackedAmount {
+		resp.Applied = append(resp.Applied, applied(exclusive.voucher, exclusive.discount, currency))
+		for _, o := range stacked {
+			resp.NotApplied = append(resp.NotApplied, rejected(o.voucher, reasonNotCombinable))
+		}
+	} else {
+		for _, o := range stacked {
+			resp.Applied = append(resp.Applied, applied(o.voucher, o.discount, currency))
+		}
+	}
+
+	for _, v := range exclusiveOK {
+		if len(resp.Applied) == 1 && resp.Applied[0].VoucherID == v.ID {
+			continue
+		}
+		resp.NotApplied = append(resp.NotApplied, rejected(v, reasonNotCombinable))
+	}
+
+	var discount int64
+	for _, a := range resp.Applied {
+		discount += a.Discount.Amount
+	}
+
+	resp.Subtotal = types.NewMoney(subtotal, currency)
+	resp.Discount = types.NewMoney(discount, currency)
+	resp.Total = types.NewMoney(subtotal-discount, currency)
+
+	return resp
+}
+
+// Covers reports whether the voucher may discount line.
+func (v Voucher) Covers(line BasketLine) bool {
+	if v.ShopID != nil && *v.ShopID != line.ShopID {
+		return false
+	}
+
+	if v.Scope == ScopeProduct && !slices.Contains(v.ProductIDs, line.ProductID) {
+		return false
+	}
+
+	return line.Harga.CurrencyCode() == v.Currency
+}
+
+// discount is what the voucher takes off the lines it covers, given what is left of
+// their price, and how it splits over them in proportion to it. Minimum spend is
+// checked on the full price. A reason comes back when the voucher does not apply.
+func (v Voucher) discount(lines []BasketLine, base, remaining []int64) (int64, []int64, string) {
+	var (
+		covered   []int
+		spend     int64
+		available int64
+	)
+
+	for i, line := range lines {
+		if v.Covers(line) {
+			covered = append(covered, i)
+			spend += base[i]
+			available += remaining[i]
+		}
+	}
+
+	if len(covered) == 0 || available <= 0 {
+		return 0, nil, "tidak ada produk yang memenuhi syarat."
+	}
+
+	if spend < v.MinSpend {
+		return 0, nil, fmt.Sprintf("minimal belanja %s belum terpenuhi.", types.NewMoney(v.MinSpend, v.Currency))
+	}
+
+	var amount int64
+	switch v.DiscountType {
+	case DiscountPercentage:
+		amount = mulDiv(available, min(v.DiscountValue, 100), 100)
+	case DiscountFixed:
+		amount = v.DiscountValue
+	}
+
+	if v.MaxDiscount != nil {
+		amount = min(amount, *v.MaxDiscount)
+	}
+	amount = min(amount, available)
+
+	if amount <= 0 {
+		return 0, nil, "tidak ada potongan untuk belanjaan ini."
+	}
+
+	shares := make([]int64, len(lines))
+	left := amount
+	for _, i := range covered {
+		shares[i] = mulDiv(amount, remaining[i], available)
+		left -= shares[i]
+	}
+
+	// what rounding left over goes to the first lines that still have room for it
+	for _, i := range covered {
+		take := min(remaining[i]-shares[i], left)
+		shares[i] += take
+		left -= take
+	}
+
+	return amount, shares, ""
+}
+
+// mulDiv returns a*b/c without overflowing on the product; a*b/c must fit in an int64.
+func mulDiv(a, b, c int64) int64 {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	q, _ := bits.Div64(hi, lo, uint64(c))
+	return int64(q)
+}
+
+func applied(v Voucher, discount int64, currency string) AppliedVoucher {
+	return AppliedVoucher{VoucherID: v.ID, Code: v.Code, Name: v.Name, Discount: types.NewMoney(discount, currency)}
+}
+
+func rejected(v Voucher, reason string) RejectedVoucher {
+	var code string
+	if v.Code != nil {
+		code = *v.Code
+	}
+
+	return RejectedVoucher{VoucherID: v.ID, Code: code, Reason: reason}
+}
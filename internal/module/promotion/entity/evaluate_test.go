@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func basket() []BasketLine {
+	return []BasketLine{
+		{ProductID: "p1", ShopID: "s1", Harga: types.NewMoney(100000, "IDR"), Quantity: 2},
+		{ProductID: "p2", ShopID: "s1", Harga: types.NewMoney(50000, "IDR"), Quantity: 1},
+		{ProductID: "p3", ShopID: "s2", Harga: types.NewMoney(30000, "IDR"), Quantity: 1},
+	}
+}
+
+func voucher(id string, stackable bool, discountType string, value int64) Voucher {
+	return Voucher{
+		ID:            id,
+		Code:          ptr(id),
+		Name:          id,
+		DiscountType:  discountType,
+		DiscountValue: value,
+		Currency:      "IDR",
+		Scope:         ScopeShop,
+		Stackable:     stackable,
+	}
+}
+
+func TestEvaluateScope(t *testing.T) {
+	shop := voucher("SHOP10", false, DiscountPercentage, 10)
+	shop.ShopID = ptr("s1")
+
+	product := voucher("P3", false, DiscountFixed, 50000)
+	product.Scope = ScopeProduct
+	product.ProductIDs = []string{"p3"}
+
+	got := Evaluate(basket(), []Voucher{shop})
+	assert.Equal(t, int64(280000), got.Subtotal.Amount)
+	assert.Equal(t, int64(25000), got.Discount.Amount) // 10% of the 250000 sold by s1
+	assert.Equal(t, int64(255000), got.Total.Amount)
+
+	// a fixed discount never exceeds what the covered lines cost
+	got = Evaluate(basket(), []Voucher{product})
+	assert.Equal(t, int64(30000), got.Discount.Amount)
+}
+
+func TestEvaluateMinSpendAndCap(t *testing.T) {
+	v := voucher("BIG", false, DiscountPercentage, 50)
+	v.MinSpend = 300000
+
+	got := Evaluate(basket(), []Voucher{v})
+	assert.Empty(t, got.Applied)
+	assert.Len(t, got.Rejected, 1)
+	assert.Equal(t, int64(0), got.Discount.Amount)
+
+	v.MinSpend = 0
+	v.MaxDiscount = ptr(int64(40000))
+
+	got = Evaluate(basket(), []Voucher{v})
+	assert.Equal(t, int64(40000), got.Discount.Amount)
+}
+
+func TestEvaluateStacking(t *testing.T) {
+	// stackable vouchers apply on what the previous ones left: 10% of 280000, then
+	// 10% of the remaining 252000
+	a := voucher("A", true, DiscountPercentage, 10)
+	b := voucher("B", true, DiscountPercentage, 10)
+
+	got := Evaluate(basket(), []Voucher{a, b})
+	assert.Len(t, got.Applied, 2)
+	assert.Equal(t, int64(28000+25200), got.Discount.Amount)
+
+	// a voucher that is not stackable wins only when it alone gives more
+	c := voucher("C", false, DiscountFixed, 50000)
+
+	got = Evaluate(basket(), []Voucher{a, b, c})
+	assert.Len(t, got.Applied, 2)
+	assert.Equal(t, int64(53200), got.Discount.Amount)
+	assert.Equal(t, []RejectedVoucher{{VoucherID: "C", Code: "C", Reason: reasonNotCombinable}}, got.NotApplied)
+	assert.Empty(t, got.Rejected)
+
+	c.DiscountValue = 60000
+
+	got = Evaluate(basket(), []Voucher{a, b, c})
+	assert.Len(t, got.Applied, 1)
+	assert.Equal(t, "C", got.Applied[0].VoucherID)
+	assert.Equal(t, int64(60000), got.Discount.Amount)
+	assert.Len(t, got.NotApplied, 2)
+	assert.Empty(t, got.Rejected)
+}
+
+func TestEvaluateNeverBelowZero(t *testing.T) {
+	a := voucher("A", true, DiscountFixed, 200000)
+	b := voucher("B", true, DiscountFixed, 200000)
+
+	got := Evaluate(basket(), []Voucher{a, b})
+	assert.Equal(t, int64(280000), got.Discount.Amount)
+	assert.Equal(t, int64(0), got.Total.Amount)
+}
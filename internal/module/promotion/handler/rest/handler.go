@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/middleware"
+	"codebase-app/internal/module/promotion/entity"
+	"codebase-app/internal/module/promotion/ports"
+	"codebase-app/internal/module/promotion/repository"
+	"codebase-app/internal/module/promotion/service"
+	shopRepository "codebase-app/internal/module/shop/repository"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type promotionHandler struct {
+	service ports.PromotionService
+}
+
+func NewPromotionHandler() *promotionHandler {
+	var (
+		handler = new(promotionHandler)
+		repo    = repository.NewPromotionRepository(adapter.Adapters.ShopeefunPostgres)
+		shop    = shopRepository.NewShopRepository(adapter.Adapters.ShopeefunPostgres)
+		service = service.NewPromotionService(repo, shop)
+	)
+	handler.service = service
+
+	return handler
+}
+
+// Register mounts the vouchers of a shop for its owner and the platform vouchers for
+// admins on the same handlers; the shop comes from the :id of the path, if any.
+func (h *promotionHandler) Register(router fiber.Router) {
+	admin := []fiber.Handler{middleware.AuthBearer, middleware.AuthRole([]string{"admin"})}
+
+	router.Post("/shops/:id/vouchers", middleware.UserIdHeader, h.CreateVoucher)
+	router.Get("/shops/:id/vouchers", middleware.UserIdHeader, h.GetVouchers)
+	router.Patch("/shops/:id/vouchers/:voucher_id", middleware.UserIdHeader, h.UpdateVoucher)
+	router.Delete("/shops/:id/vouchers/:voucher_id", middleware.UserIdHeader, h.DeleteVoucher)
+
+	router.Post("/vouchers", append(admin, h.CreateVoucher)...)
+	router.Get("/vouchers", append(admin, h.GetVouchers)...)
+	router.Patch("/vouchers/:voucher_id", append(admin, h.UpdateVoucher)...)
+	router.Delete("/vouchers/:voucher_id", append(admin, h.DeleteVoucher)...)
+
+	router.Post("/promotions/evaluate", middleware.UserIdHeader, h.Evaluate)
+}
+
+func (h *promotionHandler) CreateVoucher(c *fiber.Ctx) error {
+	var (
+		req = new(entity.CreateVoucherRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::CreateVoucher - Parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserID = l.UserId
+	req.ShopID = c.Params("id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::CreateVoucher - Validate request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.CreateVoucher(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response.Success(resp, ""))
+}
+
+func (h *promotionHandler) GetVouchers(c *fiber.Ctx) error {
+	var (
+		req = new(entity.VouchersRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetVouchers - Parse request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserID = l.UserId
+	req.ShopID = c.Params("id")
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetVouchers - Validate request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetVouchers(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
+
+func (h *promotionHandler) UpdateVoucher(c *fiber.Ctx) error {
+	var (
+		req = new(entity.UpdateVoucherRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::UpdateVoucher - Parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserID = l.UserId
+	req.ShopID = c.Params("id")
+	req.Id = c.Params("voucher_id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::UpdateVoucher - Validate request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.UpdateVoucher(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
+
+func (h *promotionHandler) DeleteVoucher(c *fiber.Ctx) error {
+	var (
+		req = new(entity.DeleteVoucherRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	req.UserID = l.UserId
+	req.ShopID = c.Params("id")
+	req.Id = c.Params("voucher_id")
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::DeleteVoucher - Validate request")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	if err := h.service.DeleteVoucher(ctx, req); err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(nil, ""))
+}
+
+func (h *promotionHandler) Evaluate(c *fiber.Ctx) error {
+	var (
+		req = new(entity.EvaluateRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.BodyParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::Evaluate - Parse request body")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserID = l.UserId
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::Evaluate - Validate request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.Evaluate(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
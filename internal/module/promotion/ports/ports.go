@@ -0,0 +1,38 @@
+package ports
+
+import (
+	"codebase-app/internal/module/promotion/entity"
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PromotionRepository interface {
+	CreateVoucher(ctx context.Context, req *entity.CreateVoucherRequest) (*entity.Voucher, error)
+	UpdateVoucher(ctx context.Context, req *entity.UpdateVoucherRequest) (*entity.Voucher, error)
+	DeleteVoucher(ctx context.Context, req *entity.DeleteVoucherRequest) error
+	GetVouchers(ctx context.Context, req *entity.VouchersRequest) (*entity.VouchersResponse, error)
+	CountShopProducts(ctx context.Context, shopID string, productIDs []string) (int, error)
+	GetBasketLines(ctx context.Context, items []entity.BasketItemRequest) ([]entity.BasketLine, error)
+	GetLiveVouchers(ctx context.Context, userID string, shopIDs, codes []string) ([]entity.LiveVoucher, error)
+	Redeem(ctx context.Context, userID, reference string, applied []entity.AppliedVoucher) error
+	ReleaseRedemptions(ctx context.Context, reference string) error
+	RefundRedemptions(ctx context.Context, tx *sqlx.Tx, reference string) error
+}
+
+// ShopRepository is the part of the shop module vouchers rely on.
+type ShopRepository interface {
+	CheckShopOwner(ctx context.Context, shopID, userID string) error
+}
+
+type PromotionService interface {
+	CreateVoucher(ctx context.Context, req *entity.CreateVoucherRequest) (*entity.Voucher, error)
+	UpdateVoucher(ctx context.Context, req *entity.UpdateVoucherRequest) (*entity.Voucher, error)
+	DeleteVoucher(ctx context.Context, req *entity.DeleteVoucherRequest) error
+	GetVouchers(ctx context.Context, req *entity.VouchersRequest) (*entity.VouchersResponse, error)
+	Evaluate(ctx context.Context, req *entity.EvaluateRequest) (*entity.Evaluation, error)
+	Apply(ctx context.Context, userID string, lines []entity.BasketLine, codes []string) (*entity.Evaluation, error)
+	Redeem(ctx context.Context, userID, reference string, applied []entity.AppliedVoucher) error
+	ReleaseRedemptions(ctx context.Context, reference string) error
+	RefundRedemptions(ctx context.Context, tx *sqlx.Tx, reference string) error
+}
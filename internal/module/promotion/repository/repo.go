@@ -0,0 +1,512 @@
+package repository
+
+import (
+	"codebase-app/internal/module/promotion/entity"
+	"codebase-app/internal/module/promotion/ports"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/types"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.PromotionRepository = &promotionRepository{}
+
+type promotionRepository struct {
+	db *sqlx.DB
+}
+
+func NewPromotionRepository(db *sqlx.DB) *promotionRepository {
+	return &promotionRepository{
+		db: db,
+	}
+}
+
+const voucherColumns = `
+	vouchers.id, vouchers.shop_id, vouchers.code, vouchers.name, vouchers.discount_type, vouchers.discount_value,
+	vouchers.max_discount, vouchers.currency, vouchers.scope, vouchers.min_spend, vouchers.usage_limit,
+	vouchers.per_user_limit, vouchers.used_count, vouchers.stackable, vouchers.starts_at, vouchers.ends_at,
+	vouchers.created_at, vouchers.updated_at,
+	ARRAY(
+		SELECT voucher_products.product_id::text FROM voucher_products
+		WHERE voucher_products.voucher_id = vouchers.id
+		ORDER BY voucher_products.product_id
+	) AS product_ids`
+
+// voucherDao reads a voucher row together with its product ids.
+type voucherDao struct {
+	ProductIDs pq.StringArray `db:"product_ids"`
+	entity.Voucher
+}
+
+func (d voucherDao) voucher() entity.Voucher {
+	v := d.Voucher
+	v.ProductIDs = []string(d.ProductIDs)
+	return v
+}
+
+func (r *promotionRepository) CreateVoucher(ctx context.Context, req *entity.CreateVoucherRequest) (*entity.Voucher, error) {
+	var id string
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CreateVoucher - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO vouchers (
+			shop_id, code, name, discount_type, discount_value, max_discount, currency, scope,
+			min_spend, usage_limit, per_user_limit, stackable, starts_at, ends_at, created_by
+		)
+		VALUES (CAST(NULLIF(?, '') AS uuid), NULLIF(UPPER(?), ''), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+	err = tx.GetContext(ctx, &id, tx.Rebind(query),
+		req.ShopID,
+		req.Code,
+		req.Name,
+		req.DiscountType,
+		req.DiscountValue,
+		req.MaxDiscount,
+		types.NewMoney(0, req.Currency).CurrencyCode(),
+		req.Scope,
+		req.MinSpend,
+		req.UsageLimit,
+		req.PerUserLimit,
+		req.Stackable,
+		req.StartsAt,
+		req.EndsAt,
+		req.UserID,
+	)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CreateVoucher - Failed to insert voucher")
+		return nil, err
+	}
+
+	if err := setVoucherProducts(ctx, tx, id, req.Scope, req.ProductIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CreateVoucher - Failed to commit transaction")
+		return nil, err
+	}
+
+	return r.getVoucher(ctx, id)
+}
+
+// UpdateVoucher replaces the fields of a voucher of req.ShopID, or of a platform
+// voucher when it is empty. Its usage so far is kept.
+func (r *promotionRepository) UpdateVoucher(ctx context.Context, req *entity.UpdateVoucherRequest) (*entity.Voucher, error) {
+	var used int
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateVoucher - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	queryLock := `
+		SELECT used_count FROM vouchers
+		WHERE id = ? AND shop_id IS NOT DISTINCT FROM CAST(NULLIF(?, '') AS uuid) AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	err = tx.GetContext(ctx, &used, tx.Rebind(queryLock), req.Id, req.ShopID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Voucher tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateVoucher - Failed to lock voucher")
+		return nil, err
+	}
+
+	if req.UsageLimit != nil && *req.UsageLimit < used {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("usage_limit", "kuota tidak boleh kurang dari pemakaian saat ini."))
+	}
+
+	query := `
+		UPDATE vouchers SET
+			code = NULLIF(UPPER(?), ''),
+			name = ?,
+			discount_type = ?,
+			discount_value = ?,
+			max_discount = ?,
+			currency = ?,
+			scope = ?,
+			min_spend = ?,
+			usage_limit = ?,
+			per_user_limit = ?,
+			stackable = ?,
+			starts_at = ?,
+			ends_at = ?,
+			updated_at = NOW()
+		WHERE id = ?
+	`
+	_, err = tx.ExecContext(ctx, tx.Rebind(query),
+		req.Code,
+		req.Name,
+		req.DiscountType,
+		req.DiscountValue,
+		req.MaxDiscount,
+		types.NewMoney(0, req.Currency).CurrencyCode(),
+		req.Scope,
+		req.MinSpend,
+		req.UsageLimit,
+		req.PerUserLimit,
+		req.Stackable,
+		req.StartsAt,
+		req.EndsAt,
+		req.Id,
+	)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateVoucher - Failed to update voucher")
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM voucher_products WHERE voucher_id = ?`), req.Id); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateVoucher - Failed to clear voucher products")
+		return nil, err
+	}
+
+	if err := setVoucherProducts(ctx, tx, req.Id, req.Scope, req.ProductIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateVoucher - Failed to commit transaction")
+		return nil, err
+	}
+
+	return r.getVoucher(ctx, req.Id)
+}
+
+func setVoucherProducts(ctx context.Context, tx *sqlx.Tx, voucherID, scope string, productIDs []string) error {
+	if scope != entity.ScopeProduct || len(productIDs) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO voucher_products (voucher_id, product_id)
+		SELECT ?, product_id FROM unnest(CAST(? AS uuid[])) AS product_id
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), voucherID, pq.Array(productIDs)); err != nil {
+		log.Error().Err(err).Str("voucher_id", voucherID).Msg("repository::setVoucherProducts - Failed to save voucher products")
+		return err
+	}
+
+	return nil
+}
+
+func (r *promotionRepository) DeleteVoucher(ctx context.Context, req *entity.DeleteVoucherRequest) error {
+	query := `
+		UPDATE vouchers SET deleted_at = NOW()
+		WHERE id = ? AND shop_id IS NOT DISTINCT FROM CAST(NULLIF(?, '') AS uuid) AND deleted_at IS NULL
+	`
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(query), req.Id, req.ShopID)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::DeleteVoucher - Failed to delete voucher")
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::DeleteVoucher - Failed to read affected rows")
+		return err
+	}
+	if n == 0 {
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Voucher tidak ditemukan"))
+	}
+
+	return nil
+}
+
+// GetVouchers lists the vouchers of req.ShopID, or the platform vouchers when it is
+// empty, newest first.
+func (r *promotionRepository) GetVouchers(ctx context.Context, req *entity.VouchersRequest) (*entity.VouchersResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		voucherDao
+	}
+
+	var (
+		data []dao
+		resp = &entity.VouchersResponse{Items: make([]entity.Voucher, 0, req.Paginate)}
+	)
+
+	query := `
+		SELECT COUNT(vouchers.id) OVER() AS total_data, ` + voucherColumns + `
+		FROM vouchers
+		WHERE shop_id IS NOT DISTINCT FROM CAST(NULLIF(?, '') AS uuid) AND deleted_at IS NULL
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), req.ShopID, req.Paginate, req.Paginate*(req.Page-1))
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::GetVouchers - Failed to get vouchers")
+		return nil, err
+	}
+
+	for _, d := range data {
+		resp.Items = append(resp.Items, d.voucher())
+	}
+
+	if len(data) > 0 {
+		resp.Meta.TotalData = data[0].TotalData
+	}
+	resp.Meta.CountTotalPage(req.Page, req.Paginate, resp.Meta.TotalData)
+
+	return resp, nil
+}
+
+func (r *promotionRepository) getVoucher(ctx context.Context, id string) (*entity.Voucher, error) {
+	var data voucherDao
+
+	query := `SELECT ` + voucherColumns + ` FROM vouchers WHERE id = ?`
+	err := r.db.GetContext(ctx, &data, r.db.Rebind(query), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Voucher tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("repository::getVoucher - Failed to get voucher")
+		return nil, err
+	}
+
+	v := data.voucher()
+	return &v, nil
+}
+
+// CountShopProducts counts how many of productIDs are live products of shopID.
+func (r *promotionRepository) CountShopProducts(ctx context.Context, shopID string, productIDs []string) (int, error) {
+	var n int
+
+	query := `SELECT COUNT(id) FROM product WHERE id = ANY(CAST(? AS uuid[])) AND (? = '' OR shop_id = CAST(NULLIF(?, '') AS uuid)) AND deleted_at IS NULL`
+	if err := r.db.GetContext(ctx, &n, r.db.Rebind(query), pq.Array(productIDs), shopID, shopID); err != nil {
+		log.Error().Err(err).Str("shop_id", shopID).Msg("repository::CountShopProducts - Failed to count products")
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// GetBasketLines prices the items of a basket at the current price of their product or
+// SKU, the flash price while a flash sale has quota left. Items that cannot be bought
+// come back as a 404 or 400 error for items[i].
+func (r *promotionRepository) GetBasketLines(ctx context.Context, items []entity.BasketItemRequest) ([]entity.BasketLine, error) {
+	var resp = make([]entity.BasketLine, 0, len(items))
+
+	query := `
+		SELECT
+			product.id AS product_id,
+			product_variants.id AS variant_id,
+			product.shop_id,
+			ROW(COALESCE(flash.harga, product_variants.harga, product.harga), COALESCE(product_variants.currency, product.currency)) AS harga,
+			EXISTS (
+				SELECT 1 FROM product_variants
+				WHERE product_variants.product_id = product.id AND product_variants.deleted_at IS NULL
+			) AS has_variants
+		FROM product
+		LEFT JOIN product_variants
+			ON product_variants.product_id = product.id
+			AND product_variants.id = CAST(NULLIF(?, '') AS uuid)
+			AND product_variants.deleted_at IS NULL
+		-- flash sales only price products without SKUs
+		LEFT JOIN active_flash_sale_items flash
+			ON flash.product_id = product.id AND flash.remaining > 0 AND product_variants.id IS NULL
+		WHERE product.id = ? AND product.deleted_at IS NULL AND product.status = 'published'
+	`
+	for i, item := range items {
+		var line struct {
+			entity.BasketLine
+			HasVariants bool `db:"has_variants"`
+		}
+
+		field := fmt.Sprintf("items[%d]", i)
+
+		err := r.db.GetContext(ctx, &line, r.db.Rebind(query), item.VariantID, item.ProductID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithErrors(field+".product_id", "produk tidak ditemukan."))
+		}
+		if err != nil {
+			log.Error().Err(err).Any("payload", item).Msg("repository::GetBasketLines - Failed to get product")
+			return nil, err
+		}
+
+		if item.VariantID != "" && line.VariantID == nil {
+			return nil, errmsg.NewCustomErrors(404, errmsg.WithErrors(field+".variant_id", "varian produk tidak ditemukan."))
+		}
+		if item.VariantID == "" && line.HasVariants {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors(field+".variant_id", "varian harus dipilih."))
+		}
+
+		line.Quantity = item.Quantity
+		resp = append(resp, line.BasketLine)
+	}
+
+	return resp, nil
+}
+
+// GetLiveVouchers returns the vouchers of codes that are within their validity window,
+// in the order of codes, followed by the promotions without a code that apply on their
+// own to the platform or to one of shopIDs.
+func (r *promotionRepository) GetLiveVouchers(ctx context.Context, userID string, shopIDs, codes []string) ([]entity.LiveVoucher, error) {
+	type dao struct {
+		voucherDao
+		UsedByUser int `db:"used_by_user"`
+	}
+
+	var (
+		data  []dao
+		upper = make([]string, 0, len(codes))
+	)
+
+	for _, code := range codes {
+		upper = append(upper, strings.ToUpper(code))
+	}
+
+	query := `
+		SELECT ` + voucherColumns + `,
+			(
+				SELECT COUNT(id) FROM voucher_redemptions
+				WHERE voucher_redemptions.voucher_id = vouchers.id
+					AND voucher_redemptions.user_id = ?
+					AND voucher_redemptions.released_at IS NULL
+			) AS used_by_user
+		FROM vouchers
+		WHERE vouchers.deleted_at IS NULL
+			AND NOW() >= vouchers.starts_at AND NOW() < vouchers.ends_at
+			AND (
+				upper(vouchers.code) = ANY(?)
+				OR (vouchers.code IS NULL AND (vouchers.shop_id IS NULL OR vouchers.shop_id = ANY(CAST(? AS uuid[]))))
+			)
+		ORDER BY array_position(CAST(? AS text[]), upper(vouchers.code)) NULLS LAST, vouchers.created_at, vouchers.id
+	`
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), userID, pq.Array(upper), pq.Array(shopIDs), pq.Array(upper))
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("repository::GetLiveVouchers - Failed to get vouchers")
+		return nil, err
+	}
+
+	resp := make([]entity.LiveVoucher, 0, len(data))
+	for _, d := range data {
+		resp = append(resp, entity.LiveVoucher{Voucher: d.voucher(), UsedByUser: d.UsedByUser})
+	}
+
+	return resp, nil
+}
+
+// Redeem records that userID spent the applied vouchers on reference. Usage caps are
+// checked again under a lock on each voucher, so concurrent redemptions cannot go
+// past them; nothing is recorded when one of the vouchers ran out.
+func (r *promotionRepository) Redeem(ctx context.Context, userID, reference string, applied []entity.AppliedVoucher) error {
+	if len(applied) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Str("reference", reference).Msg("repository::Redeem - Failed to begin transaction")
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, a := range applied {
+		var perUser *int
+
+		query := `
+			UPDATE vouchers SET used_count = used_count + 1, updated_at = NOW()
+			WHERE id = ?
+				AND deleted_at IS NULL
+				AND NOW() < ends_at
+				AND (usage_limit IS NULL OR used_count < usage_limit)
+			RETURNING per_user_limit
+		`
+		err := tx.GetContext(ctx, &perUser, tx.Rebind(query), a.VoucherID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errmsg.NewCustomErrors(409, errmsg.WithMessage("Kuota voucher sudah habis"), errmsg.WithErrors("voucher_codes", voucherLabel(a)+" sudah habis."))
+		}
+		if err != nil {
+			log.Error().Err(err).Str("reference", reference).Msg("repository::Redeem - Failed to take voucher")
+			return err
+		}
+
+		if perUser != nil {
+			var used int
+
+			queryUsed := `
+				SELECT COUNT(id) FROM voucher_redemptions
+				WHERE voucher_id = ? AND user_id = ? AND released_at IS NULL
+			`
+			if err := tx.GetContext(ctx, &used, tx.Rebind(queryUsed), a.VoucherID, userID); err != nil {
+				log.Error().Err(err).Str("reference", reference).Msg("repository::Redeem - Failed to count redemptions")
+				return err
+			}
+
+			if used >= *perUser {
+				return errmsg.NewCustomErrors(409, errmsg.WithMessage("Batas pemakaian voucher sudah tercapai"), errmsg.WithErrors("voucher_codes", voucherLabel(a)+" sudah mencapai batas pemakaian."))
+			}
+		}
+
+		queryInsert := `
+			INSERT INTO voucher_redemptions (voucher_id, user_id, reference, discount)
+			VALUES (?, ?, ?, ?)
+		`
+		if _, err := tx.ExecContext(ctx, tx.Rebind(queryInsert), a.VoucherID, userID, reference, a.Discount.Amount); err != nil {
+			log.Error().Err(err).Str("reference", reference).Msg("repository::Redeem - Failed to record redemption")
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Str("reference", reference).Msg("repository::Redeem - Failed to commit transaction")
+		return err
+	}
+
+	return nil
+}
+
+// ReleaseRedemptions gives back the vouchers spent on reference.
+func (r *promotionRepository) ReleaseRedemptions(ctx context.Context, reference string) error {
+	return releaseRedemptions(ctx, r.db, reference)
+}
+
+// RefundRedemptions gives back the vouchers spent on reference inside tx, the transaction
+// of the change that undoes the sale, e.g. an order being refunded.
+func (r *promotionRepository) RefundRedemptions(ctx context.Context, tx *sqlx.Tx, reference string) error {
+	return releaseRedemptions(ctx, tx, reference)
+}
+
+func releaseRedemptions(ctx context.Context, db sqlx.ExtContext, reference string) error {
+	query := `
+		WITH released AS (
+			UPDATE voucher_redemptions SET released_at = NOW()
+			WHERE reference = ? AND released_at IS NULL
+			RETURNING voucher_id
+		)
+		UPDATE vouchers SET used_count = used_count - released.n, updated_at = NOW()
+		FROM (SELECT voucher_id, COUNT(*) AS n FROM released GROUP BY voucher_id) released
+		WHERE vouchers.id = released.voucher_id
+	`
+	if _, err := db.ExecContext(ctx, db.Rebind(query), reference); err != nil {
+		log.Error().Err(err).Str("reference", reference).Msg("repository::releaseRedemptions - Failed to release vouchers")
+		return err
+	}
+
+	return nil
+}
+
+func voucherLabel(a entity.AppliedVoucher) string {
+	if a.Code != nil {
+		return "voucher " + *a.Code
+	}
+	return "promo " + a.Name
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"codebase-app/internal/module/promotion/entity"
+	"codebase-app/internal/module/promotion/ports"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var _ ports.PromotionService = &promotionService{}
+
+type promotionService struct {
+	repo ports.PromotionRepository
+	shop ports.ShopRepository
+}
+
+func NewPromotionService(repo ports.PromotionRepository, shop ports.ShopRepository) *promotionService {
+	return &promotionService{
+		repo: repo,
+		shop: shop,
+	}
+}
+
+// CreateVoucher creates a voucher of a shop for its owner. Platform vouchers have no
+// owner to check; their routes are for admins only.
+func (s *promotionService) CreateVoucher(ctx context.Context, req *entity.CreateVoucherRequest) (*entity.Voucher, error) {
+	if err := s.checkVoucher(ctx, req.UserID, req.ShopID, &req.VoucherRequest); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateVoucher(ctx, req)
+}
+
+func (s *promotionService) UpdateVoucher(ctx context.Context, req *entity.UpdateVoucherRequest) (*entity.Voucher, error) {
+	if err := s.checkVoucher(ctx, req.UserID, req.ShopID, &req.VoucherRequest); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateVoucher(ctx, req)
+}
+
+func (s *promotionService) DeleteVoucher(ctx context.Context, req *entity.DeleteVoucherRequest) error {
+	if err := s.checkOwner(ctx, req.ShopID, req.UserID); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteVoucher(ctx, req)
+}
+
+func (s *promotionService) GetVouchers(ctx context.Context, req *entity.VouchersRequest) (*entity.VouchersResponse, error) {
+	if err := s.checkOwner(ctx, req.ShopID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetVouchers(ctx, req)
+}
+
+// Evaluate prices a basket with the vouchers that would apply to it if it were ordered now.
+func (s *promotionService) Evaluate(ctx context.Context, req *entity.EvaluateRequest) (*entity.Evaluation, error) {
+	lines, err := s.repo.GetBasketLines(ctx, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, line := range lines {
+		if line.Harga.CurrencyCode() != lines[0].Harga.CurrencyCode() {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors(fmt.Sprintf("items[%d].product_id", i), "mata uang produk harus sama."))
+		}
+	}
+
+	return s.Apply(ctx, req.UserID, lines, req.Codes)
+}
+
+// Apply works out the discount on priced lines for userID. The vouchers of codes come
+// first, in the order given, then the promotions without a code that cover the lines.
+// A code that cannot be used is listed as rejected with the reason, and one that lost
+// to a better promotion as not applied; promotions without a code that do not apply
+// are left out.
+func (s *promotionService) Apply(ctx context.Context, userID string, lines []entity.BasketLine, codes []string) (*entity.Evaluation, error) {
+	var (
+		shopIDs  = make([]string, 0, len(lines))
+		vouchers = make([]entity.Voucher, 0)
+		rejected = make([]entity.RejectedVoucher, 0)
+		seen     = make(map[string]bool, len(codes))
+	)
+
+	for _, line := range lines {
+		shopIDs = append(shopIDs, line.ShopID)
+	}
+
+	live, err := s.repo.GetLiveVouchers(ctx, userID, shopIDs, codes)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]entity.LiveVoucher, len(live))
+	for _, v := range live {
+		if v.Code != nil {
+			found[strings.ToUpper(*v.Code)] = v
+		}
+	}
+
+	for _, code := range codes {
+		key := strings.ToUpper(code)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		v, ok := found[key]
+		switch {
+		case !ok:
+			rejected = append(rejected, entity.RejectedVoucher{Code: code, Reason: "voucher tidak ditemukan atau tidak berlaku."})
+		case v.UsageLimit != nil && v.UsedCount >= *v.UsageLimit:
+			rejected = append(rejected, entity.RejectedVoucher{VoucherID: v.ID, Code: code, Reason: "kuota voucher sudah habis."})
+		case v.PerUserLimit != nil && v.UsedByUser >= *v.PerUserLimit:
+			rejected = append(rejected, entity.RejectedVoucher{VoucherID: v.ID, Code: code, Reason: "batas pemakaian voucher sudah tercapai."})
+		default:
+			vouchers = append(vouchers, v.Voucher)
+		}
+	}
+
+	for _, v := range live {
+		if v.Code != nil {
+			continue
+		}
+		if (v.UsageLimit != nil && v.UsedCount >= *v.UsageLimit) || (v.PerUserLimit != nil && v.UsedByUser >= *v.PerUserLimit) {
+			continue
+		}
+		vouchers = append(vouchers, v.Voucher)
+	}
+
+	resp := entity.Evaluate(lines, vouchers)
+
+	for _, r := range resp.Rejected {
+		if r.Code != "" {
+			rejected = append(rejected, r)
+		}
+	}
+	resp.Rejected = rejected
+
+	// promotions without a code that lost to a better one were never asked for
+	notApplied := make([]entity.RejectedVoucher, 0, len(resp.NotApplied))
+	for _, r := range resp.NotApplied {
+		if r.Code != "" {
+			notApplied = append(notApplied, r)
+		}
+	}
+	resp.NotApplied = notApplied
+
+	return resp, nil
+}
+
+func (s *promotionService) Redeem(ctx context.Context, userID, reference string, applied []entity.AppliedVoucher) error {
+	return s.repo.Redeem(ctx, userID, reference, applied)
+}
+
+func (s *promotionService) ReleaseRedemptions(ctx context.Context, reference string) error {
+	return s.repo.ReleaseRedemptions(ctx, reference)
+}
+
+func (s *promotionService) RefundRedemptions(ctx context.Context, tx *sqlx.Tx, reference string) error {
+	return s.repo.RefundRedemptions(ctx, tx, reference)
+}
+
+// checkVoucher validates what the tags of a voucher cannot express on their own.
+func (s *promotionService) checkVoucher(ctx context.Context, userID, shopID string, req *entity.VoucherRequest) error {
+	if req.DiscountType == entity.DiscountPercentage && req.DiscountValue > 100 {
+		return errmsg.NewCustomErrors(400, errmsg.WithErrors("discount_value", "persentase potongan maksimal 100."))
+	}
+
+	if !req.EndsAt.After(req.StartsAt) {
+		return errmsg.NewCustomErrors(400, errmsg.WithErrors("ends_at", "harus setelah starts_at."))
+	}
+
+	if req.Scope == entity.ScopeProduct && len(req.ProductIDs) == 0 {
+		return errmsg.NewCustomErrors(400, errmsg.WithErrors("product_ids", "wajib diisi untuk voucher produk."))
+	}
+
+	if err := s.checkOwner(ctx, shopID, userID); err != nil {
+		return err
+	}
+
+	if req.Scope != entity.ScopeProduct {
+		return nil
+	}
+
+	productIDs := dedupe(req.ProductIDs)
+	n, err := s.repo.CountShopProducts(ctx, shopID, productIDs)
+	if err != nil {
+		return err
+	}
+
+	if n != len(productIDs) {
+		msg := "produk tidak ditemukan."
+		if shopID != "" {
+			msg = "produk tidak ditemukan di toko ini."
+		}
+		return errmsg.NewCustomErrors(400, errmsg.WithErrors("product_ids", msg))
+	}
+	req.ProductIDs = productIDs
+
+	return nil
+}
+
+// checkOwner checks that userID owns shopID; platform vouchers, with no shop, pass.
+func (s *promotionService) checkOwner(ctx context.Context, shopID, userID string) error {
+	if shopID == "" {
+		return nil
+	}
+
+	return s.shop.CheckShopOwner(ctx, shopID, userID)
+}
+
+func dedupe(ids []string) []string {
+	var (
+		resp = make([]string, 0, len(ids))
+		seen = make(map[string]bool, len(ids))
+	)
+
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			resp = append(resp, id)
+		}
+	}
+
+	return resp
+}
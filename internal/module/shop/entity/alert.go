@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+// StockAlert is raised once each time a product's stock drops below its low-stock threshold.
+type StockAlert struct {
+	ID          string    `json:"id" db:"id"`
+	ProductID   string    `json:"product_id" db:"product_id"`
+	ProductName string    `json:"product_name" db:"product_name"`
+	Stok        int       `json:"stok" db:"stok"`
+	Threshold   int       `json:"threshold" db:"threshold"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// StockAlertsRequest reads a shop's alert feed oldest first. Unlike the listings the
+// feed always hands out a next_cursor, passing it back only returns alerts raised since.
+type StockAlertsRequest struct {
+	UserID   string `prop:"user_id" validate:"uuid"`
+	ShopID   string `params:"id" validate:"uuid"`
+	Paginate int    `query:"paginate" validate:"required,max=100"`
+	Cursor   string `query:"cursor"`
+}
+
+func (r *StockAlertsRequest) SetDefault() {
+	if r.Paginate < 1 {
+		r.Paginate = 20
+	}
+}
+
+type StockAlertsResponse struct {
+	Items []StockAlert `json:"items"`
+	Meta  types.Meta   `json:"meta"`
+}
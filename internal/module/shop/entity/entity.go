@@ -1,6 +1,9 @@
 package entity
 
-import "codebase-app/pkg/types"
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
 
 type CreateShopRequest struct {
 	UserId string `validate:"uuid" db:"user_id"`
@@ -19,9 +22,12 @@ type GetShopRequest struct {
 }
 
 type GetShopResponse struct {
-	Name        string `json:"name" db:"name"`
-	Description string `json:"description" db:"description"`
-	Terms       string `json:"terms" db:"terms"`
+	Name          string `json:"name" db:"name"`
+	Description   string `json:"description" db:"description"`
+	Terms         string `json:"terms" db:"terms"`
+	FollowerCount int64  `json:"follower_count" db:"follower_count"`
+
+	ShopStatus
 }
 
 type DeleteShopRequest struct {
@@ -46,7 +52,8 @@ type UpdateShopResponse struct {
 type ShopsRequest struct {
 	UserId   string `prop:"user_id" validate:"uuid"`
 	Page     int    `query:"page" validate:"required"`
-	Paginate int    `query:"paginate" validate:"required"`
+	Paginate int    `query:"paginate" validate:"required,max=100"`
+	Cursor   string `query:"cursor"`
 }
 
 func (r *ShopsRequest) SetDefault() {
@@ -71,60 +78,138 @@ type ShopsResponse struct {
 type ProductsResponse struct {
 	Product         []ProductResponseDashboard `json:"product"`
 	Meta            types.Meta                 `json:"meta"`
+	Facets          ProductFacets              `json:"facets"`
+	Q               string                     `json:"q" db:"q"`
+	Sort            string                     `json:"sort" db:"sort"`
 	KategoriFilter  string                     `json:"kategorifilter" db:"kategori"`
 	Name            string                     `json:"namefilter" db:"name"`
-	MinHarga        int                        `json:"min_harga" db:"harga"`
-	MaxHarga        int                        `json:"max_harga" db:"harga"`
+	MinHarga        int64                      `json:"min_harga" db:"harga"`
+	MaxHarga        int64                      `json:"max_harga" db:"harga"`
 	MerekFilter     string                     `json:"merekfilter" db:"merek"`
 	PenilaianFilter int                        `json:"rating" db:"rating"`
 	Page            int                        `json:"page" db:"page"`
 	Pagination      int                        `json:"pagination" db:"pagination"`
 }
 
-type KategoriRequest struct {
-	ProductID string `json:"product_id"`
-	Name      string `json:"name" db:"name" `
+// ProductCategory is a node of the global category taxonomy linked to a product.
+type ProductCategory struct {
+	ID   string `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+	Slug string `json:"slug" db:"slug"`
 }
 
 type CreateProductRequest struct {
-	UserID      string            `validate:"uuid" db:"user_id"`
-	ShopID      string            `db:"shop_id" json:"shop_id"`
-	Name        string            `validate:"required" json:"name" db:"name"`
-	Description string            `json:"description" validate:"required" db:"description"`
-	Kategori    []KategoriRequest `validate:"required" json:"kategori"`
-	Harga       int               `validate:"required" json:"harga" db:"harga"`
-	Stok        int               `validate:"required" json:"stok" db:"stok"`
-	Merek       string            `validate:"required" json:"merek" db:"merek"`
+	UserID      string      `validate:"uuid" db:"user_id"`
+	ShopID      string      `db:"shop_id" json:"shop_id"`
+	Name        string      `validate:"required" json:"name" db:"name"`
+	Description string      `json:"description" validate:"required" db:"description"`
+	Kategori    []string    `validate:"required,min=1,unique_in_slice,dive,uuid" json:"kategori"`
+	Harga       types.Money `validate:"required_without=Variants" json:"harga" db:"harga"`
+	Stok        int         `validate:"required_without=Variants" json:"stok" db:"stok"`
+	Merek       string      `validate:"required" json:"merek" db:"merek"`
+
+	// LowStockThreshold raises a stock alert once stok drops below it, 0 turns it off.
+	LowStockThreshold int `validate:"min=0" json:"low_stock_threshold" db:"low_stock_threshold"`
+
+	// Status is published unless the product is saved as a draft, which PublishAt
+	// schedules to go live on its own.
+	Status    string     `validate:"omitempty,oneof=draft published" json:"status" db:"status"`
+	PublishAt *time.Time `json:"publish_at" db:"published_at"`
+
+	Options  []ProductOptionRequest  `validate:"omitempty,dive" json:"options"`
+	Variants []ProductVariantRequest `validate:"required_with=Options,omitempty,dive" json:"variants"`
 }
+
+// HasVariants reports whether the product is sold through SKUs instead of a single price and stock.
+func (r *CreateProductRequest) HasVariants() bool {
+	return len(r.Variants) > 0
+}
+
 type ProductResponse struct {
-	ID          string            `json:"id" db:"id" validate:"uuid"`
-	UserID      string            `validate:"uuid" db:"user_id" json:"user_id"`
-	ShopID      string            `validate:"uuid" db:"shop_name" json:"shop_name"`
-	Nama        string            `validate:"required" json:"name" db:"name"`
-	Description string            `validate:"required" json:"deskripsi" db:"deskripsi"`
-	Kategori    []KategoriRequest `validate:"required" json:"kategori" db:"kategori"`
-	Harga       int               `validate:"required" json:"harga" db:"harga"`
-	Stok        int               `validate:"required" json:"stok" db:"stok"`
-	Merek       string            `validate:"required" json:"merek" db:"merek"`
+	ID                string            `json:"id" db:"id" validate:"uuid"`
+	UserID            string            `validate:"uuid" db:"user_id" json:"user_id"`
+	ShopID            string            `validate:"uuid" db:"shop_name" json:"shop_name"`
+	Nama              string            `validate:"required" json:"name" db:"name"`
+	Description       string            `validate:"required" json:"deskripsi" db:"deskripsi"`
+	Kategori          []ProductCategory `validate:"required" json:"kategori" db:"-"`
+	Harga             types.Money       `validate:"required" json:"harga" db:"harga"`
+	HargaEfektif      types.Money       `json:"harga_efektif" db:"-"`
+	FlashSale         *ProductFlashSale `json:"flash_sale" db:"-"`
+	Stok              int               `validate:"required" json:"stok" db:"stok"`
+	Merek             string            `validate:"required" json:"merek" db:"merek"`
+	MinHarga          types.Money       `json:"min_harga" db:"min_harga"`
+	MaxHarga          types.Money       `json:"max_harga" db:"max_harga"`
+	LowStockThreshold int               `json:"low_stock_threshold" db:"low_stock_threshold"`
+	Options           []ProductOption   `json:"options"`
+	Variants          []ProductVariant  `json:"variants"`
+	Images            []ProductImage    `json:"images"`
+	Rating            RatingSummary     `json:"rating"`
+	Terjual           int               `json:"terjual"`
+
+	// Available is false while the shop is not open, see ShopStatus.
+	Available bool `json:"available" db:"available"`
+
+	Status      string     `json:"status" db:"status"`
+	PublishedAt *time.Time `json:"published_at" db:"published_at"`
 }
 type ProductResponseDashboard struct {
-	ID        string `json:"id" db:"id" validate:"uuid"`
-	UserID    string `validate:"uuid" db:"user_id" json:"user_id"`
-	ShopID    string `validate:"uuid" db:"shop_name" json:"shop_name"`
-	Nama      string `validate:"required" json:"name" db:"name"`
-	Kategori  string `validate:"required" json:"kategori" db:"kategori"`
-	Harga     int    `validate:"required" json:"harga" db:"harga"`
-	Stok      int    `validate:"required" json:"stok" db:"stok"`
-	Penilaian int    `validate:"required" json:"penilaian" db:"penilaian"`
-	Merek     string `validate:"required" json:"merek" db:"merek"`
+	ID        string            `json:"id" db:"id" validate:"uuid"`
+	UserID    string            `validate:"uuid" db:"user_id" json:"user_id"`
+	ShopID    string            `validate:"uuid" db:"shop_name" json:"shop_name"`
+	Nama      string            `validate:"required" json:"name" db:"name"`
+	Kategori  []ProductCategory `validate:"required" json:"kategori" db:"-"`
+	Harga     types.Money       `validate:"required" json:"harga" db:"harga"`
+	Stok      int               `validate:"required" json:"stok" db:"stok"`
+	Penilaian float64           `json:"penilaian" db:"penilaian"`
+	Ulasan    int               `json:"review_count" db:"review_count"`
+	Terjual   int               `json:"terjual" db:"terjual"`
+	Merek     string            `validate:"required" json:"merek" db:"merek"`
+	MinHarga  types.Money       `json:"min_harga" db:"min_harga"`
+	MaxHarga  types.Money       `json:"max_harga" db:"max_harga"`
+
+	// Rank and Snippet are only filled when the listing is a full-text search.
+	Rank    float64 `json:"rank,omitempty" db:"rank"`
+	Snippet string  `json:"snippet,omitempty" db:"snippet"`
+
+	// HargaEfektif is the price the product sells for now, see EffectivePrice.
+	HargaEfektif types.Money       `json:"harga_efektif" db:"-"`
+	FlashSale    *ProductFlashSale `json:"flash_sale" db:"-"`
+
+	// Available is false while the shop is not open, see ShopStatus.
+	Available bool `json:"available" db:"available"`
+
+	Variants []ProductVariant `json:"variants" db:"-"`
+	Images   []ProductImage   `json:"images" db:"-"`
 }
 type ProductResponseDetail struct {
 	ID          string            `json:"id" db:"id" validate:"uuid"`
 	Nama        string            `validate:"required" json:"nama" db:"nama"`
 	Description string            `validate:"required" json:"deskripsi" db:"deskripsi"`
-	Kategori    []KategoriRequest `validate:"required" json:"kategori" db:"kategori"`
-	Harga       int               `validate:"required" json:"harga" db:"harga"`
+	Kategori    []ProductCategory `validate:"required" json:"kategori" db:"-"`
+	Harga       types.Money       `validate:"required" json:"harga" db:"harga"`
 	Stok        int               `validate:"required" json:"stok" db:"stok"`
+	Terjual     int               `json:"terjual" db:"terjual"`
+	Images      []ProductImage    `json:"images" db:"-"`
+
+	HargaEfektif types.Money       `json:"harga_efektif" db:"-"`
+	FlashSale    *ProductFlashSale `json:"flash_sale" db:"-"`
+}
+
+type DetailShopRequest struct {
+	Id       string `params:"id" validate:"uuid"`
+	Page     int    `query:"page" validate:"required"`
+	Paginate int    `query:"paginate" validate:"required,max=100"`
+	Cursor   string `query:"cursor"`
+}
+
+func (r *DetailShopRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 4
+	}
 }
 
 type DetailShopAndProduct struct {
@@ -133,37 +218,94 @@ type DetailShopAndProduct struct {
 	Description   string                  `json:"description"`
 	Terms         string                  `json:"terms"`
 	Terjual       int                     `json:"terjual"`
+	FollowerCount int64                   `json:"follower_count"`
 	DaftarProduct []ProductResponseDetail `json:"daftar_products"`
 	Meta          types.Meta              `json:"meta"`
+
+	ShopStatus
 }
 
+// ProductFilter bounds harga with MinHarga and MaxHarga in minor units and keeps
+// products rated at least Penilaian stars.
 type ProductFilter struct {
-	Kategori   string `json:"kategori" db:"kategori"`
-	Name       string `json:"name" db:"name"`
-	MinHarga   int    `json:"min_harga" db:"harga"`
-	MaxHarga   int    `json:"max_harga" db:"harga"`
-	Merek      string `json:"merek" db:"merek"`
-	Penilaian  int    `json:"rating" db:"rating"`
-	Page       int    `json:"page" db:"page"`
-	Pagination int    `json:"pagination" db:"pagination"`
+	Q          string `json:"q" query:"q" db:"q"`
+	Kategori   string `json:"kategori" query:"kategori" db:"kategori"`
+	Name       string `json:"name" query:"name" db:"name"`
+	MinHarga   int64  `json:"min_harga" query:"min_harga" db:"harga"`
+	MaxHarga   int64  `json:"max_harga" query:"max_harga" db:"harga"`
+	Merek      string `json:"merek" query:"merek" db:"merek"`
+	Penilaian  int    `json:"rating" query:"rating" db:"rating" validate:"omitempty,min=1,max=5"`
+	Sort       string `json:"sort" query:"sort" validate:"omitempty,oneof=price_asc price_desc newest rating stock best_match best_selling"`
+	Page       int    `json:"page" query:"page" db:"page"`
+	Pagination int    `json:"pagination" query:"pagination" db:"pagination" validate:"max=100"`
+
+	// Cursor switches the listing to keyset pagination; Page is ignored when it is set.
+	Cursor string `json:"cursor" query:"cursor"`
 }
 
+const (
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+	SortNewest    = "newest"
+	SortRating    = "rating"
+	SortStock     = "stock"
+	SortBestMatch = "best_match"
+
+	// SortBestSelling orders by units sold, most first.
+	SortBestSelling = "best_selling"
+)
+
 func (p *ProductFilter) SetDefaultFilter() {
 	if p.MaxHarga < 1 {
 		p.MaxHarga = 99999999999999
 	}
+
+	if p.Page < 1 {
+		p.Page = 1
+	}
+
+	if p.Pagination < 1 {
+		p.Pagination = 10
+	}
+
+	// best match only means something for a keyword search
+	if p.Sort == "" || (p.Sort == SortBestMatch && p.Q == "") {
+		p.Sort = SortNewest
+		if p.Q != "" {
+			p.Sort = SortBestMatch
+		}
+	}
 }
 
 type UpdateProductRequest struct {
-	ID          string            `prop:"id" db:"id"`
-	UserID      string            `json:"user_id" db:"user_id"`
-	ShopID      string            `json:"shop_id" db:"shop_id"`
-	Name        string            `json:"name" db:"name"`
-	Description string            `json:"description" db:"description"`
-	Kategori    []KategoriRequest `json:"kategori"`
-	Harga       int               `json:"harga" db:"harga"`
-	Stok        int               `json:"stok" db:"stok"`
-	Merek       string            `json:"merek" db:"merek"`
+	ID          string      `prop:"id" db:"id"`
+	UserID      string      `json:"user_id" db:"user_id"`
+	ShopID      string      `json:"shop_id" db:"shop_id"`
+	Name        string      `json:"name" db:"name"`
+	Description string      `json:"description" db:"description"`
+	Kategori    []string    `json:"kategori" validate:"omitempty,unique_in_slice,dive,uuid"`
+	Harga       types.Money `json:"harga" db:"harga"`
+	Stok        int         `json:"stok" db:"stok"`
+	Merek       string      `json:"merek" db:"merek"`
+
+	// LowStockThreshold keeps the current threshold when nil.
+	LowStockThreshold *int `json:"low_stock_threshold" db:"low_stock_threshold" validate:"omitempty,min=0"`
+
+	// Options and Variants replace the product's SKUs when present; a nil
+	// Variants keeps the existing ones untouched and an empty list removes them.
+	Options  []ProductOptionRequest  `json:"options" validate:"omitempty,dive"`
+	Variants []ProductVariantRequest `json:"variants" validate:"omitempty,dive"`
+
+	// Revision is the revision the update was stored as; RevertedFrom is set by a
+	// rollback to the revision it restores, and Status to the status it had, empty
+	// keeping the current one.
+	Revision     int    `json:"revision" db:"-"`
+	RevertedFrom *int   `json:"-" db:"-"`
+	Status       string `json:"-" db:"-"`
+}
+
+func (r *UpdateProductRequest) HasVariants() bool {
+	return len(r.Variants) > 0
 }
 
 //nama, deskripsi, kategori, harga, dan stok.
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+// ExportProductsRequest exports the catalog of a shop, narrowed down and ordered by
+// Filter the same way as the product listing. Paging fields of Filter are ignored.
+type ExportProductsRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	ShopID string `params:"id" validate:"uuid"`
+	Format string `query:"format" validate:"oneof=csv jsonl"`
+	Filter ProductFilter
+}
+
+func (r *ExportProductsRequest) SetDefault() {
+	if r.Format == "" {
+		r.Format = ImportFormatCSV
+	}
+
+	r.Filter.SetDefaultFilter()
+}
+
+// ExportProductRow is one product of an export. It carries the columns of an import
+// file, so an export can be edited and imported back, plus a few read-only ones.
+type ExportProductRow struct {
+	ID                string      `json:"id" db:"id"`
+	Name              string      `json:"name" db:"name"`
+	Description       string      `json:"description" db:"description"`
+	Kategori          []string    `json:"kategori" db:"-"`
+	KategoriNama      []string    `json:"kategori_nama" db:"-"`
+	Harga             types.Money `json:"harga" db:"harga"`
+	Stok              int         `json:"stok" db:"stok"`
+	Merek             string      `json:"merek" db:"merek"`
+	LowStockThreshold int         `json:"low_stock_threshold" db:"low_stock_threshold"`
+	CreatedAt         time.Time   `json:"created_at" db:"created_at"`
+}
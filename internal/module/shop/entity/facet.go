@@ -0,0 +1,21 @@
+package entity
+
+type FacetCount struct {
+	Value string `json:"value" db:"value"`
+	Label string `json:"label" db:"label"`
+	Count int    `json:"count" db:"count"`
+}
+
+// PriceBucket counts products priced in [Min, Max); Max is nil for the open-ended last bucket.
+type PriceBucket struct {
+	Min   int64  `json:"min"`
+	Max   *int64 `json:"max"`
+	Count int    `json:"count"`
+}
+
+type ProductFacets struct {
+	Kategori []FacetCount  `json:"kategori"`
+	Merek    []FacetCount  `json:"merek"`
+	Rating   []FacetCount  `json:"rating"`
+	Harga    []PriceBucket `json:"harga"`
+}
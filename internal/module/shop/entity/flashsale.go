@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+// ProductFlashSale is the flash sale a product is in right now.
+type ProductFlashSale struct {
+	ID        string      `json:"id" db:"flash_sale_id"`
+	Name      string      `json:"name" db:"name"`
+	Harga     types.Money `json:"harga" db:"harga"`
+	Kuota     int         `json:"kuota" db:"quota"`
+	SisaKuota int         `json:"sisa_kuota" db:"remaining"`
+	EndsAt    time.Time   `json:"ends_at" db:"ends_at"`
+}
+
+// EffectivePrice is what a product sells for: the flash price while the sale has quota
+// left, harga otherwise.
+func EffectivePrice(harga types.Money, flash *ProductFlashSale) types.Money {
+	if flash != nil && flash.SisaKuota > 0 {
+		return flash.Harga
+	}
+	return harga
+}
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+// Kinds of shop events shown in the follow feed.
+const (
+	EventPublished = "published"
+	EventRestocked = "restocked"
+)
+
+type FollowShopRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	ShopID string `params:"id" validate:"uuid"`
+}
+
+type FollowShopResponse struct {
+	ShopID        string `json:"shop_id" db:"id"`
+	Following     bool   `json:"following" db:"following"`
+	FollowerCount int64  `json:"follower_count" db:"follower_count"`
+}
+
+// FeedRequest reads the events of the shops a user follows, newest first.
+type FeedRequest struct {
+	UserID   string `prop:"user_id" validate:"uuid"`
+	Paginate int    `query:"paginate" validate:"required,max=100"`
+	Cursor   string `query:"cursor"`
+}
+
+func (r *FeedRequest) SetDefault() {
+	if r.Paginate < 1 {
+		r.Paginate = 20
+	}
+}
+
+// FeedEvent is a product of a followed shop that was published or came back in stock.
+// Stok is the stock at that moment, Harga the product price as it is now.
+type FeedEvent struct {
+	ID          string      `json:"id" db:"id"`
+	Kind        string      `json:"kind" db:"kind"`
+	ShopID      string      `json:"shop_id" db:"shop_id"`
+	ShopName    string      `json:"shop_name" db:"shop_name"`
+	ProductID   string      `json:"product_id" db:"product_id"`
+	ProductName string      `json:"product_name" db:"product_name"`
+	Harga       types.Money `json:"harga" db:"harga"`
+	Stok        int         `json:"stok" db:"stok"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+}
+
+type FeedResponse struct {
+	Items []FeedEvent `json:"items"`
+	Meta  types.Meta  `json:"meta"`
+}
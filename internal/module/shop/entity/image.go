@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+)
+
+// Statuses of the background job producing the resized variants of an image.
+const (
+	ImageVariantsPending    = "pending"
+	ImageVariantsProcessing = "processing"
+	ImageVariantsReady      = "ready"
+	ImageVariantsFailed     = "failed"
+)
+
+// ProductImage is one picture of a product's gallery; galleries are ordered by
+// Position and have exactly one primary image as long as they are not empty.
+// Variants stays empty until VariantsStatus is ready.
+type ProductImage struct {
+	ID             string        `json:"id" db:"id"`
+	URL            string        `json:"url" db:"url"`
+	Position       int           `json:"position" db:"position"`
+	IsPrimary      bool          `json:"is_primary" db:"is_primary"`
+	Variants       ImageVariants `json:"variants" db:"variants"`
+	VariantsStatus string        `json:"variants_status" db:"variants_status"`
+	StorageKey     string        `json:"-" db:"storage_key"`
+	Attempts       int           `json:"-" db:"variants_attempts"`
+}
+
+// ImageVariant is a resized copy of an image whose longest side is Size pixels.
+type ImageVariant struct {
+	Size   int    `json:"size"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Format string `json:"format"`
+	URL    string `json:"url"`
+}
+
+// ImageVariants is stored as jsonb in product_images.variants.
+type ImageVariants []ImageVariant
+
+// Scan implements the sql.Scanner interface.
+func (v *ImageVariants) Scan(val any) error {
+	switch b := val.(type) {
+	case nil:
+		*v = ImageVariants{}
+		return nil
+	case []byte:
+		return json.Unmarshal(b, v)
+	case string:
+		return json.Unmarshal([]byte(b), v)
+	default:
+		return errors.New("entity: unsupported type for ImageVariants")
+	}
+}
+
+// Value implements the driver.Valuer interface.
+func (v ImageVariants) Value() (driver.Value, error) {
+	if v == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(v)
+}
+
+type UploadProductImageRequest struct {
+	UserID    string                `prop:"user_id" validate:"uuid"`
+	ProductID string                `params:"id" validate:"uuid"`
+	Image     *multipart.FileHeader `form:"image" validate:"required"`
+}
+
+// ReorderProductImagesRequest lists every image of the product in the new order.
+// PrimaryID, when set, also moves the primary flag.
+type ReorderProductImagesRequest struct {
+	UserID    string   `prop:"user_id" validate:"uuid"`
+	ProductID string   `params:"id" validate:"uuid"`
+	ImageIDs  []string `json:"image_ids" validate:"required,min=1,unique_in_slice,dive,uuid"`
+	PrimaryID string   `json:"primary_id" validate:"omitempty,uuid"`
+}
+
+type DeleteProductImageRequest struct {
+	UserID    string `prop:"user_id" validate:"uuid"`
+	ProductID string `params:"id" validate:"uuid"`
+	ImageID   string `params:"image_id" validate:"uuid"`
+}
@@ -0,0 +1,141 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Formats accepted by the product import.
+const (
+	ImportFormatCSV   = "csv"
+	ImportFormatJSONL = "jsonl"
+)
+
+// ImportFormatOf returns the import format of a file by its extension, empty when it
+// is not one of them.
+func ImportFormatOf(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return ImportFormatCSV
+	case ".jsonl", ".ndjson":
+		return ImportFormatJSONL
+	default:
+		return ""
+	}
+}
+
+// Statuses of a product import job.
+const (
+	ImportPending    = "pending"
+	ImportProcessing = "processing"
+	ImportDone       = "done"
+	ImportFailed     = "failed"
+)
+
+// Outcomes of a single import row. A dry run reports what would have happened.
+const (
+	ImportRowCreated = "created"
+	ImportRowUpdated = "updated"
+	ImportRowFailed  = "failed"
+)
+
+// ImportProductRow is one product of an import file. Rows with an ID update that
+// product, the others create a new one. Products with variants cannot be imported.
+//
+// CSV files carry the same fields as columns, with kategori ids separated by "|"
+// and harga in minor units next to an optional currency column.
+type ImportProductRow struct {
+	ID                string      `json:"id" validate:"omitempty,uuid"`
+	Name              string      `json:"name"`
+	Description       string      `json:"description"`
+	Kategori          []string    `json:"kategori"`
+	Harga             types.Money `json:"harga"`
+	Stok              int         `json:"stok"`
+	Merek             string      `json:"merek"`
+	LowStockThreshold *int        `json:"low_stock_threshold"`
+}
+
+type CreateProductImportRequest struct {
+	UserID string                `prop:"user_id" validate:"uuid"`
+	ShopID string                `params:"id" validate:"uuid"`
+	File   *multipart.FileHeader `form:"file" validate:"required"`
+	DryRun bool                  `form:"dry_run"`
+
+	// Format is taken from the file extension when left empty.
+	Format string `form:"format" validate:"omitempty,oneof=csv jsonl"`
+}
+
+type ProductImportRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	Id     string `params:"id" validate:"uuid"`
+}
+
+// ProductImport is a background job importing the products of an uploaded file.
+type ProductImport struct {
+	ID         string           `json:"id" db:"id"`
+	UserID     string           `json:"user_id" db:"user_id"`
+	ShopID     string           `json:"shop_id" db:"shop_id"`
+	Format     string           `json:"format" db:"format"`
+	DryRun     bool             `json:"dry_run" db:"dry_run"`
+	Status     string           `json:"status" db:"status"`
+	TotalRows  int              `json:"total_rows" db:"total_rows"`
+	Created    int              `json:"created_rows" db:"created_rows"`
+	Updated    int              `json:"updated_rows" db:"updated_rows"`
+	Failed     int              `json:"failed_rows" db:"failed_rows"`
+	Report     ImportRowResults `json:"report" db:"report"`
+	Error      *string          `json:"error" db:"error"`
+	CreatedAt  time.Time        `json:"created_at" db:"created_at"`
+	FinishedAt *time.Time       `json:"finished_at" db:"finished_at"`
+	Source     []byte           `json:"-" db:"source"`
+}
+
+// ImportRowResult is the outcome of one row; Row is its line in the file.
+type ImportRowResult struct {
+	Row       int                 `json:"row"`
+	Status    string              `json:"status"`
+	ProductID string              `json:"product_id,omitempty"`
+	Errors    map[string][]string `json:"errors,omitempty"`
+}
+
+// ImportRowResults is stored as jsonb in product_imports.report.
+type ImportRowResults []ImportRowResult
+
+// Count returns how many rows ended with status.
+func (r ImportRowResults) Count(status string) int {
+	var n int
+	for _, row := range r {
+		if row.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Scan implements the sql.Scanner interface.
+func (r *ImportRowResults) Scan(val any) error {
+	switch b := val.(type) {
+	case nil:
+		*r = ImportRowResults{}
+		return nil
+	case []byte:
+		return json.Unmarshal(b, r)
+	case string:
+		return json.Unmarshal([]byte(b), r)
+	default:
+		return errors.New("entity: unsupported type for ImportRowResults")
+	}
+}
+
+// Value implements the driver.Valuer interface.
+func (r ImportRowResults) Value() (driver.Value, error) {
+	if r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r)
+}
@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+// Reasons a product's stock changed.
+const (
+	MovementRestock    = "restock"
+	MovementSale       = "sale"
+	MovementAdjustment = "adjustment"
+	MovementReturn     = "return"
+)
+
+// StockMovement is one entry of the stock ledger. Balance is the stock of the product,
+// or of the SKU when VariantID is set, right after the change.
+type StockMovement struct {
+	ID        string    `json:"id" db:"id"`
+	VariantID *string   `json:"variant_id" db:"variant_id"`
+	Delta     int       `json:"delta" db:"delta"`
+	Balance   int       `json:"balance" db:"balance"`
+	Reason    string    `json:"reason" db:"reason"`
+	Actor     string    `json:"actor" db:"actor"`
+	Reference *string   `json:"reference" db:"reference"`
+	Note      *string   `json:"note" db:"note"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// MovementSource describes who changed stock and why, it is written to every ledger
+// entry produced by the change.
+type MovementSource struct {
+	Reason    string
+	Actor     string
+	Reference string
+	Note      string
+}
+
+// CreateStockMovementRequest lets a seller record stock coming in or going out by hand.
+type CreateStockMovementRequest struct {
+	UserID    string `prop:"user_id" validate:"uuid"`
+	ProductID string `params:"id" validate:"uuid"`
+	VariantID string `json:"variant_id" validate:"omitempty,uuid"`
+	Delta     int    `json:"delta" validate:"required,ne=0"`
+	Reason    string `json:"reason" validate:"required,oneof=restock adjustment return"`
+	Note      string `json:"note" validate:"max=255"`
+}
+
+type StockMovementsRequest struct {
+	UserID    string `prop:"user_id" validate:"uuid"`
+	ProductID string `params:"id" validate:"uuid"`
+	VariantID string `query:"variant_id" validate:"omitempty,uuid"`
+	Reason    string `query:"reason" validate:"omitempty,oneof=restock sale adjustment return"`
+	Page      int    `query:"page" validate:"required"`
+	Paginate  int    `query:"paginate" validate:"required,max=100"`
+}
+
+func (r *StockMovementsRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 20
+	}
+}
+
+type StockMovementsResponse struct {
+	Stok  int             `json:"stok"`
+	Items []StockMovement `json:"items"`
+	Meta  types.Meta      `json:"meta"`
+}
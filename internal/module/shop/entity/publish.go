@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+// Statuses of a product. Only published products are shown to buyers and can be sold;
+// a draft with a publish time is scheduled and goes live on its own.
+const (
+	ProductDraft     = "draft"
+	ProductPublished = "published"
+	ProductArchived  = "archived"
+)
+
+// UpdateProductStatusRequest publishes a product now, turns it back into a draft,
+// optionally scheduled at PublishAt, or archives it.
+type UpdateProductStatusRequest struct {
+	UserID    string     `prop:"user_id" validate:"uuid"`
+	ProductID string     `params:"id" validate:"uuid"`
+	Status    string     `json:"status" validate:"required,oneof=draft published archived"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+type ProductStatusResponse struct {
+	ProductID   string     `json:"product_id" db:"id"`
+	Status      string     `json:"status" db:"status"`
+	PublishedAt *time.Time `json:"published_at" db:"published_at"`
+}
+
+type PreviewProductRequest struct {
+	UserID    string `prop:"user_id" validate:"uuid"`
+	ProductID string `params:"id" validate:"uuid"`
+}
+
+// ShopProductsRequest lists every product of a shop for its owner, whatever its status.
+type ShopProductsRequest struct {
+	UserID   string `prop:"user_id" validate:"uuid"`
+	ShopID   string `params:"id" validate:"uuid"`
+	Status   string `query:"status" validate:"omitempty,oneof=draft published archived"`
+	Page     int    `query:"page" validate:"required"`
+	Paginate int    `query:"paginate" validate:"required,max=100"`
+}
+
+func (r *ShopProductsRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type ShopProduct struct {
+	ID          string      `json:"id" db:"id"`
+	Nama        string      `json:"name" db:"name"`
+	Harga       types.Money `json:"harga" db:"harga"`
+	Stok        int         `json:"stok" db:"stok"`
+	Status      string      `json:"status" db:"status"`
+	PublishedAt *time.Time  `json:"published_at" db:"published_at"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+type ShopProductsResponse struct {
+	Items []ShopProduct `json:"items"`
+	Meta  types.Meta    `json:"meta"`
+}
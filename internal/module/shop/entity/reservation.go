@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+const (
+	ReservationActive    = "active"
+	ReservationConfirmed = "confirmed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+	ReservationReturned  = "returned"
+)
+
+// MaxUserHolds is how many live holds one buyer may have across all products. A hold a
+// buyer makes directly is never sold, only an order's is, so it just keeps stock off sale
+// while they decide; the limits here and on ReserveStockRequest keep one buyer from
+// taking a product off sale.
+const MaxUserHolds = 5
+
+// ReserveStockRequest holds Quantity units of a product, or of one of its SKUs, for
+// Holder during TTL seconds. Reserving again for the same holder replaces the quantity
+// of the previous hold but not its expiry, so a hold cannot be kept alive by renewing
+// it. Holder is never taken from the request: buyers hold under UserHolder and orders
+// under their number. MaxHolds, when set, is how many live holds Holder may have.
+type ReserveStockRequest struct {
+	UserID    string `prop:"user_id" validate:"uuid"`
+	ProductID string `params:"id" validate:"uuid"`
+	VariantID string `json:"variant_id" validate:"omitempty,uuid"`
+	Holder    string `json:"-"`
+	MaxHolds  int    `json:"-"`
+	Quantity  int    `json:"quantity" validate:"required,min=1,max=5"`
+	TTL       int    `json:"ttl" validate:"omitempty,min=60,max=900"`
+}
+
+func (r *ReserveStockRequest) SetDefault() {
+	if r.TTL == 0 {
+		r.TTL = 900
+	}
+}
+
+// ReservationRequest releases a reservation; only its holder may do so.
+type ReservationRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	Id     string `params:"id" validate:"uuid"`
+	Holder string `json:"-"`
+}
+
+// UserHolder is the holder of the reservations a buyer makes directly. It can never be
+// an order number, so a buyer cannot add to or take the holds of an order.
+func UserHolder(userID string) string {
+	return "USR-" + userID
+}
+
+type StockReservation struct {
+	ID        string    `json:"id" db:"id"`
+	ProductID string    `json:"product_id" db:"product_id"`
+	VariantID *string   `json:"variant_id" db:"variant_id"`
+	Holder    string    `json:"holder" db:"holder"`
+	Quantity  int       `json:"quantity" db:"quantity"`
+	Status    string    `json:"status" db:"status"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
@@ -0,0 +1,124 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"time"
+)
+
+// ProductReview is a buyer's rating of a product. Hidden reviews are left out of the
+// listing and of the product's rating.
+type ProductReview struct {
+	ID        string       `json:"id" db:"id"`
+	ProductID string       `json:"product_id" db:"product_id"`
+	UserID    string       `json:"user_id" db:"user_id"`
+	Rating    int          `json:"rating" db:"rating"`
+	Body      string       `json:"body" db:"body"`
+	Photos    ReviewPhotos `json:"photos" db:"photos"`
+	Reply     *string      `json:"reply" db:"reply"`
+	RepliedAt *time.Time   `json:"replied_at" db:"replied_at"`
+	HiddenAt  *time.Time   `json:"hidden_at,omitempty" db:"hidden_at"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+}
+
+type ReviewPhoto struct {
+	URL        string `json:"url"`
+	StorageKey string `json:"-"`
+}
+
+// ReviewPhotos is stored as jsonb in product_reviews.photos, storage keys included.
+type ReviewPhotos []ReviewPhoto
+
+// Scan implements the sql.Scanner interface.
+func (p *ReviewPhotos) Scan(val any) error {
+	var b []byte
+
+	switch v := val.(type) {
+	case nil:
+		*p = ReviewPhotos{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return errors.New("entity: unsupported type for ReviewPhotos")
+	}
+
+	var raw []struct {
+		URL        string `json:"url"`
+		StorageKey string `json:"storage_key"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*p = make(ReviewPhotos, 0, len(raw))
+	for _, r := range raw {
+		*p = append(*p, ReviewPhoto{URL: r.URL, StorageKey: r.StorageKey})
+	}
+
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (p ReviewPhotos) Value() (driver.Value, error) {
+	raw := make([]map[string]string, 0, len(p))
+	for _, photo := range p {
+		raw = append(raw, map[string]string{"url": photo.URL, "storage_key": photo.StorageKey})
+	}
+	return json.Marshal(raw)
+}
+
+type CreateReviewRequest struct {
+	UserID    string                  `prop:"user_id" validate:"uuid"`
+	ProductID string                  `params:"id" validate:"uuid"`
+	Rating    int                     `form:"rating" json:"rating" validate:"required,min=1,max=5"`
+	Body      string                  `form:"body" json:"body" validate:"required,max=2000"`
+	Photos    []*multipart.FileHeader `form:"-" json:"-" validate:"max=3"`
+}
+
+type ReviewsRequest struct {
+	ProductID string `params:"id" validate:"uuid"`
+	Rating    int    `query:"rating" validate:"omitempty,min=1,max=5"`
+	Page      int    `query:"page" validate:"required"`
+	Paginate  int    `query:"paginate" validate:"required,max=100"`
+}
+
+func (r *ReviewsRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type ReviewsResponse struct {
+	Items []ProductReview `json:"items"`
+	Meta  types.Meta      `json:"meta"`
+}
+
+type ReplyReviewRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	Id     string `params:"id" validate:"uuid"`
+	Reply  string `json:"reply" validate:"required,max=2000"`
+}
+
+type HideReviewRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	Id     string `params:"id" validate:"uuid"`
+	Hidden bool   `json:"hidden"`
+}
+
+// RatingSummary is the maintained rating of a product: the average of its visible
+// reviews and how many of them gave each star, from 1 to 5.
+type RatingSummary struct {
+	Average      float64     `json:"average"`
+	Count        int         `json:"count"`
+	Distribution map[int]int `json:"distribution"`
+}
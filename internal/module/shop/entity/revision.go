@@ -0,0 +1,208 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+)
+
+// ProductSnapshot is a product as it was right after a change. It holds everything
+// UpdateProductByID writes, and the status, and is stored as jsonb in
+// product_revisions.snapshot. Status is empty in revisions stored before it was kept.
+type ProductSnapshot struct {
+	Status            string                  `json:"status"`
+	Name              string                  `json:"name"`
+	Description       string                  `json:"description"`
+	Harga             types.Money             `json:"harga"`
+	Stok              int                     `json:"stok"`
+	Merek             string                  `json:"merek"`
+	LowStockThreshold int                     `json:"low_stock_threshold"`
+	Kategori          []string                `json:"kategori"`
+	Options           []ProductOptionRequest  `json:"options"`
+	Variants          []ProductVariantRequest `json:"variants"`
+}
+
+// Scan implements the sql.Scanner interface.
+func (s *ProductSnapshot) Scan(val any) error {
+	switch v := val.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	default:
+		return errors.New("entity: unsupported type for ProductSnapshot")
+	}
+}
+
+// Value implements the driver.Valuer interface.
+func (s ProductSnapshot) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// ProductRevision is one numbered version of a product. Revisions are never changed;
+// RevertedFrom is set when the revision was made by rolling back to an earlier one.
+type ProductRevision struct {
+	ProductID    string          `json:"product_id" db:"product_id"`
+	Revision     int             `json:"revision" db:"revision"`
+	Actor        *string         `json:"actor" db:"actor"`
+	RevertedFrom *int            `json:"reverted_from" db:"reverted_from"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+	Snapshot     ProductSnapshot `json:"snapshot" db:"snapshot"`
+}
+
+type ProductRevisionsRequest struct {
+	UserID    string `prop:"user_id" validate:"uuid"`
+	ProductID string `params:"id" validate:"uuid"`
+	Page      int    `query:"page" validate:"required"`
+	Paginate  int    `query:"paginate" validate:"required,max=100"`
+}
+
+func (r *ProductRevisionsRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type ProductRevisionsResponse struct {
+	Items []ProductRevision `json:"items"`
+	Meta  types.Meta        `json:"meta"`
+}
+
+type ProductRevisionRequest struct {
+	UserID    string `prop:"user_id" validate:"uuid"`
+	ProductID string `params:"id" validate:"uuid"`
+	Revision  int    `params:"revision" validate:"min=1"`
+}
+
+// RevisionDiffRequest compares revision From of a product with revision To.
+type RevisionDiffRequest struct {
+	UserID    string `prop:"user_id" validate:"uuid"`
+	ProductID string `params:"id" validate:"uuid"`
+	From      int    `query:"from" validate:"required,min=1"`
+	To        int    `query:"to" validate:"required,min=1"`
+}
+
+// RevisionChange is a field that differs between two revisions. From is nil for
+// options and variants added since, To for those removed.
+type RevisionChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+type RevisionDiffResponse struct {
+	From    int              `json:"from"`
+	To      int              `json:"to"`
+	Changes []RevisionChange `json:"changes"`
+}
+
+// DiffSnapshots lists the fields that differ from a to b. Options are matched by name
+// and variants by code, so a variant is compared field by field, as
+// "variants.<code>.harga", unless it was added or removed as a whole.
+func DiffSnapshots(a, b ProductSnapshot) []RevisionChange {
+	var changes = make([]RevisionChange, 0)
+
+	add := func(field string, from, to any) {
+		changes = append(changes, RevisionChange{Field: field, From: from, To: to})
+	}
+
+	if a.Status != b.Status && a.Status != "" && b.Status != "" {
+		add("status", a.Status, b.Status)
+	}
+	if a.Name != b.Name {
+		add("name", a.Name, b.Name)
+	}
+	if a.Description != b.Description {
+		add("description", a.Description, b.Description)
+	}
+	if a.Harga.Amount != b.Harga.Amount || a.Harga.CurrencyCode() != b.Harga.CurrencyCode() {
+		add("harga", a.Harga, b.Harga)
+	}
+	if a.Stok != b.Stok {
+		add("stok", a.Stok, b.Stok)
+	}
+	if a.Merek != b.Merek {
+		add("merek", a.Merek, b.Merek)
+	}
+	if a.LowStockThreshold != b.LowStockThreshold {
+		add("low_stock_threshold", a.LowStockThreshold, b.LowStockThreshold)
+	}
+	if !sameSet(a.Kategori, b.Kategori) {
+		add("kategori", a.Kategori, b.Kategori)
+	}
+
+	for _, name := range unionKeys(a.Options, b.Options, func(o ProductOptionRequest) string { return o.Name }) {
+		from := findBy(a.Options, func(o ProductOptionRequest) bool { return o.Name == name })
+		to := findBy(b.Options, func(o ProductOptionRequest) bool { return o.Name == name })
+		switch {
+		case from == nil:
+			add("options."+name, nil, to.Values)
+		case to == nil:
+			add("options."+name, from.Values, nil)
+		case !slices.Equal(from.Values, to.Values):
+			add("options."+name, from.Values, to.Values)
+		}
+	}
+
+	for _, code := range unionKeys(a.Variants, b.Variants, func(v ProductVariantRequest) string { return v.Code }) {
+		from := findBy(a.Variants, func(v ProductVariantRequest) bool { return v.Code == code })
+		to := findBy(b.Variants, func(v ProductVariantRequest) bool { return v.Code == code })
+		field := "variants." + code
+		switch {
+		case from == nil:
+			add(field, nil, *to)
+		case to == nil:
+			add(field, *from, nil)
+		default:
+			if !maps.Equal(from.Options, to.Options) {
+				add(field+".options", from.Options, to.Options)
+			}
+			if from.Harga.Amount != to.Harga.Amount || from.Harga.CurrencyCode() != to.Harga.CurrencyCode() {
+				add(field+".harga", from.Harga, to.Harga)
+			}
+			if from.Stok != to.Stok {
+				add(field+".stok", from.Stok, to.Stok)
+			}
+		}
+	}
+
+	return changes
+}
+
+// sameSet tells whether a and b hold the same ids in any order.
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+// unionKeys returns the keys of a in order followed by the keys only found in b.
+func unionKeys[T any](a, b []T, key func(T) string) []string {
+	var keys []string
+
+	for _, list := range [][]T{a, b} {
+		for _, item := range list {
+			if k := key(item); !slices.Contains(keys, k) {
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	return keys
+}
+
+func findBy[T any](list []T, match func(T) bool) *T {
+	if i := slices.IndexFunc(list, match); i >= 0 {
+		return &list[i]
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func snapshot() ProductSnapshot {
+	return ProductSnapshot{
+		Status:            ProductPublished,
+		Name:              "Kaos",
+		Description:       "Kaos katun",
+		Harga:             types.NewMoney(50000, "IDR"),
+		Stok:              15,
+		Merek:             "Lokal",
+		LowStockThreshold: 5,
+		Kategori:          []string{"c1", "c2"},
+		Options:           []ProductOptionRequest{{Name: "size", Values: []string{"M", "L"}}},
+		Variants: []ProductVariantRequest{
+			{Code: "KAOS-M", Options: VariantOptions{"size": "M"}, Harga: types.NewMoney(50000, "IDR"), Stok: 10},
+			{Code: "KAOS-L", Options: VariantOptions{"size": "L"}, Harga: types.NewMoney(55000, "IDR"), Stok: 5},
+		},
+	}
+}
+
+func TestDiffSnapshotsSame(t *testing.T) {
+	a, b := snapshot(), snapshot()
+	b.Kategori = []string{"c2", "c1"} // category order does not matter
+
+	assert.Empty(t, DiffSnapshots(a, b))
+}
+
+func TestDiffSnapshotsFields(t *testing.T) {
+	a, b := snapshot(), snapshot()
+	b.Status = ProductArchived
+	b.Name = "Kaos Polos"
+	b.Harga = types.NewMoney(45000, "IDR")
+	b.Kategori = []string{"c1"}
+
+	assert.Equal(t, []RevisionChange{
+		{Field: "status", From: ProductPublished, To: ProductArchived},
+		{Field: "name", From: "Kaos", To: "Kaos Polos"},
+		{Field: "harga", From: a.Harga, To: b.Harga},
+		{Field: "kategori", From: a.Kategori, To: b.Kategori},
+	}, DiffSnapshots(a, b))
+}
+
+func TestDiffSnapshotsVariants(t *testing.T) {
+	a, b := snapshot(), snapshot()
+	b.Options = []ProductOptionRequest{{Name: "size", Values: []string{"M", "XL"}}}
+	b.Variants = []ProductVariantRequest{
+		{Code: "KAOS-M", Options: VariantOptions{"size": "M"}, Harga: types.NewMoney(48000, "IDR"), Stok: 10},
+		{Code: "KAOS-XL", Options: VariantOptions{"size": "XL"}, Harga: types.NewMoney(60000, "IDR"), Stok: 0},
+	}
+
+	assert.Equal(t, []RevisionChange{
+		{Field: "options.size", From: []string{"M", "L"}, To: []string{"M", "XL"}},
+		{Field: "variants.KAOS-M.harga", From: a.Variants[0].Harga, To: b.Variants[0].Harga},
+		{Field: "variants.KAOS-L", From: a.Variants[1], To: nil},
+		{Field: "variants.KAOS-XL", From: nil, To: b.Variants[1]},
+	}, DiffSnapshots(a, b))
+}
+
+func TestDiffSnapshotsUnknownStatus(t *testing.T) {
+	a, b := snapshot(), snapshot()
+	a.Status = "" // stored before revisions kept the status
+
+	assert.Empty(t, DiffSnapshots(a, b))
+}
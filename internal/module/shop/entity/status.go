@@ -0,0 +1,91 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Shop statuses. A vacation ends on its own at VacationUntil, a shop closed by an admin
+// stays closed until an admin opens it again.
+const (
+	ShopOpen     = "open"
+	ShopVacation = "vacation"
+	ShopClosed   = "closed"
+)
+
+// OpeningHour is an opening window on a week day, 0 being Sunday. Open and Close are
+// "HH:MM" times in the shop timezone; Close is excluded and may be "24:00".
+type OpeningHour struct {
+	Day   int    `json:"day" validate:"min=0,max=6"`
+	Open  string `json:"open" validate:"required"`
+	Close string `json:"close" validate:"required"`
+}
+
+// OpeningHours is the weekly schedule of a shop, stored as jsonb in shops.opening_hours.
+// A shop without any window is open all week.
+type OpeningHours []OpeningHour
+
+// Scan implements the sql.Scanner interface.
+func (h *OpeningHours) Scan(val any) error {
+	switch v := val.(type) {
+	case nil:
+		*h = OpeningHours{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, h)
+	case string:
+		return json.Unmarshal([]byte(v), h)
+	default:
+		return errors.New("entity: unsupported type for OpeningHours")
+	}
+}
+
+// Value implements the driver.Valuer interface.
+func (h OpeningHours) Value() (driver.Value, error) {
+	if h == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(h)
+}
+
+// ShopStatus tells whether a shop takes orders now. Status reads open again once a
+// vacation is over; IsOpen also accounts for the opening hours.
+type ShopStatus struct {
+	Status        string       `json:"status" db:"status"`
+	VacationUntil *time.Time   `json:"vacation_until" db:"vacation_until"`
+	ClosedReason  *string      `json:"closed_reason,omitempty" db:"closed_reason"`
+	Timezone      string       `json:"timezone" db:"timezone"`
+	OpeningHours  OpeningHours `json:"opening_hours" db:"opening_hours"`
+	IsOpen        bool         `json:"is_open" db:"is_open"`
+}
+
+type UpdateShopStatusRequest struct {
+	UserID        string     `prop:"user_id" validate:"uuid"`
+	ShopID        string     `params:"id" validate:"uuid"`
+	Status        string     `json:"status" validate:"required,oneof=open vacation"`
+	VacationUntil *time.Time `json:"vacation_until"`
+}
+
+type UpdateOpeningHoursRequest struct {
+	UserID   string       `prop:"user_id" validate:"uuid"`
+	ShopID   string       `params:"id" validate:"uuid"`
+	Timezone string       `json:"timezone" validate:"required,oneof=Asia/Jakarta Asia/Makassar Asia/Jayapura"`
+	Hours    OpeningHours `json:"hours" validate:"max=50,dive"`
+}
+
+func (r *UpdateOpeningHoursRequest) SetDefault() {
+	if r.Timezone == "" {
+		r.Timezone = "Asia/Jakarta"
+	}
+}
+
+type CloseShopRequest struct {
+	ShopID string `params:"id" validate:"uuid"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type ReopenShopRequest struct {
+	ShopID string `params:"id" validate:"uuid"`
+}
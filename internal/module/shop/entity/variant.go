@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// VariantOptions maps an option axis (e.g. "size") to the chosen value (e.g. "XL").
+// It is stored as jsonb in product_variants.options.
+type VariantOptions map[string]string
+
+// Scan implements the sql.Scanner interface.
+func (o *VariantOptions) Scan(val any) error {
+	switch v := val.(type) {
+	case nil:
+		*o = VariantOptions{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, o)
+	case string:
+		return json.Unmarshal([]byte(v), o)
+	default:
+		return errors.New("entity: unsupported type for VariantOptions")
+	}
+}
+
+// Value implements the driver.Valuer interface.
+func (o VariantOptions) Value() (driver.Value, error) {
+	if o == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(o)
+}
+
+type ProductOptionRequest struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Values []string `json:"values" validate:"required,min=1,unique_in_slice,dive,required,max=100"`
+}
+
+type ProductVariantRequest struct {
+	Code    string         `json:"code" validate:"required,max=100"`
+	Options VariantOptions `json:"options" validate:"required"`
+	Harga   types.Money    `json:"harga" validate:"required,min=1"`
+	Stok    int            `json:"stok" validate:"min=0"`
+}
+
+type ProductOption struct {
+	ID     string   `json:"id" db:"id"`
+	Name   string   `json:"name" db:"name"`
+	Values []string `json:"values" db:"-"`
+}
+
+type ProductVariant struct {
+	ID      string         `json:"id" db:"id"`
+	Code    string         `json:"code" db:"code"`
+	Options VariantOptions `json:"options" db:"options"`
+	Harga   types.Money    `json:"harga" db:"harga"`
+	Stok    int            `json:"stok" db:"stok"`
+}
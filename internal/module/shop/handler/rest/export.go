@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"bufio"
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/middleware"
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/response"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// exportTimeout bounds how long a single catalog export may keep its query open.
+const exportTimeout = 10 * time.Minute
+
+var exportContentTypes = map[string]string{
+	entity.ImportFormatCSV:   "text/csv; charset=utf-8",
+	entity.ImportFormatJSONL: "application/x-ndjson",
+}
+
+func (h *shopHandler) ExportProducts(c *fiber.Ctx) error {
+	var (
+		req = new(entity.ExportProductsRequest)
+		v   = adapter.Adapters.Validator
+		l   = middleware.GetLocals(c)
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::ExportProducts - Parse request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if err := c.QueryParser(&req.Filter); err != nil {
+		log.Warn().Err(err).Msg("handler::ExportProducts - Parse request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.UserID = l.UserId
+	req.ShopID = c.Params("id")
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::ExportProducts - Validate request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	// the export outlives this handler, so it cannot run on the request context
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer cancel()
+		err := h.service.ExportProducts(ctx, req, pw)
+		if err != nil && !errors.Is(err, io.ErrClosedPipe) {
+			log.Warn().Err(err).Any("payload", req).Msg("handler::ExportProducts - Export stopped")
+		}
+		pw.CloseWithError(err)
+	}()
+
+	// until the first bytes arrive the export can still fail with a proper response
+	body := bufio.NewReader(pr)
+	if _, err := body.Peek(1); err != nil && !errors.Is(err, io.EOF) {
+		pr.Close()
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	c.Set(fiber.HeaderContentType, exportContentTypes[req.Format])
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="products-%s.%s"`, req.ShopID, req.Format))
+	c.Status(fiber.StatusOK).Response().SetBodyStream(&exportBody{Reader: body, pipe: pr}, -1)
+
+	return nil
+}
+
+// exportBody closes the pipe when the response is done or the client went away, which
+// makes the export stop writing.
+type exportBody struct {
+	*bufio.Reader
+	pipe *io.PipeReader
+}
+
+func (b *exportBody) Close() error {
+	return b.pipe.Close()
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"codebase-app/internal/adapter"
+	integration "codebase-app/internal/integration/filestorage"
 	"codebase-app/internal/middleware"
 	"codebase-app/internal/module/shop/entity"
 	"codebase-app/internal/module/shop/ports"
@@ -9,7 +10,7 @@ import (
 	"codebase-app/internal/module/shop/service"
 	"codebase-app/pkg/errmsg"
 	"codebase-app/pkg/response"
-	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/rs/zerolog/log"
@@ -23,7 +24,8 @@ func NewShopHandler() *shopHandler {
 	var (
 		handler = new(shopHandler)
 		repo    = repository.NewShopRepository(adapter.Adapters.ShopeefunPostgres)
-		service = service.NewShopService(repo)
+		storage = integration.NewFileStorageIntegration()
+		service = service.NewShopService(repo, storage)
 	)
 	handler.service = service
 
@@ -39,9 +41,39 @@ func (h *shopHandler) Register(router fiber.Router) {
 	router.Post("/product", middleware.UserIdHeader, h.CreateProduct)
 	router.Post("/detailshop/:id", h.GetDetailShopAndProduct)
 	router.Post("/product-all", h.GetAllProduct)
+	router.Get("/search", h.SearchProduct)
 	router.Get("/product/:id", h.GetDetailProduct)
 	router.Patch("/delete/:id", h.DeleteProductByID)
 	router.Put("/update/:id", middleware.UserIdHeader, h.UpdateProductByID)
+	router.Post("/product/:id/images", middleware.UserIdHeader, h.UploadProductImage)
+	router.Put("/product/:id/images/order", middleware.UserIdHeader, h.ReorderProductImages)
+	router.Delete("/product/:id/images/:image_id", middleware.UserIdHeader, h.DeleteProductImage)
+	router.Post("/product/:id/reservations", middleware.UserIdHeader, h.ReserveStock)
+	router.Post("/reservations/:id/release", middleware.UserIdHeader, h.ReleaseReservation)
+	router.Get("/product/:id/stock-movements", middleware.UserIdHeader, h.GetStockMovements)
+	router.Post("/product/:id/stock-movements", middleware.UserIdHeader, h.CreateStockMovement)
+	router.Get("/shops/:id/stock-alerts", middleware.UserIdHeader, h.GetStockAlerts)
+	router.Post("/shops/:id/imports", middleware.UserIdHeader, h.CreateProductImport)
+	router.Get("/imports/:id", middleware.UserIdHeader, h.GetProductImport)
+	router.Get("/shops/:id/export", middleware.UserIdHeader, h.ExportProducts)
+	router.Get("/product/:id/reviews", h.GetReviews)
+	router.Post("/product/:id/reviews", middleware.UserIdHeader, h.CreateReview)
+	router.Put("/reviews/:id/reply", middleware.UserIdHeader, h.ReplyReview)
+	router.Patch("/reviews/:id/hide", middleware.AuthBearer, middleware.AuthRole([]string{"admin"}), h.HideReview)
+	router.Post("/shops/:id/follow", middleware.UserIdHeader, h.FollowShop)
+	router.Delete("/shops/:id/follow", middleware.UserIdHeader, h.UnfollowShop)
+	router.Get("/feed", middleware.UserIdHeader, h.GetFeed)
+	router.Put("/shops/:id/status", middleware.UserIdHeader, h.UpdateShopStatus)
+	router.Put("/shops/:id/opening-hours", middleware.UserIdHeader, h.UpdateOpeningHours)
+	router.Patch("/shops/:id/close", middleware.AuthBearer, middleware.AuthRole([]string{"admin"}), h.CloseShop)
+	router.Patch("/shops/:id/reopen", middleware.AuthBearer, middleware.AuthRole([]string{"admin"}), h.ReopenShop)
+	router.Get("/shops/:id/products", middleware.UserIdHeader, h.GetShopProducts)
+	router.Get("/product/:id/preview", middleware.UserIdHeader, h.PreviewProduct)
+	router.Put("/product/:id/status", middleware.UserIdHeader, h.UpdateProductStatus)
+	router.Get("/product/:id/revisions", middleware.UserIdHeader, h.GetProductRevisions)
+	router.Get("/product/:id/revisions/diff", middleware.UserIdHeader, h.DiffProductRevisions)
+	router.Get("/product/:id/revisions/:revision", middleware.UserIdHeader, h.GetProductRevision)
+	router.Post("/product/:id/revisions/:revision/rollback", middleware.UserIdHeader, h.RollbackProduct)
 
 }
 
@@ -220,16 +252,26 @@ func (h *shopHandler) CreateProduct(c *fiber.Ctx) error {
 
 func (h *shopHandler) GetDetailShopAndProduct(c *fiber.Ctx) error {
 	var (
-		id            = c.Params("id")
-		querypage     = c.Query("page", "10")
-		querypaginate = c.Query("paginate", "10")
-		ctx           = c.Context()
+		req = new(entity.DetailShopRequest)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
 	)
 
-	page, _ := strconv.Atoi(querypage)
-	paginate, _ := strconv.Atoi(querypaginate)
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::GetDetailShopAndProduct - Parse request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	req.Id = c.Params("id")
+	req.SetDefault()
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetDetailShopAndProduct - Validate request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
 
-	resp, err := h.service.GetDetailShopAndProduct(ctx, id, paginate, page)
+	resp, err := h.service.GetDetailShopAndProduct(ctx, req)
 	if err != nil {
 		code, errs := errmsg.Errors[error](err)
 		return c.Status(code).JSON(response.Error(errs))
@@ -241,6 +283,7 @@ func (h *shopHandler) GetAllProduct(c *fiber.Ctx) error {
 	var (
 		req = new(entity.ProductFilter)
 		ctx = c.Context()
+		v   = adapter.Adapters.Validator
 	)
 
 	if err := c.BodyParser(req); err != nil {
@@ -248,6 +291,12 @@ func (h *shopHandler) GetAllProduct(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
 	}
 
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::GetAllProduct - Validate request body")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
 	resp, err := h.service.GetAllProduct(ctx, req)
 	if err != nil {
 		code, errs := errmsg.Errors[error](err)
@@ -256,6 +305,38 @@ func (h *shopHandler) GetAllProduct(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(response.Success(resp, ""))
 
 }
+func (h *shopHandler) SearchProduct(c *fiber.Ctx) error {
+	var (
+		req = new(entity.ProductFilter)
+		ctx = c.Context()
+		v   = adapter.Adapters.Validator
+	)
+
+	if err := c.QueryParser(req); err != nil {
+		log.Warn().Err(err).Msg("handler::SearchProduct - Parse request query")
+		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
+	}
+
+	if strings.TrimSpace(req.Q) == "" {
+		log.Warn().Any("payload", req).Msg("handler::SearchProduct - Empty keyword")
+		errs := errmsg.NewCustomErrors(400, errmsg.WithErrors("q", "q harus diisi."))
+		return c.Status(errs.Code).JSON(response.Error(errs))
+	}
+
+	if err := v.Validate(req); err != nil {
+		log.Warn().Err(err).Any("payload", req).Msg("handler::SearchProduct - Validate request query")
+		code, errs := errmsg.Errors(err, req)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+
+	resp, err := h.service.GetAllProduct(ctx, req)
+	if err != nil {
+		code, errs := errmsg.Errors[error](err)
+		return c.Status(code).JSON(response.Error(errs))
+	}
+	return c.Status(fiber.StatusOK).JSON(response.Success(resp, ""))
+}
+
 func (h *shopHandler) GetDetailProduct(c *fiber.Ctx) error {
 	var (
 		req = c.Params("id")
@@ -293,14 +374,15 @@ func (h *shopHandler) UpdateProductByID(c *fiber.Ctx) error {
 		l   = middleware.GetLocals(c)
 	)
 
-	req.UserID = l.UserId
-	req.ID = c.Params("id")
-
 	if err := c.BodyParser(req); err != nil {
 		log.Warn().Err(err).Msg("handler::CreateProduct - Parse request body")
 		return c.Status(fiber.StatusBadRequest).JSON(response.Error(err))
 	}
 
+	// set after parsing so the body cannot pick the owner or the product
+	req.UserID = l.UserId
+	req.ID = c.Params("id")
+
 	if err := v.Validate(req); err != nil {
 		log.Warn().Err(err).Any("payload", req).Msg("handler::CreateProduct - Validate request body")
 		code, errs := errmsg.Errors(err, req)
@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"codebase-app/internal/adapter"
+	integration "codebase-app/internal/integration/filestorage"
+	"codebase-app/internal/module/shop/ports"
+	"codebase-app/internal/module/shop/repository"
+	"codebase-app/internal/module/shop/service"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// imageVariantPollInterval is how long an idle worker waits before looking for new uploads.
+const imageVariantPollInterval = 2 * time.Second
+
+type imageVariantWorker struct {
+	service ports.ShopService
+	workers int
+}
+
+// NewImageVariantWorker builds the background worker resizing uploaded product images.
+func NewImageVariantWorker(workers int) *imageVariantWorker {
+	var (
+		worker  = new(imageVariantWorker)
+		repo    = repository.NewShopRepository(adapter.Adapters.ShopeefunPostgres)
+		storage = integration.NewFileStorageIntegration()
+		service = service.NewShopService(repo, storage)
+	)
+	worker.service = service
+	worker.workers = max(1, workers)
+
+	return worker
+}
+
+// Run processes pending images until ctx is cancelled.
+func (w *imageVariantWorker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	log.Info().Int("workers", w.workers).Msg("worker::ImageVariant - Started")
+
+	for i := 0; i < w.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+
+	wg.Wait()
+	log.Info().Msg("worker::ImageVariant - Stopped")
+}
+
+func (w *imageVariantWorker) loop(ctx context.Context) {
+	for {
+		processed, err := w.service.GenerateNextImageVariants(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("worker::ImageVariant - Failed to process image")
+		}
+
+		// keep draining while there is work, otherwise wait for new uploads
+		if processed && err == nil {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(imageVariantPollInterval):
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"codebase-app/internal/adapter"
+	integration "codebase-app/internal/integration/filestorage"
+	"codebase-app/internal/module/shop/ports"
+	"codebase-app/internal/module/shop/repository"
+	"codebase-app/internal/module/shop/service"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// productImportPollInterval is how long an idle worker waits before looking for new imports.
+const productImportPollInterval = 5 * time.Second
+
+type productImportWorker struct {
+	service ports.ShopService
+}
+
+// NewProductImportWorker builds the background worker running queued product imports.
+func NewProductImportWorker() *productImportWorker {
+	var (
+		worker  = new(productImportWorker)
+		repo    = repository.NewShopRepository(adapter.Adapters.ShopeefunPostgres)
+		storage = integration.NewFileStorageIntegration()
+		service = service.NewShopService(repo, storage)
+	)
+	worker.service = service
+
+	return worker
+}
+
+// Run processes queued imports one at a time until ctx is cancelled.
+func (w *productImportWorker) Run(ctx context.Context) {
+	log.Info().Msg("worker::ProductImport - Started")
+
+	for {
+		processed, err := w.service.RunNextProductImport(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("worker::ProductImport - Failed to run import")
+		}
+
+		// keep draining while there is work, otherwise wait for new imports
+		if processed && err == nil && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("worker::ProductImport - Stopped")
+			return
+		case <-time.After(productImportPollInterval):
+		}
+	}
+}
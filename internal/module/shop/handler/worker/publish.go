@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"codebase-app/internal/adapter"
+	integration "codebase-app/internal/integration/filestorage"
+	"codebase-app/internal/module/shop/ports"
+	"codebase-app/internal/module/shop/repository"
+	"codebase-app/internal/module/shop/service"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// publishInterval is how often scheduled drafts are checked, and so how late at most
+// they go live.
+const publishInterval = time.Minute
+
+type publishScheduler struct {
+	service ports.ShopService
+}
+
+// NewPublishScheduler builds the background job publishing drafts whose time has come.
+func NewPublishScheduler() *publishScheduler {
+	var (
+		worker  = new(publishScheduler)
+		repo    = repository.NewShopRepository(adapter.Adapters.ShopeefunPostgres)
+		storage = integration.NewFileStorageIntegration()
+		service = service.NewShopService(repo, storage)
+	)
+	worker.service = service
+
+	return worker
+}
+
+// Run publishes until ctx is cancelled.
+func (w *publishScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(publishInterval)
+	defer ticker.Stop()
+
+	log.Info().Msg("worker::PublishScheduler - Started")
+
+	for {
+		if err := w.service.PublishScheduled(ctx); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("worker::PublishScheduler - Failed to publish scheduled products")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("worker::PublishScheduler - Stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
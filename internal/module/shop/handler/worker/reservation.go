@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"codebase-app/internal/adapter"
+	integration "codebase-app/internal/integration/filestorage"
+	"codebase-app/internal/module/shop/ports"
+	"codebase-app/internal/module/shop/repository"
+	"codebase-app/internal/module/shop/service"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// reservationSweepInterval is how often stale stock holds are expired.
+const reservationSweepInterval = time.Minute
+
+type reservationSweeper struct {
+	service ports.ShopService
+}
+
+// NewReservationSweeper builds the background job expiring stock reservations past their TTL.
+func NewReservationSweeper() *reservationSweeper {
+	var (
+		worker  = new(reservationSweeper)
+		repo    = repository.NewShopRepository(adapter.Adapters.ShopeefunPostgres)
+		storage = integration.NewFileStorageIntegration()
+		service = service.NewShopService(repo, storage)
+	)
+	worker.service = service
+
+	return worker
+}
+
+// Run sweeps until ctx is cancelled.
+func (w *reservationSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(reservationSweepInterval)
+	defer ticker.Stop()
+
+	log.Info().Msg("worker::ReservationSweeper - Started")
+
+	for {
+		if err := w.service.ExpireReservations(ctx); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Msg("worker::ReservationSweeper - Failed to expire reservations")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("worker::ReservationSweeper - Stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
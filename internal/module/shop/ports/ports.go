@@ -3,6 +3,10 @@ package ports
 import (
 	"codebase-app/internal/module/shop/entity"
 	"context"
+	"io"
+	"time"
+
+	"github.com/jmoiron/sqlx"
 )
 
 type ShopRepository interface {
@@ -12,11 +16,50 @@ type ShopRepository interface {
 	UpdateShop(ctx context.Context, req *entity.UpdateShopRequest) (*entity.UpdateShopResponse, error)
 	GetShops(ctx context.Context, req *entity.ShopsRequest) (*entity.ShopsResponse, error)
 	CreateProduct(ctx context.Context, req *entity.CreateProductRequest) (*entity.ProductResponse, error)
-	GetDetailShopAndProduct(ctx context.Context, id string, paginate int, page int) (*entity.DetailShopAndProduct, error)
+	GetDetailShopAndProduct(ctx context.Context, req *entity.DetailShopRequest) (*entity.DetailShopAndProduct, error)
 	GetAllProduct(ctx context.Context, req *entity.ProductFilter) (*entity.ProductsResponse, error)
 	GetDetailProduct(ctx context.Context, id string) (*entity.ProductResponse, error)
 	DeleteProductByID(ctx context.Context, id string) error
 	UpdateProductByID(ctx context.Context, req *entity.UpdateProductRequest) (*entity.UpdateProductRequest, error)
+	CreateProductImage(ctx context.Context, productID string, image *entity.ProductImage) (*entity.ProductImage, error)
+	ReorderProductImages(ctx context.Context, req *entity.ReorderProductImagesRequest) ([]entity.ProductImage, error)
+	DeleteProductImage(ctx context.Context, req *entity.DeleteProductImageRequest) (*entity.ProductImage, error)
+	ClaimPendingImage(ctx context.Context, staleAfter time.Duration) (*entity.ProductImage, error)
+	SaveImageVariants(ctx context.Context, imageID string, variants entity.ImageVariants) error
+	ReleaseImage(ctx context.Context, imageID string, retry bool) error
+	ReserveStock(ctx context.Context, req *entity.ReserveStockRequest) (*entity.StockReservation, error)
+	ReleaseReservation(ctx context.Context, req *entity.ReservationRequest) (*entity.StockReservation, error)
+	ConfirmHolds(ctx context.Context, tx *sqlx.Tx, holder, actor string) ([]entity.StockReservation, error)
+	ReturnHolds(ctx context.Context, tx *sqlx.Tx, holder, actor string) ([]entity.StockReservation, error)
+	ReleaseHolds(ctx context.Context, holder string) error
+	ExpireReservations(ctx context.Context) (int64, error)
+	CreateStockMovement(ctx context.Context, req *entity.CreateStockMovementRequest) (*entity.StockMovement, error)
+	GetStockMovements(ctx context.Context, req *entity.StockMovementsRequest) (*entity.StockMovementsResponse, error)
+	GetStockAlerts(ctx context.Context, req *entity.StockAlertsRequest) (*entity.StockAlertsResponse, error)
+	CheckShopOwner(ctx context.Context, shopID, userID string) error
+	CreateProductImport(ctx context.Context, req *entity.CreateProductImportRequest, source []byte) (*entity.ProductImport, error)
+	GetProductImport(ctx context.Context, req *entity.ProductImportRequest) (*entity.ProductImport, error)
+	GetProductShopID(ctx context.Context, productID string) (string, error)
+	ClaimPendingImport(ctx context.Context, staleAfter time.Duration) (*entity.ProductImport, error)
+	FinishProductImport(ctx context.Context, id string, report entity.ImportRowResults, failure string) error
+	ExportProducts(ctx context.Context, req *entity.ExportProductsRequest, fn func(row *entity.ExportProductRow) error) error
+	CreateReview(ctx context.Context, req *entity.CreateReviewRequest, photos entity.ReviewPhotos) (*entity.ProductReview, error)
+	GetReviews(ctx context.Context, req *entity.ReviewsRequest) (*entity.ReviewsResponse, error)
+	GetReview(ctx context.Context, id string) (*entity.ProductReview, error)
+	ReplyReview(ctx context.Context, req *entity.ReplyReviewRequest) (*entity.ProductReview, error)
+	HideReview(ctx context.Context, req *entity.HideReviewRequest) (*entity.ProductReview, error)
+	FollowShop(ctx context.Context, req *entity.FollowShopRequest) (*entity.FollowShopResponse, error)
+	UnfollowShop(ctx context.Context, req *entity.FollowShopRequest) (*entity.FollowShopResponse, error)
+	GetFeed(ctx context.Context, req *entity.FeedRequest) (*entity.FeedResponse, error)
+	UpdateShopStatus(ctx context.Context, req *entity.UpdateShopStatusRequest) (*entity.ShopStatus, error)
+	UpdateOpeningHours(ctx context.Context, req *entity.UpdateOpeningHoursRequest) (*entity.ShopStatus, error)
+	CloseShop(ctx context.Context, req *entity.CloseShopRequest) (*entity.ShopStatus, error)
+	ReopenShop(ctx context.Context, req *entity.ReopenShopRequest) (*entity.ShopStatus, error)
+	UpdateProductStatus(ctx context.Context, req *entity.UpdateProductStatusRequest) (*entity.ProductStatusResponse, error)
+	PublishScheduled(ctx context.Context) (int64, error)
+	GetShopProducts(ctx context.Context, req *entity.ShopProductsRequest) (*entity.ShopProductsResponse, error)
+	GetProductRevisions(ctx context.Context, req *entity.ProductRevisionsRequest) (*entity.ProductRevisionsResponse, error)
+	GetProductRevision(ctx context.Context, productID string, revision int) (*entity.ProductRevision, error)
 }
 
 type ShopService interface {
@@ -26,9 +69,50 @@ type ShopService interface {
 	UpdateShop(ctx context.Context, req *entity.UpdateShopRequest) (*entity.UpdateShopResponse, error)
 	GetShops(ctx context.Context, req *entity.ShopsRequest) (*entity.ShopsResponse, error)
 	CreateProduct(ctx context.Context, req *entity.CreateProductRequest) (*entity.ProductResponse, error)
-	GetDetailShopAndProduct(ctx context.Context, id string, paginate int, page int) (*entity.DetailShopAndProduct, error)
+	GetDetailShopAndProduct(ctx context.Context, req *entity.DetailShopRequest) (*entity.DetailShopAndProduct, error)
 	GetAllProduct(ctx context.Context, req *entity.ProductFilter) (*entity.ProductsResponse, error)
 	GetDetailProduct(ctx context.Context, id string) (*entity.ProductResponse, error)
 	DeleteProductByID(ctx context.Context, id string) error
 	UpdateProductByID(ctx context.Context, req *entity.UpdateProductRequest) (*entity.UpdateProductRequest, error)
+	UploadProductImage(ctx context.Context, req *entity.UploadProductImageRequest) (*entity.ProductImage, error)
+	ReorderProductImages(ctx context.Context, req *entity.ReorderProductImagesRequest) ([]entity.ProductImage, error)
+	DeleteProductImage(ctx context.Context, req *entity.DeleteProductImageRequest) error
+	GenerateNextImageVariants(ctx context.Context) (bool, error)
+	ReserveStock(ctx context.Context, req *entity.ReserveStockRequest) (*entity.StockReservation, error)
+	ReleaseReservation(ctx context.Context, req *entity.ReservationRequest) (*entity.StockReservation, error)
+	ExpireReservations(ctx context.Context) error
+	CreateStockMovement(ctx context.Context, req *entity.CreateStockMovementRequest) (*entity.StockMovement, error)
+	GetStockMovements(ctx context.Context, req *entity.StockMovementsRequest) (*entity.StockMovementsResponse, error)
+	GetStockAlerts(ctx context.Context, req *entity.StockAlertsRequest) (*entity.StockAlertsResponse, error)
+	CreateProductImport(ctx context.Context, req *entity.CreateProductImportRequest) (*entity.ProductImport, error)
+	GetProductImport(ctx context.Context, req *entity.ProductImportRequest) (*entity.ProductImport, error)
+	RunNextProductImport(ctx context.Context) (bool, error)
+	ImportProducts(ctx context.Context, job *entity.ProductImport, body io.Reader) (entity.ImportRowResults, error)
+	ExportProducts(ctx context.Context, req *entity.ExportProductsRequest, w io.Writer) error
+	CreateReview(ctx context.Context, req *entity.CreateReviewRequest) (*entity.ProductReview, error)
+	GetReviews(ctx context.Context, req *entity.ReviewsRequest) (*entity.ReviewsResponse, error)
+	ReplyReview(ctx context.Context, req *entity.ReplyReviewRequest) (*entity.ProductReview, error)
+	HideReview(ctx context.Context, req *entity.HideReviewRequest) (*entity.ProductReview, error)
+	FollowShop(ctx context.Context, req *entity.FollowShopRequest) (*entity.FollowShopResponse, error)
+	UnfollowShop(ctx context.Context, req *entity.FollowShopRequest) (*entity.FollowShopResponse, error)
+	GetFeed(ctx context.Context, req *entity.FeedRequest) (*entity.FeedResponse, error)
+	UpdateShopStatus(ctx context.Context, req *entity.UpdateShopStatusRequest) (*entity.ShopStatus, error)
+	UpdateOpeningHours(ctx context.Context, req *entity.UpdateOpeningHoursRequest) (*entity.ShopStatus, error)
+	CloseShop(ctx context.Context, req *entity.CloseShopRequest) (*entity.ShopStatus, error)
+	ReopenShop(ctx context.Context, req *entity.ReopenShopRequest) (*entity.ShopStatus, error)
+	UpdateProductStatus(ctx context.Context, req *entity.UpdateProductStatusRequest) (*entity.ProductStatusResponse, error)
+	PreviewProduct(ctx context.Context, req *entity.PreviewProductRequest) (*entity.ProductResponse, error)
+	GetShopProducts(ctx context.Context, req *entity.ShopProductsRequest) (*entity.ShopProductsResponse, error)
+	PublishScheduled(ctx context.Context) error
+	GetProductRevisions(ctx context.Context, req *entity.ProductRevisionsRequest) (*entity.ProductRevisionsResponse, error)
+	GetProductRevision(ctx context.Context, req *entity.ProductRevisionRequest) (*entity.ProductRevision, error)
+	DiffProductRevisions(ctx context.Context, req *entity.RevisionDiffRequest) (*entity.RevisionDiffResponse, error)
+	RollbackProduct(ctx context.Context, req *entity.ProductRevisionRequest) (*entity.UpdateProductRequest, error)
+}
+
+// FileStorage is where product images are uploaded to.
+type FileStorage interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) (url string, err error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
 }
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/types"
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// alertFeedSort tags the cursors of the alert feed, which is read oldest first.
+const alertFeedSort = "oldest"
+
+// checkLowStock raises a stock alert when a product's stock has dropped below its
+// low-stock threshold. Only the first change that crosses the threshold raises one;
+// the product is armed again once its stock is back at or above it. Like reconcileStock
+// it runs at the end of every transaction that touches stock.
+func checkLowStock(ctx context.Context, tx *sqlx.Tx, productID string) error {
+	queryAlert := `
+		WITH crossed AS (
+			UPDATE product SET low_stock_alerted = true
+			WHERE id = ? AND low_stock_threshold > 0 AND stok < low_stock_threshold AND NOT low_stock_alerted
+			RETURNING id, shop_id, stok, low_stock_threshold
+		)
+		INSERT INTO stock_alerts (shop_id, product_id, stok, threshold)
+		SELECT shop_id, id, stok, low_stock_threshold FROM crossed
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(queryAlert), productID); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::checkLowStock - Failed to raise stock alert")
+		return err
+	}
+
+	queryRearm := `
+		UPDATE product SET low_stock_alerted = false
+		WHERE id = ? AND low_stock_alerted AND (low_stock_threshold = 0 OR stok >= low_stock_threshold)
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(queryRearm), productID); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::checkLowStock - Failed to rearm stock alert")
+		return err
+	}
+
+	return nil
+}
+
+func (r *shopRepository) GetStockAlerts(ctx context.Context, req *entity.StockAlertsRequest) (*entity.StockAlertsResponse, error) {
+	type dao struct {
+		SortValue string `db:"sort_value"`
+		entity.StockAlert
+	}
+
+	var (
+		data   = make([]dao, 0, req.Paginate+1)
+		resp   = &entity.StockAlertsResponse{Items: make([]entity.StockAlert, 0, req.Paginate)}
+		args   = []any{req.ShopID}
+		keyset = ""
+	)
+
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor, alertFeedSort)
+		if err != nil {
+			log.Warn().Err(err).Any("payload", req).Msg("repository::GetStockAlerts - Invalid cursor")
+			return nil, err
+		}
+		keyset = "AND (stock_alerts.created_at, stock_alerts.id) > (CAST(? AS timestamptz), CAST(? AS uuid))"
+		args = append(args, cursor.Value, cursor.Id)
+	}
+
+	// one extra row tells whether there is a next page
+	query := `
+		SELECT
+			stock_alerts.created_at::text AS sort_value,
+			stock_alerts.id,
+			stock_alerts.product_id,
+			product.name AS product_name,
+			stock_alerts.stok,
+			stock_alerts.threshold,
+			stock_alerts.created_at
+		FROM stock_alerts
+		JOIN product ON product.id = stock_alerts.product_id
+		WHERE stock_alerts.shop_id = ?
+			` + keyset + `
+		ORDER BY stock_alerts.created_at ASC, stock_alerts.id ASC
+		LIMIT ?
+	`
+	args = append(args, req.Paginate+1)
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::GetStockAlerts - Failed to get stock alerts")
+		return nil, err
+	}
+
+	if len(data) > req.Paginate {
+		data = data[:req.Paginate]
+	}
+
+	for _, d := range data {
+		resp.Items = append(resp.Items, d.StockAlert)
+	}
+
+	// the feed has no last page, consumers keep polling from the newest alert they saw
+	resp.Meta.Cursor = req.Cursor
+	resp.Meta.NextCursor = req.Cursor
+	if len(data) > 0 {
+		last := data[len(data)-1]
+		resp.Meta.NextCursor = types.Cursor{Sort: alertFeedSort, Value: last.SortValue, Id: last.ID}.Encode()
+	}
+	resp.Meta.Paginate = req.Paginate
+
+	return resp, nil
+}
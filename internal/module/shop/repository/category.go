@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// saveCategories replaces the category links of a product with the given category ids.
+func (r *shopRepository) saveCategories(ctx context.Context, tx *sqlx.Tx, productID string, categoryIDs []string) error {
+	queryDelete := `DELETE FROM product_categories WHERE product_id = ?`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(queryDelete), productID); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::saveCategories - Failed to delete category links")
+		return err
+	}
+
+	queryInsert := `
+		INSERT INTO product_categories (product_id, category_id)
+		SELECT ?, id FROM categories WHERE id = ANY(?) AND deleted_at IS NULL
+	`
+	res, err := tx.ExecContext(ctx, tx.Rebind(queryInsert), productID, pq.Array(categoryIDs))
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Any("kategori", categoryIDs).Msg("repository::saveCategories - Failed to insert category links")
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); int(n) != len(categoryIDs) {
+		log.Warn().Str("product_id", productID).Any("kategori", categoryIDs).Msg("repository::saveCategories - Unknown category id")
+		return errmsg.NewCustomErrors(400, errmsg.WithErrors("kategori", "kategori tidak ditemukan."))
+	}
+
+	return nil
+}
+
+// getCategories loads the categories linked to the given products, keyed by product id.
+func (r *shopRepository) getCategories(ctx context.Context, productIDs []string) (map[string][]entity.ProductCategory, error) {
+	type dao struct {
+		ProductID string `db:"product_id"`
+		entity.ProductCategory
+	}
+
+	var (
+		data []dao
+		resp = make(map[string][]entity.ProductCategory, len(productIDs))
+	)
+
+	if len(productIDs) == 0 {
+		return resp, nil
+	}
+
+	query := `
+		SELECT product_categories.product_id, categories.id, categories.name, categories.slug
+		FROM product_categories
+		JOIN categories ON categories.id = product_categories.category_id
+		WHERE product_categories.product_id = ANY(?) AND categories.deleted_at IS NULL
+		ORDER BY categories.position, categories.name
+	`
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), pq.Array(productIDs)); err != nil {
+		log.Error().Err(err).Any("payload", productIDs).Msg("repository::getCategories - Failed to get categories")
+		return nil, err
+	}
+
+	for _, d := range data {
+		resp[d.ProductID] = append(resp[d.ProductID], d.ProductCategory)
+	}
+
+	return resp, nil
+}
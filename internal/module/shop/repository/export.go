@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"context"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// ExportProducts calls fn with every product of the request's shop matching its filter,
+// in listing order. Rows are read from Postgres as they are handed to fn rather than
+// loaded up front; an error returned by fn stops the export and is returned as is.
+func (r *shopRepository) ExportProducts(ctx context.Context, req *entity.ExportProductsRequest, fn func(row *entity.ExportProductRow) error) error {
+	type dao struct {
+		KategoriIDs   pq.StringArray `db:"kategori"`
+		KategoriNames pq.StringArray `db:"kategori_nama"`
+		entity.ExportProductRow
+	}
+
+	var (
+		where, args = productFilter(&req.Filter, false)
+		tsquery     = searchQuery(req.Filter.Q)
+		sortKey     = productSortKey(req.Filter.Sort, tsquery != "")
+		search      = ""
+	)
+
+	if tsquery != "" {
+		search = "CROSS JOIN (SELECT to_tsquery('simple', ?) AS query) search"
+		args = append([]any{tsquery}, args...)
+	}
+	args = append(args, req.ShopID)
+
+	query := `
+		SELECT
+			product.id,
+			product.name,
+			product.description,
+			ROW(product.harga, product.currency) AS harga,
+			product.stok,
+			COALESCE(product.merek, '') AS merek,
+			product.low_stock_threshold,
+			product.created_at,
+			COALESCE(product_category.ids, '{}') AS kategori,
+			COALESCE(product_category.names, '{}') AS kategori_nama
+		FROM product
+		` + search + `
+		LEFT JOIN LATERAL (
+			SELECT MIN(harga) AS min_harga, MAX(harga) AS max_harga
+			FROM product_variants
+			WHERE product_variants.product_id = product.id AND product_variants.deleted_at IS NULL
+		) variant_price ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT
+				array_agg(categories.id::text ORDER BY categories.position, categories.name) AS ids,
+				array_agg(categories.name ORDER BY categories.position, categories.name) AS names
+			FROM product_categories
+			JOIN categories ON categories.id = product_categories.category_id
+			WHERE product_categories.product_id = product.id AND categories.deleted_at IS NULL
+		) product_category ON TRUE
+		WHERE
+			` + where + `
+			AND product.shop_id = ?
+		ORDER BY ` + sortKey.orderBy()
+
+	rows, err := r.db.QueryxContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::ExportProducts - Failed to query products")
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d dao
+		if err := rows.StructScan(&d); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repository::ExportProducts - Failed to scan product")
+			return err
+		}
+
+		d.Kategori, d.KategoriNama = d.KategoriIDs, d.KategoriNames
+		if err := fn(&d.ExportProductRow); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::ExportProducts - Failed to read products")
+		return err
+	}
+
+	return nil
+}
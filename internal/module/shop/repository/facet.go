@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// priceBuckets are the lower bounds of the harga facet buckets.
+var priceBuckets = []int64{0, 50000, 100000, 250000, 500000, 1000000}
+
+// getFacets counts the products matching the filter per category, merek, rating and price bucket.
+func (r *shopRepository) getFacets(ctx context.Context, req *entity.ProductFilter) (*entity.ProductFacets, error) {
+	type dao struct {
+		Facet string `db:"facet"`
+		entity.FacetCount
+	}
+
+	var (
+		data        []dao
+		where, args = productFilter(req, true)
+		resp        = &entity.ProductFacets{
+			Kategori: make([]entity.FacetCount, 0),
+			Merek:    make([]entity.FacetCount, 0),
+			Rating:   make([]entity.FacetCount, 0),
+			Harga:    make([]entity.PriceBucket, 0, len(priceBuckets)),
+		}
+	)
+
+	query := `
+		WITH filtered AS (
+			SELECT product.id, product.merek, product.penilaian, product.harga
+			FROM product
+			WHERE
+				` + where + `
+		)
+		SELECT 'kategori' AS facet, categories.id::text AS value, categories.name AS label, COUNT(DISTINCT filtered.id) AS count
+		FROM filtered
+		JOIN product_categories ON product_categories.product_id = filtered.id
+		JOIN categories ON categories.id = product_categories.category_id
+		WHERE categories.deleted_at IS NULL
+		GROUP BY categories.id, categories.name
+		UNION ALL
+		SELECT 'merek', merek, merek, COUNT(id)
+		FROM filtered
+		WHERE merek IS NOT NULL AND merek <> ''
+		GROUP BY merek
+		UNION ALL
+		SELECT 'rating', stars::text, stars::text, COUNT(id)
+		FROM filtered
+		CROSS JOIN generate_series(1, 4) AS stars
+		WHERE penilaian >= stars
+		GROUP BY stars
+		UNION ALL
+		SELECT 'harga', width_bucket(harga, ?::bigint[])::text, '', COUNT(id)
+		FROM filtered
+		GROUP BY 2
+	`
+
+	args = append(args, pq.Array(priceBuckets))
+
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::getFacets - Failed to get facets")
+		return nil, err
+	}
+
+	bucketCounts := make(map[int]int, len(priceBuckets))
+	for _, d := range data {
+		switch d.Facet {
+		case "kategori":
+			resp.Kategori = append(resp.Kategori, d.FacetCount)
+		case "merek":
+			resp.Merek = append(resp.Merek, d.FacetCount)
+		case "rating":
+			resp.Rating = append(resp.Rating, d.FacetCount)
+		case "harga":
+			// width_bucket returns 1 for the first bucket and 0 below it (negative prices)
+			idx, _ := strconv.Atoi(d.Value)
+			if idx > 0 {
+				bucketCounts[idx-1] += d.Count
+			}
+		}
+	}
+
+	for i, min := range priceBuckets {
+		bucket := entity.PriceBucket{Min: min, Count: bucketCounts[i]}
+		if i+1 < len(priceBuckets) {
+			max := priceBuckets[i+1]
+			bucket.Max = &max
+		}
+		resp.Harga = append(resp.Harga, bucket)
+	}
+
+	byCount := func(items []entity.FacetCount) func(i, j int) bool {
+		return func(i, j int) bool {
+			if items[i].Count != items[j].Count {
+				return items[i].Count > items[j].Count
+			}
+			return items[i].Label < items[j].Label
+		}
+	}
+	sort.Slice(resp.Kategori, byCount(resp.Kategori))
+	sort.Slice(resp.Merek, byCount(resp.Merek))
+	sort.Slice(resp.Rating, func(i, j int) bool {
+		return resp.Rating[i].Value > resp.Rating[j].Value
+	})
+
+	return resp, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/types"
+	"strings"
+)
+
+// productFilter turns a ProductFilter into a WHERE clause over the product table
+// and its bind arguments, so listings and their aggregates share the same conditions.
+// Buyer listings pass publishedOnly; owner queries see drafts and archived products too.
+func productFilter(req *entity.ProductFilter, publishedOnly bool) (where string, args []any) {
+	conds := []string{"product.deleted_at IS NULL"}
+
+	if publishedOnly {
+		conds = append(conds, "product.status = 'published'")
+	}
+
+	if req.Merek != "" {
+		conds = append(conds, "product.merek ILIKE '%' || ? || '%'")
+		args = append(args, req.Merek)
+	}
+
+	if req.Name != "" {
+		conds = append(conds, "product.name ILIKE '%' || ? || '%'")
+		args = append(args, req.Name)
+	}
+
+	conds = append(conds, "product.harga >= ?", "product.harga <= ?")
+	args = append(args, req.MinHarga, req.MaxHarga)
+
+	if req.Kategori != "" {
+		conds = append(conds, `EXISTS (
+			SELECT 1 FROM product_categories
+			JOIN categories ON categories.id = product_categories.category_id
+			WHERE product_categories.product_id = product.id
+				AND categories.deleted_at IS NULL
+				AND categories.name ILIKE '%' || ? || '%'
+		)`)
+		args = append(args, req.Kategori)
+	}
+
+	if req.Penilaian > 0 {
+		conds = append(conds, "product.penilaian >= ?")
+		args = append(args, req.Penilaian)
+	}
+
+	if tsquery := searchQuery(req.Q); tsquery != "" {
+		conds = append(conds, "product.search_vector @@ to_tsquery('simple', ?)")
+		args = append(args, tsquery)
+	}
+
+	return strings.Join(conds, "\n\t\t\t\tAND "), args
+}
+
+// searchQuery builds a prefix tsquery out of free text, e.g. "kaos pol" => "kaos:* | pol:*".
+// It returns an empty string when q holds no words.
+func searchQuery(q string) string {
+	return pkg.FormatKeywords(q)
+}
+
+// productSort is the keyset of a listing order: the sort key expression, the SQL type
+// its cursor value is cast back to, and the direction. product.id breaks ties so rows
+// sharing a sort value keep the same position across pages.
+type productSort struct {
+	key  string
+	cast string
+	desc bool
+}
+
+func productSortKey(sort string, search bool) productSort {
+	switch sort {
+	case entity.SortPriceAsc:
+		return productSort{key: "COALESCE(variant_price.min_harga, product.harga)", cast: "bigint"}
+	case entity.SortPriceDesc:
+		return productSort{key: "COALESCE(variant_price.min_harga, product.harga)", cast: "bigint", desc: true}
+	case entity.SortRating:
+		return productSort{key: "COALESCE(product.penilaian, 0)", cast: "numeric", desc: true}
+	case entity.SortStock:
+		return productSort{key: "product.stok", cast: "integer", desc: true}
+	case entity.SortBestSelling:
+		return productSort{key: "product.terjual", cast: "bigint", desc: true}
+	case entity.SortBestMatch:
+		if search {
+			return productSort{key: "ts_rank(product.search_vector, search.query)", cast: "real", desc: true}
+		}
+	}
+
+	return productSort{key: "product.published_at", cast: "timestamptz", desc: true}
+}
+
+func (s productSort) orderBy() string {
+	dir := "ASC"
+	if s.desc {
+		dir = "DESC"
+	}
+
+	return s.key + " " + dir + ", product.id " + dir
+}
+
+// after is the keyset condition selecting rows past the cursor; it binds the cursor value and id.
+func (s productSort) after() string {
+	op := ">"
+	if s.desc {
+		op = "<"
+	}
+
+	return "(" + s.key + ", product.id) " + op + " (CAST(? AS " + s.cast + "), CAST(? AS uuid))"
+}
+
+// decodeCursor parses a client cursor and checks it was issued for the same listing order.
+func decodeCursor(cursor, sort string) (*types.Cursor, error) {
+	c, err := types.DecodeCursor(cursor)
+	if err != nil || c.Sort != sort {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("cursor", "cursor tidak valid."))
+	}
+
+	return c, nil
+}
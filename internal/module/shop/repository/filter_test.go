@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchQuery(t *testing.T) {
+	cases := []struct {
+		q, want string
+	}{
+		{"kaos pol", "kaos:* | pol:*"},
+		{"don't", "don:* | t:*"},
+		{"'kaos'", "kaos:*"},
+		{`a&b|!c (d):*<e>\f`, "a:* | b:* | c:* | d:* | e:* | f:*"},
+		{"  kaos   \t polos  ", "kaos:* | polos:*"},
+		{"&|!():*<>'\\", ""},
+		{"", ""},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.want, searchQuery(c.q), "q = %q", c.q)
+	}
+}
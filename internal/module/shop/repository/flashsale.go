@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// getFlashSales loads the flash sale each of productIDs is in right now, if any.
+func (r *shopRepository) getFlashSales(ctx context.Context, productIDs []string) (map[string]*entity.ProductFlashSale, error) {
+	type dao struct {
+		ProductID string `db:"product_id"`
+		entity.ProductFlashSale
+	}
+
+	var (
+		data []dao
+		resp = make(map[string]*entity.ProductFlashSale, len(productIDs))
+	)
+
+	if len(productIDs) == 0 {
+		return resp, nil
+	}
+
+	query := `
+		SELECT product_id, flash_sale_id, name, ROW(harga, currency) AS harga, quota, remaining, ends_at
+		FROM active_flash_sale_items
+		WHERE product_id = ANY(?)
+	`
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), pq.Array(productIDs)); err != nil {
+		log.Error().Err(err).Any("payload", productIDs).Msg("repository::getFlashSales - Failed to get flash sales")
+		return nil, err
+	}
+
+	for i := range data {
+		resp[data[i].ProductID] = &data[i].ProductFlashSale
+	}
+
+	return resp, nil
+}
+
+// checkFlashSaleVariants fails when a product that is in a running or upcoming flash sale
+// is about to get SKUs. A flash sale has one price per product and cannot price SKUs.
+// The caller holds the product row, the one flash sales lock when taking a product.
+func checkFlashSaleVariants(ctx context.Context, tx *sqlx.Tx, productID string) error {
+	var found bool
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM flash_sale_items
+			JOIN flash_sales ON flash_sales.id = flash_sale_items.flash_sale_id
+			WHERE flash_sale_items.product_id = ? AND flash_sales.deleted_at IS NULL AND flash_sales.ends_at > NOW()
+		)
+	`
+	if err := tx.GetContext(ctx, &found, tx.Rebind(query), productID); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::checkFlashSaleVariants - Failed to check flash sales")
+		return err
+	}
+
+	if found {
+		return errmsg.NewCustomErrors(409, errmsg.WithErrors("variants", "produk yang ada di flash sale tidak dapat diberi varian."))
+	}
+
+	return nil
+}
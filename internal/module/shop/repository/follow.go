@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/types"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// feedSort tags the cursors of the follow feed, which is read newest first.
+const feedSort = "feed"
+
+// raiseShopEvent adds an event about a product to the feed of its shop's followers.
+func raiseShopEvent(ctx context.Context, tx *sqlx.Tx, productID, kind string) error {
+	query := `
+		INSERT INTO shop_events (shop_id, product_id, kind, stok)
+		SELECT shop_id, id, ?, stok FROM product WHERE id = ?
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), kind, productID); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Str("kind", kind).Msg("repository::raiseShopEvent - Failed to raise shop event")
+		return err
+	}
+
+	return nil
+}
+
+// checkRestock raises a restocked event when a published product that ran out of stock
+// has stock again, and remembers when it runs out. Like checkLowStock it runs at the end
+// of every transaction that touches stock.
+func checkRestock(ctx context.Context, tx *sqlx.Tx, productID string) error {
+	queryRestock := `
+		WITH restocked AS (
+			UPDATE product SET out_of_stock = false
+			WHERE id = ? AND out_of_stock AND stok > 0 AND deleted_at IS NULL
+			RETURNING id, shop_id, stok, status
+		)
+		INSERT INTO shop_events (shop_id, product_id, kind, stok)
+		SELECT shop_id, id, ?, stok FROM restocked WHERE status = 'published'
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(queryRestock), productID, entity.EventRestocked); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::checkRestock - Failed to raise restock event")
+		return err
+	}
+
+	querySoldOut := `UPDATE product SET out_of_stock = true WHERE id = ? AND NOT out_of_stock AND stok = 0`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(querySoldOut), productID); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::checkRestock - Failed to mark product out of stock")
+		return err
+	}
+
+	return nil
+}
+
+// FollowShop makes userID follow a shop; following it again changes nothing.
+func (r *shopRepository) FollowShop(ctx context.Context, req *entity.FollowShopRequest) (*entity.FollowShopResponse, error) {
+	var found bool
+
+	queryShop := `SELECT EXISTS (SELECT 1 FROM shops WHERE id = ? AND deleted_at IS NULL)`
+	if err := r.db.GetContext(ctx, &found, r.db.Rebind(queryShop), req.ShopID); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::FollowShop - Failed to get shop")
+		return nil, err
+	}
+	if !found {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Toko tidak ditemukan"))
+	}
+
+	query := `
+		WITH followed AS (
+			INSERT INTO shop_followers (shop_id, user_id) VALUES (?, ?)
+			ON CONFLICT (shop_id, user_id) DO NOTHING
+			RETURNING shop_id
+		)
+		UPDATE shops SET follower_count = follower_count + 1
+		FROM followed
+		WHERE shops.id = followed.shop_id
+	`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), req.ShopID, req.UserID); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::FollowShop - Failed to follow shop")
+		return nil, err
+	}
+
+	return r.followState(ctx, req)
+}
+
+// UnfollowShop stops userID from following a shop; unfollowing it again changes nothing.
+func (r *shopRepository) UnfollowShop(ctx context.Context, req *entity.FollowShopRequest) (*entity.FollowShopResponse, error) {
+	query := `
+		WITH unfollowed AS (
+			DELETE FROM shop_followers WHERE shop_id = ? AND user_id = ?
+			RETURNING shop_id
+		)
+		UPDATE shops SET follower_count = follower_count - 1
+		FROM unfollowed
+		WHERE shops.id = unfollowed.shop_id
+	`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), req.ShopID, req.UserID); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UnfollowShop - Failed to unfollow shop")
+		return nil, err
+	}
+
+	return r.followState(ctx, req)
+}
+
+func (r *shopRepository) followState(ctx context.Context, req *entity.FollowShopRequest) (*entity.FollowShopResponse, error) {
+	var resp = new(entity.FollowShopResponse)
+
+	query := `
+		SELECT
+			id,
+			follower_count,
+			EXISTS (SELECT 1 FROM shop_followers WHERE shop_id = shops.id AND user_id = ?) AS following
+		FROM shops
+		WHERE id = ?
+	`
+	err := r.db.GetContext(ctx, resp, r.db.Rebind(query), req.UserID, req.ShopID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Toko tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::followState - Failed to get shop")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// GetFeed reads the events of the shops userID follows. Events of deleted shops, and of
+// products deleted or no longer published, are left out.
+func (r *shopRepository) GetFeed(ctx context.Context, req *entity.FeedRequest) (*entity.FeedResponse, error) {
+	type dao struct {
+		SortValue string `db:"sort_value"`
+		entity.FeedEvent
+	}
+
+	var (
+		data   = make([]dao, 0, req.Paginate+1)
+		resp   = &entity.FeedResponse{Items: make([]entity.FeedEvent, 0, req.Paginate)}
+		args   = []any{req.UserID}
+		keyset = ""
+	)
+
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor, feedSort)
+		if err != nil {
+			log.Warn().Err(err).Any("payload", req).Msg("repository::GetFeed - Invalid cursor")
+			return nil, err
+		}
+		keyset = "WHERE (shop_events.created_at, shop_events.id) < (CAST(? AS timestamptz), CAST(? AS uuid))"
+		args = append(args, cursor.Value, cursor.Id)
+	}
+
+	// one extra row tells whether there is a next page
+	query := `
+		SELECT
+			shop_events.created_at::text AS sort_value,
+			shop_events.id,
+			shop_events.kind,
+			shop_events.shop_id,
+			shops.name AS shop_name,
+			shop_events.product_id,
+			product.name AS product_name,
+			ROW(product.harga, product.currency) AS harga,
+			shop_events.stok,
+			shop_events.created_at
+		FROM shop_events
+		JOIN shop_followers ON shop_followers.shop_id = shop_events.shop_id AND shop_followers.user_id = ?
+		JOIN shops ON shops.id = shop_events.shop_id AND shops.deleted_at IS NULL
+		JOIN product ON product.id = shop_events.product_id AND product.deleted_at IS NULL AND product.status = 'published'
+		` + keyset + `
+		ORDER BY shop_events.created_at DESC, shop_events.id DESC
+		LIMIT ?
+	`
+	args = append(args, req.Paginate+1)
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::GetFeed - Failed to get feed")
+		return nil, err
+	}
+
+	if len(data) > req.Paginate {
+		data = data[:req.Paginate]
+		last := data[len(data)-1]
+		resp.Meta.NextCursor = types.Cursor{Sort: feedSort, Value: last.SortValue, Id: last.ID}.Encode()
+	}
+
+	for _, d := range data {
+		resp.Items = append(resp.Items, d.FeedEvent)
+	}
+
+	resp.Meta.Cursor = req.Cursor
+	resp.Meta.Paginate = req.Paginate
+
+	return resp, nil
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// maxProductImages caps the size of a product gallery.
+const maxProductImages = 10
+
+// lockProduct takes a row lock on a live product so concurrent gallery changes are serialized.
+func lockProduct(ctx context.Context, tx *sqlx.Tx, productID string) error {
+	var id string
+
+	query := `SELECT id FROM product WHERE id = ? AND deleted_at IS NULL FOR UPDATE`
+	err := tx.GetContext(ctx, &id, tx.Rebind(query), productID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Produk tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::lockProduct - Failed to lock product")
+		return err
+	}
+
+	return nil
+}
+
+// CreateProductImage appends an image to the end of the gallery; the first image becomes primary.
+func (r *shopRepository) CreateProductImage(ctx context.Context, productID string, image *entity.ProductImage) (*entity.ProductImage, error) {
+	var (
+		resp  = new(entity.ProductImage)
+		count int
+	)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::CreateProductImage - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, productID); err != nil {
+		return nil, err
+	}
+
+	queryCount := `SELECT COUNT(id) FROM product_images WHERE product_id = ?`
+	if err := tx.GetContext(ctx, &count, tx.Rebind(queryCount), productID); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::CreateProductImage - Failed to count images")
+		return nil, err
+	}
+
+	if count >= maxProductImages {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("image", "jumlah gambar produk sudah maksimal."))
+	}
+
+	queryInsert := `
+		INSERT INTO product_images (product_id, storage_key, url, position, is_primary)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id, url, position, is_primary, variants, variants_status, variants_attempts, storage_key
+	`
+	err = tx.QueryRowxContext(ctx, tx.Rebind(queryInsert), productID, image.StorageKey, image.URL, count, count == 0).StructScan(resp)
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::CreateProductImage - Failed to insert image")
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::CreateProductImage - Failed to commit transaction")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ReorderProductImages rewrites the gallery positions to follow req.ImageIDs.
+func (r *shopRepository) ReorderProductImages(ctx context.Context, req *entity.ReorderProductImagesRequest) ([]entity.ProductImage, error) {
+	var current []string
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::ReorderProductImages - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	queryCurrent := `SELECT id FROM product_images WHERE product_id = ?`
+	if err := tx.SelectContext(ctx, &current, tx.Rebind(queryCurrent), req.ProductID); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::ReorderProductImages - Failed to get images")
+		return nil, err
+	}
+
+	// the new order has to name every image of the product exactly once
+	known := make(map[string]bool, len(current))
+	for _, id := range current {
+		known[id] = true
+	}
+	if len(req.ImageIDs) != len(current) {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("image_ids", "urutan harus memuat semua gambar produk."))
+	}
+	for _, id := range req.ImageIDs {
+		if !known[id] {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("image_ids", "gambar tidak ditemukan."))
+		}
+	}
+	if req.PrimaryID != "" && !known[req.PrimaryID] {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("primary_id", "gambar tidak ditemukan."))
+	}
+
+	queryOrder := `
+		UPDATE product_images SET position = ordered.position - 1, updated_at = NOW()
+		FROM unnest(CAST(? AS uuid[])) WITH ORDINALITY AS ordered(id, position)
+		WHERE product_images.id = ordered.id AND product_images.product_id = ?
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(queryOrder), pq.Array(req.ImageIDs), req.ProductID); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::ReorderProductImages - Failed to reorder images")
+		return nil, err
+	}
+
+	if req.PrimaryID != "" {
+		// cleared first, the unique primary index is checked row by row
+		queryClear := `UPDATE product_images SET is_primary = false WHERE product_id = ? AND is_primary`
+		if _, err := tx.ExecContext(ctx, tx.Rebind(queryClear), req.ProductID); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repository::ReorderProductImages - Failed to clear primary image")
+			return nil, err
+		}
+
+		queryPrimary := `UPDATE product_images SET is_primary = true WHERE id = ?`
+		if _, err := tx.ExecContext(ctx, tx.Rebind(queryPrimary), req.PrimaryID); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repository::ReorderProductImages - Failed to set primary image")
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::ReorderProductImages - Failed to commit transaction")
+		return nil, err
+	}
+
+	images, err := r.getImages(ctx, []string{req.ProductID})
+	if err != nil {
+		return nil, err
+	}
+
+	return images[req.ProductID], nil
+}
+
+// DeleteProductImage removes an image, closes the gap it leaves in the order and
+// hands the primary flag to the next image when needed. The deleted row is returned
+// so the caller can remove the stored file.
+func (r *shopRepository) DeleteProductImage(ctx context.Context, req *entity.DeleteProductImageRequest) (*entity.ProductImage, error) {
+	var resp = new(entity.ProductImage)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::DeleteProductImage - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	queryDelete := `
+		DELETE FROM product_images WHERE id = ? AND product_id = ?
+		RETURNING id, url, position, is_primary, variants, variants_status, variants_attempts, storage_key
+	`
+	err = tx.QueryRowxContext(ctx, tx.Rebind(queryDelete), req.ImageID, req.ProductID).StructScan(resp)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Gambar tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::DeleteProductImage - Failed to delete image")
+		return nil, err
+	}
+
+	queryShift := `UPDATE product_images SET position = position - 1 WHERE product_id = ? AND position > ?`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(queryShift), req.ProductID, resp.Position); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::DeleteProductImage - Failed to shift positions")
+		return nil, err
+	}
+
+	if resp.IsPrimary {
+		queryPromote := `
+			UPDATE product_images SET is_primary = true
+			WHERE id = (SELECT id FROM product_images WHERE product_id = ? ORDER BY position LIMIT 1)
+		`
+		if _, err := tx.ExecContext(ctx, tx.Rebind(queryPromote), req.ProductID); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repository::DeleteProductImage - Failed to promote primary image")
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::DeleteProductImage - Failed to commit transaction")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// getImages loads the galleries of the given products in display order, keyed by product id.
+func (r *shopRepository) getImages(ctx context.Context, productIDs []string) (map[string][]entity.ProductImage, error) {
+	type dao struct {
+		ProductID string `db:"product_id"`
+		entity.ProductImage
+	}
+
+	var (
+		data []dao
+		resp = make(map[string][]entity.ProductImage, len(productIDs))
+	)
+
+	if len(productIDs) == 0 {
+		return resp, nil
+	}
+
+	query := `
+		SELECT product_id, id, url, position, is_primary, variants, variants_status, variants_attempts, storage_key
+		FROM product_images
+		WHERE product_id = ANY(?)
+		ORDER BY product_id, position
+	`
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), pq.Array(productIDs)); err != nil {
+		log.Error().Err(err).Any("payload", productIDs).Msg("repository::getImages - Failed to get images")
+		return nil, err
+	}
+
+	for _, d := range data {
+		resp[d.ProductID] = append(resp[d.ProductID], d.ProductImage)
+	}
+
+	return resp, nil
+}
+
+// ClaimPendingImage picks the oldest image still waiting for its variants and marks it
+// as processing. Images stuck in processing for longer than staleAfter, e.g. because
+// the worker died, are picked up again. It returns nil when there is nothing to do.
+func (r *shopRepository) ClaimPendingImage(ctx context.Context, staleAfter time.Duration) (*entity.ProductImage, error) {
+	var resp = new(entity.ProductImage)
+
+	query := `
+		UPDATE product_images SET variants_status = ?, variants_attempts = variants_attempts + 1, updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM product_images
+			WHERE variants_status = ?
+				OR (variants_status = ? AND updated_at < NOW() - CAST(? AS interval))
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, url, position, is_primary, variants, variants_status, variants_attempts, storage_key
+	`
+	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query),
+		entity.ImageVariantsProcessing,
+		entity.ImageVariantsPending,
+		entity.ImageVariantsProcessing,
+		fmt.Sprintf("%d seconds", int(staleAfter.Seconds())),
+	).StructScan(resp)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("repository::ClaimPendingImage - Failed to claim image")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// SaveImageVariants stores the generated variants of a claimed image and marks it ready.
+// It returns a 404 error when the image was deleted in the meantime.
+func (r *shopRepository) SaveImageVariants(ctx context.Context, imageID string, variants entity.ImageVariants) error {
+	query := `UPDATE product_images SET variants = ?, variants_status = ?, updated_at = NOW() WHERE id = ?`
+
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(query), variants, entity.ImageVariantsReady, imageID)
+	if err != nil {
+		log.Error().Err(err).Str("image_id", imageID).Msg("repository::SaveImageVariants - Failed to save variants")
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Gambar tidak ditemukan"))
+	}
+
+	return nil
+}
+
+// ReleaseImage hands a claimed image back after a failed attempt, either to be retried
+// or, once retry is false, marked as failed for good.
+func (r *shopRepository) ReleaseImage(ctx context.Context, imageID string, retry bool) error {
+	status := entity.ImageVariantsFailed
+	if retry {
+		status = entity.ImageVariantsPending
+	}
+
+	query := `UPDATE product_images SET variants_status = ?, updated_at = NOW() WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), status, imageID); err != nil {
+		log.Error().Err(err).Str("image_id", imageID).Msg("repository::ReleaseImage - Failed to release image")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const importColumns = `id, user_id, shop_id, format, dry_run, status, total_rows, created_rows, updated_rows, failed_rows, report, error, created_at, finished_at`
+
+// CreateProductImport queues the import of source into a shop of req.UserID.
+func (r *shopRepository) CreateProductImport(ctx context.Context, req *entity.CreateProductImportRequest, source []byte) (*entity.ProductImport, error) {
+	var resp = new(entity.ProductImport)
+
+	query := `
+		INSERT INTO product_imports (user_id, shop_id, format, dry_run, source)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING ` + importColumns
+	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query), req.UserID, req.ShopID, req.Format, req.DryRun, source).StructScan(resp)
+	if err != nil {
+		log.Error().Err(err).Str("shop_id", req.ShopID).Msg("repository::CreateProductImport - Failed to create import")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// GetProductShopID returns the shop a live product is sold by.
+func (r *shopRepository) GetProductShopID(ctx context.Context, productID string) (string, error) {
+	var shopID string
+
+	query := `SELECT shop_id FROM product WHERE id = ? AND deleted_at IS NULL`
+	err := r.db.GetContext(ctx, &shopID, r.db.Rebind(query), productID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", errmsg.NewCustomErrors(404, errmsg.WithMessage("Produk tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::GetProductShopID - Failed to get product")
+		return "", err
+	}
+
+	return shopID, nil
+}
+
+func (r *shopRepository) GetProductImport(ctx context.Context, req *entity.ProductImportRequest) (*entity.ProductImport, error) {
+	var resp = new(entity.ProductImport)
+
+	query := `SELECT ` + importColumns + ` FROM product_imports WHERE id = ? AND user_id = ?`
+	err := r.db.GetContext(ctx, resp, r.db.Rebind(query), req.Id, req.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Import tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::GetProductImport - Failed to get import")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ClaimPendingImport picks the oldest queued import, together with its file, and marks
+// it as processing. Imports stuck in processing for longer than staleAfter, e.g. because
+// the worker died, are only run again when they are dry runs; the others may already
+// have written part of their rows and are marked as failed instead.
+// It returns nil when there is nothing to do.
+func (r *shopRepository) ClaimPendingImport(ctx context.Context, staleAfter time.Duration) (*entity.ProductImport, error) {
+	var (
+		resp  = new(entity.ProductImport)
+		stale = fmt.Sprintf("%d seconds", int(staleAfter.Seconds()))
+	)
+
+	queryStale := `
+		UPDATE product_imports SET status = ?, error = ?, source = NULL, finished_at = NOW(), updated_at = NOW()
+		WHERE status = ? AND NOT dry_run AND updated_at < NOW() - CAST(? AS interval)
+	`
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(queryStale), entity.ImportFailed, "import terhenti sebelum selesai", entity.ImportProcessing, stale)
+	if err != nil {
+		log.Error().Err(err).Msg("repository::ClaimPendingImport - Failed to fail stale imports")
+		return nil, err
+	}
+
+	query := `
+		UPDATE product_imports SET status = ?, updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM product_imports
+			WHERE status = ?
+				OR (status = ? AND dry_run AND updated_at < NOW() - CAST(? AS interval))
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING source, ` + importColumns
+	err = r.db.QueryRowxContext(ctx, r.db.Rebind(query),
+		entity.ImportProcessing,
+		entity.ImportPending,
+		entity.ImportProcessing,
+		stale,
+	).StructScan(resp)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("repository::ClaimPendingImport - Failed to claim import")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// FinishProductImport stores the outcome of an import and drops its file. A non empty
+// failure marks the whole import as failed.
+func (r *shopRepository) FinishProductImport(ctx context.Context, id string, report entity.ImportRowResults, failure string) error {
+	status := entity.ImportDone
+	if failure != "" {
+		status = entity.ImportFailed
+	}
+
+	query := `
+		UPDATE product_imports SET
+			status = ?,
+			total_rows = ?,
+			created_rows = ?,
+			updated_rows = ?,
+			failed_rows = ?,
+			report = ?,
+			error = NULLIF(?, ''),
+			source = NULL,
+			finished_at = NOW(),
+			updated_at = NOW()
+		WHERE id = ?
+	`
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(query),
+		status,
+		len(report),
+		report.Count(entity.ImportRowCreated),
+		report.Count(entity.ImportRowUpdated),
+		report.Count(entity.ImportRowFailed),
+		report,
+		failure,
+		id,
+	)
+	if err != nil {
+		log.Error().Err(err).Str("import_id", id).Msg("repository::FinishProductImport - Failed to save import report")
+		return err
+	}
+
+	return nil
+}
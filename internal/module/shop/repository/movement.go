@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+const movementColumns = `id, variant_id, delta, balance, reason, actor, reference, note, created_at`
+
+// reconcileStock appends the ledger entries bringing the ledger of a product back in line
+// with its stock, one per SKU (or for the product itself when it has no SKUs) whose stock
+// changed. It runs at the end of every transaction that touches stock, after the product
+// row has been written, so changes to the same product are recorded one after another.
+func reconcileStock(ctx context.Context, tx *sqlx.Tx, productID string, src entity.MovementSource) ([]entity.StockMovement, error) {
+	var resp []entity.StockMovement
+
+	query := `
+		WITH actual AS (
+			SELECT id AS variant_id, stok FROM product_variants
+			WHERE product_id = ? AND deleted_at IS NULL
+			UNION ALL
+			-- the stock of a product with SKUs is only a mirror of theirs
+			SELECT NULL::uuid, CASE WHEN EXISTS (
+				SELECT 1 FROM product_variants
+				WHERE product_variants.product_id = product.id AND product_variants.deleted_at IS NULL
+			) THEN 0 ELSE stok END
+			FROM product WHERE id = ?
+		), ledger AS (
+			SELECT variant_id, SUM(delta) AS stok FROM stock_movements
+			WHERE product_id = ?
+			GROUP BY variant_id
+		)
+		INSERT INTO stock_movements (product_id, variant_id, delta, balance, reason, actor, reference, note)
+		SELECT
+			?,
+			COALESCE(actual.variant_id, ledger.variant_id),
+			COALESCE(actual.stok, 0) - COALESCE(ledger.stok, 0),
+			COALESCE(actual.stok, 0),
+			?, ?, NULLIF(?, ''), NULLIF(?, '')
+		FROM actual
+		FULL JOIN ledger
+			ON COALESCE(actual.variant_id, '00000000-0000-0000-0000-000000000000'::uuid)
+				= COALESCE(ledger.variant_id, '00000000-0000-0000-0000-000000000000'::uuid)
+		WHERE COALESCE(actual.stok, 0) <> COALESCE(ledger.stok, 0)
+		RETURNING ` + movementColumns
+	err := tx.SelectContext(ctx, &resp, tx.Rebind(query),
+		productID, productID, productID,
+		productID, src.Reason, src.Actor, src.Reference, src.Note,
+	)
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Any("source", src).Msg("repository::reconcileStock - Failed to record stock movements")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// CreateStockMovement applies a manual stock change to a product or one of its SKUs.
+func (r *shopRepository) CreateStockMovement(ctx context.Context, req *entity.CreateStockMovementRequest) (*entity.StockMovement, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CreateStockMovement - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stok, err := lockStock(ctx, tx, req.ProductID, req.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if stok+req.Delta < 0 {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("delta", "stok tidak boleh kurang dari 0."))
+	}
+
+	if req.VariantID != "" {
+		queryVariant := `UPDATE product_variants SET stok = stok + ?, updated_at = NOW() WHERE id = ?`
+		if _, err := tx.ExecContext(ctx, tx.Rebind(queryVariant), req.Delta, req.VariantID); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repository::CreateStockMovement - Failed to update variant stock")
+			return nil, err
+		}
+	}
+
+	queryProduct := `UPDATE product SET stok = stok + ?, updated_at = NOW() WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(queryProduct), req.Delta, req.ProductID); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CreateStockMovement - Failed to update product stock")
+		return nil, err
+	}
+
+	if err := checkReservedStock(ctx, tx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	movements, err := reconcileStock(ctx, tx, req.ProductID, entity.MovementSource{
+		Reason: req.Reason,
+		Actor:  req.UserID,
+		Note:   req.Note,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkLowStock(ctx, tx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	if err := checkRestock(ctx, tx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CreateStockMovement - Failed to commit transaction")
+		return nil, err
+	}
+
+	if len(movements) == 0 {
+		return nil, errmsg.NewCustomErrors(500, errmsg.WithMessage("Perubahan stok tidak tercatat"))
+	}
+
+	return &movements[0], nil
+}
+
+func (r *shopRepository) GetStockMovements(ctx context.Context, req *entity.StockMovementsRequest) (*entity.StockMovementsResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.StockMovement
+	}
+
+	var (
+		data []dao
+		resp = &entity.StockMovementsResponse{Items: make([]entity.StockMovement, 0, req.Paginate)}
+	)
+
+	queryStock := `SELECT stok FROM product WHERE id = ? AND deleted_at IS NULL`
+	if err := r.db.GetContext(ctx, &resp.Stok, r.db.Rebind(queryStock), req.ProductID); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::GetStockMovements - Failed to get product stock")
+		return nil, err
+	}
+
+	query := `
+		SELECT COUNT(id) OVER() AS total_data, ` + movementColumns + `
+		FROM stock_movements
+		WHERE product_id = ?
+			AND (? = '' OR variant_id = CAST(NULLIF(?, '') AS uuid))
+			AND (? = '' OR reason = ?)
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query),
+		req.ProductID,
+		req.VariantID, req.VariantID,
+		req.Reason, req.Reason,
+		req.Paginate, req.Paginate*(req.Page-1),
+	)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::GetStockMovements - Failed to get stock movements")
+		return nil, err
+	}
+
+	for _, d := range data {
+		resp.Items = append(resp.Items, d.StockMovement)
+	}
+
+	if len(data) > 0 {
+		resp.Meta.TotalData = data[0].TotalData
+	}
+	resp.Meta.CountTotalPage(req.Page, req.Paginate, resp.Meta.TotalData)
+
+	return resp, nil
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// UpdateProductStatus moves a product between draft, published and archived. Publishing
+// makes it live now and tells the followers of its shop; a product already live keeps
+// its publish time. A draft takes PublishAt as its schedule, none leaves it unscheduled.
+// A change of status is stored as a new revision of the product.
+func (r *shopRepository) UpdateProductStatus(ctx context.Context, req *entity.UpdateProductStatusRequest) (*entity.ProductStatusResponse, error) {
+	var current string
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateProductStatus - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	queryLock := `SELECT status FROM product WHERE id = ? AND deleted_at IS NULL FOR UPDATE`
+	err = tx.GetContext(ctx, &current, tx.Rebind(queryLock), req.ProductID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Produk tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateProductStatus - Failed to lock product")
+		return nil, err
+	}
+
+	// keep the product as it was before its first tracked change
+	if err := baselineRevision(ctx, tx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	resp, err := setProductStatus(ctx, tx, req.ProductID, current, req.Status, req.PublishAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Status != current {
+		if _, err := recordRevision(ctx, tx, req.ProductID, req.UserID, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateProductStatus - Failed to commit transaction")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// setProductStatus moves a product the caller holds from current to status, see
+// UpdateProductStatus.
+func setProductStatus(ctx context.Context, tx *sqlx.Tx, productID, current, status string, publishAt *time.Time) (*entity.ProductStatusResponse, error) {
+	var resp = new(entity.ProductStatusResponse)
+
+	query := `
+		UPDATE product SET
+			status = ?,
+			published_at = CASE
+				WHEN ? = 'draft' THEN CAST(? AS timestamptz)
+				WHEN ? = 'published' AND status <> 'published' THEN NOW()
+				ELSE published_at
+			END,
+			updated_at = NOW()
+		WHERE id = ?
+		RETURNING id, status, published_at
+	`
+	err := tx.QueryRowxContext(ctx, tx.Rebind(query),
+		status,
+		status,
+		publishAt,
+		status,
+		productID,
+	).StructScan(resp)
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Str("status", status).Msg("repository::setProductStatus - Failed to update product status")
+		return nil, err
+	}
+
+	if current != entity.ProductPublished && resp.Status == entity.ProductPublished {
+		if err := raiseShopEvent(ctx, tx, productID, entity.EventPublished); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+// PublishScheduled makes every draft whose publish time has come live, telling the
+// followers of their shops and storing a revision for each, made by no one. It returns
+// how many were published.
+func (r *shopRepository) PublishScheduled(ctx context.Context) (int64, error) {
+	var ids []string
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("repository::PublishScheduled - Failed to begin transaction")
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// products being edited right now are left for the next run
+	queryDue := `
+		SELECT id FROM product
+		WHERE status = 'draft' AND published_at <= NOW() AND deleted_at IS NULL
+		ORDER BY id
+		FOR UPDATE SKIP LOCKED
+	`
+	if err := tx.SelectContext(ctx, &ids, queryDue); err != nil {
+		log.Error().Err(err).Msg("repository::PublishScheduled - Failed to get scheduled products")
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if err := baselineRevision(ctx, tx, id); err != nil {
+			return 0, err
+		}
+
+		if _, err := setProductStatus(ctx, tx, id, entity.ProductDraft, entity.ProductPublished, nil); err != nil {
+			return 0, err
+		}
+
+		if _, err := recordRevision(ctx, tx, id, "", nil); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Msg("repository::PublishScheduled - Failed to commit transaction")
+		return 0, err
+	}
+
+	return int64(len(ids)), nil
+}
+
+// GetShopProducts lists the products of a shop for its owner, drafts and archived ones
+// included, last created first.
+func (r *shopRepository) GetShopProducts(ctx context.Context, req *entity.ShopProductsRequest) (*entity.ShopProductsResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.ShopProduct
+	}
+
+	var (
+		data []dao
+		resp = &entity.ShopProductsResponse{Items: make([]entity.ShopProduct, 0, req.Paginate)}
+	)
+
+	query := `
+		SELECT
+			COUNT(id) OVER() AS total_data,
+			id,
+			name,
+			ROW(harga, currency) AS harga,
+			stok,
+			status,
+			published_at,
+			created_at,
+			updated_at
+		FROM product
+		WHERE shop_id = ? AND deleted_at IS NULL AND (? = '' OR status = ?)
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query),
+		req.ShopID,
+		req.Status,
+		req.Status,
+		req.Paginate,
+		req.Paginate*(req.Page-1),
+	)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::GetShopProducts - Failed to get products")
+		return nil, err
+	}
+
+	for _, d := range data {
+		resp.Items = append(resp.Items, d.ShopProduct)
+	}
+
+	if len(data) > 0 {
+		resp.Meta.TotalData = data[0].TotalData
+	}
+	resp.Meta.CountTotalPage(req.Page, req.Paginate, resp.Meta.TotalData)
+
+	return resp, nil
+}
@@ -3,11 +3,16 @@ package repository
 import (
 	"codebase-app/internal/module/shop/entity"
 	"codebase-app/internal/module/shop/ports"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/types"
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"strconv"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 )
 
@@ -48,7 +53,7 @@ func (r *shopRepository) GetShop(ctx context.Context, req *entity.GetShopRequest
 	var resp = new(entity.GetShopResponse)
 	// Your code here
 	query := `
-		SELECT name, description, terms
+		SELECT name, description, terms, follower_count, ` + shopStatusColumns + `
 		FROM shops
 		WHERE id = ? AND deleted_at is NULL
 	`
@@ -105,48 +110,99 @@ func (r *shopRepository) UpdateShop(ctx context.Context, req *entity.UpdateShopR
 	return resp, nil
 }
 
+// CheckShopOwner makes sure the shop exists and belongs to userID.
+func (r *shopRepository) CheckShopOwner(ctx context.Context, shopID, userID string) error {
+	var owner string
+
+	query := `SELECT user_id FROM shops WHERE id = ? AND deleted_at IS NULL`
+	err := r.db.GetContext(ctx, &owner, r.db.Rebind(query), shopID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Toko tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Str("shop_id", shopID).Msg("repository::CheckShopOwner - Failed to get shop")
+		return err
+	}
+
+	if owner != userID {
+		log.Warn().Str("shop_id", shopID).Str("user_id", userID).Msg("repository::CheckShopOwner - Shop is not owned by user")
+		return errmsg.NewCustomErrors(403, errmsg.WithMessage("Toko bukan milik anda"))
+	}
+
+	return nil
+}
+
 func (r *shopRepository) GetShops(ctx context.Context, req *entity.ShopsRequest) (*entity.ShopsResponse, error) {
 	type dao struct {
-		TotalData int `db:"total_data"`
+		TotalData int    `db:"total_data"`
+		SortValue string `db:"sort_value"`
 		entity.ShopItem
 	}
 
 	var (
-		resp = new(entity.ShopsResponse)
-		data = make([]dao, 0, req.Paginate)
+		resp   = new(entity.ShopsResponse)
+		data   = make([]dao, 0, req.Paginate+1)
+		args   = []any{req.UserId}
+		keyset = ""
+		offset = req.Paginate * (req.Page - 1)
 	)
 	resp.Items = make([]entity.ShopItem, 0, req.Paginate)
 
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor, entity.SortNewest)
+		if err != nil {
+			log.Warn().Err(err).Any("payload", req).Msg("repository::GetShops - Invalid cursor")
+			return nil, err
+		}
+		keyset = "AND (created_at, id) < (CAST(? AS timestamptz), CAST(? AS uuid))"
+		args = append(args, cursor.Value, cursor.Id)
+		offset = 0
+	}
+
+	// one extra row tells whether there is a next page
 	query := `
 		SELECT
 			COUNT(id) OVER() as total_data,
+			created_at::text AS sort_value,
 			id,
 			name
 		FROM shops
 		WHERE
 			deleted_at IS NULL
 			AND user_id = ?
+			` + keyset + `
+		ORDER BY created_at DESC, id DESC
 		LIMIT ? OFFSET ?
 	`
+	args = append(args, req.Paginate+1, offset)
 
-	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query),
-		req.UserId,
-		req.Paginate,
-		req.Paginate*(req.Page-1),
-	)
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
 	if err != nil {
 		log.Error().Err(err).Any("payload", req).Msg("repository::GetShops - Failed to get shops")
 		return nil, err
 	}
 
-	if len(data) > 0 {
-		resp.Meta.TotalData = data[0].TotalData
+	if len(data) > req.Paginate {
+		data = data[:req.Paginate]
+		last := data[len(data)-1]
+		resp.Meta.NextCursor = types.Cursor{Sort: entity.SortNewest, Value: last.SortValue, Id: last.Id}.Encode()
 	}
 
 	for _, d := range data {
 		resp.Items = append(resp.Items, d.ShopItem)
 	}
 
+	if req.Cursor != "" {
+		// the window count only covers rows after the cursor, so totals are left out
+		resp.Meta.Cursor = req.Cursor
+		resp.Meta.Paginate = req.Paginate
+		return resp, nil
+	}
+
+	if len(data) > 0 {
+		resp.Meta.TotalData = data[0].TotalData
+	}
+
 	resp.Meta.CountTotalPage(req.Page, req.Paginate, resp.Meta.TotalData)
 
 	return resp, nil
@@ -155,53 +211,128 @@ func (r *shopRepository) GetShops(ctx context.Context, req *entity.ShopsRequest)
 func (r *shopRepository) CreateProduct(ctx context.Context, req *entity.CreateProductRequest) (*entity.ProductResponse, error) {
 	var resp = new(entity.ProductResponse)
 
-	queryproduct := `INSERT INTO product (user_id, shop_id, name, description, harga, stok, merek) VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id, user_id, shop_id, name, description, harga, stok, merek`
-	err1 := r.db.QueryRowContext(ctx, r.db.Rebind(queryproduct),
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CreateProduct - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	queryproduct := `INSERT INTO product (user_id, shop_id, name, description, harga, currency, stok, merek, low_stock_threshold, status, published_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CASE WHEN ? = 'published' THEN NOW() ELSE CAST(? AS timestamptz) END) RETURNING id, user_id, shop_id, name, description, ROW(harga, currency), stok, merek, low_stock_threshold, status, published_at, (SELECT ` + shopOpen + ` FROM shops WHERE shops.id = product.shop_id)`
+	err1 := tx.QueryRowContext(ctx, tx.Rebind(queryproduct),
 		req.UserID,
 		req.ShopID,
 		req.Name,
 		req.Description,
 		req.Harga,
+		req.Harga.CurrencyCode(),
 		req.Stok,
 		req.Merek,
-	).Scan(&resp.ID, &resp.UserID, &resp.ShopID, &resp.Nama, &resp.Description, &resp.Harga, &resp.Stok, &resp.Merek)
+		req.LowStockThreshold,
+		req.Status,
+		req.Status,
+		req.PublishAt,
+	).Scan(&resp.ID, &resp.UserID, &resp.ShopID, &resp.Nama, &resp.Description, &resp.Harga, &resp.Stok, &resp.Merek, &resp.LowStockThreshold, &resp.Status, &resp.PublishedAt, &resp.Available)
 	if err1 != nil {
 		log.Error().Err(err1).Any("payload", req).Msg("repository::CreateProduct - Failed to create product")
 		return nil, err1
 	}
 
-	queryKategori := `INSERT INTO kategori (product_id, name) VALUES (?, ?) RETURNING name`
-	for _, v := range req.Kategori {
-		_, err2 := r.db.ExecContext(ctx, r.db.Rebind(queryKategori), resp.ID, v.Name)
+	if err := r.saveCategories(ctx, tx, resp.ID, req.Kategori); err != nil {
+		return nil, err
+	}
+
+	if req.HasVariants() {
+		resp.Options, resp.Variants, err = r.saveVariants(ctx, tx, resp.ID, req.Options, req.Variants)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = reconcileStock(ctx, tx, resp.ID, entity.MovementSource{
+		Reason: entity.MovementRestock,
+		Actor:  req.UserID,
+		Note:   "stok awal",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkLowStock(ctx, tx, resp.ID); err != nil {
+		return nil, err
+	}
+
+	if _, err := recordRevision(ctx, tx, resp.ID, req.UserID, nil); err != nil {
+		return nil, err
+	}
 
-		if err2 != nil {
-			log.Error().Err(err2).Any("payload", req).Msg("repository::CreateKategori - Failed to create kategori")
-			return nil, err2
+	if resp.Status == entity.ProductPublished {
+		if err := raiseShopEvent(ctx, tx, resp.ID, entity.EventPublished); err != nil {
+			return nil, err
 		}
+	}
+
+	if err := checkRestock(ctx, tx, resp.ID); err != nil {
+		return nil, err
+	}
 
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CreateProduct - Failed to commit transaction")
+		return nil, err
+	}
+
+	categories, err := r.getCategories(ctx, []string{resp.ID})
+	if err != nil {
+		return nil, err
 	}
 
-	resp.Kategori = req.Kategori
+	resp.Kategori = categories[resp.ID]
+	resp.MinHarga, resp.MaxHarga = priceRange(resp.Harga, resp.Variants)
 
 	return resp, nil
 
 }
 
-func (r *shopRepository) GetDetailShopAndProduct(ctx context.Context, id string, paginate int, page int) (*entity.DetailShopAndProduct, error) {
-	var resp = new(entity.DetailShopAndProduct)
+func (r *shopRepository) GetDetailShopAndProduct(ctx context.Context, req *entity.DetailShopRequest) (*entity.DetailShopAndProduct, error) {
+	var (
+		resp   = new(entity.DetailShopAndProduct)
+		id     = req.Id
+		args   = []any{req.Id}
+		keyset = ""
+		total  = "COUNT(product.id) OVER()"
+		offset = req.Paginate * (req.Page - 1)
+	)
+
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor, entity.SortNewest)
+		if err != nil {
+			log.Warn().Err(err).Any("payload", req).Msg("repository::GetDetailShopAndProduct - Invalid cursor")
+			return nil, err
+		}
+		keyset = "AND (product.published_at, product.id) < (CAST(? AS timestamptz), CAST(? AS uuid))"
+		args = append(args, cursor.Value, cursor.Id)
+		total = "0"
+		offset = 0
+	}
+	args = append(args, req.Paginate+1, offset)
 
 	type daoshop struct {
-		Name        string `db:"name"`
-		Description string `db:"description"`
-		Terms       string `db:"terms"`
+		Name          string `db:"name"`
+		Description   string `db:"description"`
+		Terms         string `db:"terms"`
+		Terjual       int    `db:"terjual"`
+		FollowerCount int64  `db:"follower_count"`
+		entity.ShopStatus
 	}
 	type daoproduct struct {
-		ProductName       string `db:"product_name"`
-		KategoriProductID string `db:"kategori_productid"`
-		ProductDesc       string `db:"product_description"`
-		ProductHarga      int    `db:"product_harga"`
-		ProductStok       int    `db:"product_stok"`
-		KategoriNames     string `db:"kategori_name"`
+		TotalData    int         `db:"total_data"`
+		SortValue    string      `db:"sort_value"`
+		ProductID    string      `db:"product_id"`
+		ProductName  string      `db:"product_name"`
+		ProductDesc  string      `db:"product_description"`
+		ProductHarga types.Money `db:"product_harga"`
+		ProductStok  int         `db:"product_stok"`
+		ProductSold  int         `db:"product_terjual"`
 	}
 
 	var datashop []daoshop
@@ -215,7 +346,7 @@ func (r *shopRepository) GetDetailShopAndProduct(ctx context.Context, id string,
 	// Goroutine untuk menjalankan query shop
 	go func() {
 		defer close(shopChan)
-		shopErr = r.db.SelectContext(ctx, &datashop, r.db.Rebind(`SELECT name, description, terms FROM shops WHERE id = ?`), id)
+		shopErr = r.db.SelectContext(ctx, &datashop, r.db.Rebind(`SELECT name, description, terms, terjual, follower_count, `+shopStatusColumns+` FROM shops WHERE id = ? AND deleted_at IS NULL`), id)
 		if shopErr != nil {
 			log.Error().Err(shopErr).Any("payload", id).Msg("repository::GetDetailShopAndProduct Shop - Failed to get Get Detail Shop And Product")
 		}
@@ -225,10 +356,13 @@ func (r *shopRepository) GetDetailShopAndProduct(ctx context.Context, id string,
 	// Goroutine untuk menjalankan query product
 	go func() {
 		defer close(productChan)
-		productErr = r.db.SelectContext(ctx, &dataproduct, r.db.Rebind(`SELECT product.name as product_name, product.description as product_description, product.harga as product_harga,
-			product.stok as product_stok, kategori.product_id as kategori_productid, kategori.name as kategori_name 
-			FROM product JOIN kategori ON product.id = kategori.product_id 
-			WHERE shop_id = ? LIMIT ? OFFSET ?`), id, 4, 4*(page-1))
+		productErr = r.db.SelectContext(ctx, &dataproduct, r.db.Rebind(`SELECT `+total+` as total_data, product.published_at::text as sort_value,
+			product.id as product_id, product.name as product_name, product.description as product_description,
+			ROW(product.harga, product.currency) as product_harga, product.stok as product_stok, product.terjual as product_terjual
+			FROM product
+			WHERE shop_id = ? AND deleted_at IS NULL AND status = 'published' `+keyset+`
+			ORDER BY product.published_at DESC, product.id DESC
+			LIMIT ? OFFSET ?`), args...)
 		if productErr != nil {
 			log.Error().Err(productErr).Any("payload", id).Msg("repository::GetDetailShopAndProduct Product - Failed to get Get Detail Shop And Product")
 		}
@@ -239,139 +373,196 @@ func (r *shopRepository) GetDetailShopAndProduct(ctx context.Context, id string,
 	datashop = <-shopChan
 	dataproduct = <-productChan
 
+	if shopErr != nil {
+		return nil, shopErr
+	}
+	if productErr != nil {
+		return nil, productErr
+	}
+
+	// one extra row tells whether there is a next page
+	if len(dataproduct) > req.Paginate {
+		dataproduct = dataproduct[:req.Paginate]
+		last := dataproduct[len(dataproduct)-1]
+		resp.Meta.NextCursor = types.Cursor{Sort: entity.SortNewest, Value: last.SortValue, Id: last.ProductID}.Encode()
+	}
+
 	if len(datashop) > 0 {
 		resp.Name = datashop[0].Name
 		resp.Description = datashop[0].Description
 		resp.Terms = datashop[0].Terms
+		resp.Terjual = datashop[0].Terjual
+		resp.FollowerCount = datashop[0].FollowerCount
+		resp.ShopStatus = datashop[0].ShopStatus
 	}
-	resp.Terjual = 0
 
 	productMap := make(map[string]*entity.ProductResponseDetail)
 
+	productIDs := make([]string, 0, len(dataproduct))
+
 	for _, row := range dataproduct {
-		if _, exists := productMap[row.ProductName]; !exists {
-			productMap[row.ProductName] = &entity.ProductResponseDetail{
-				ID:          row.ProductName,
+		if _, exists := productMap[row.ProductID]; !exists {
+			productMap[row.ProductID] = &entity.ProductResponseDetail{
+				ID:          row.ProductID,
 				Nama:        row.ProductName,
 				Description: row.ProductDesc,
 				Harga:       row.ProductHarga,
 				Stok:        row.ProductStok,
+				Terjual:     row.ProductSold,
 			}
+			productIDs = append(productIDs, row.ProductID)
 		}
+	}
 
-		productMap[row.ProductName].Kategori = append(productMap[row.ProductName].Kategori, entity.KategoriRequest{
-			ProductID: row.KategoriProductID,
-			Name:      row.KategoriNames,
-		})
+	categories, err := r.getCategories(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := r.getImages(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	flashSales, err := r.getFlashSales(ctx, productIDs)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, product := range productMap {
+	for _, id := range productIDs {
+		product := productMap[id]
+		product.Kategori = categories[product.ID]
+		product.Images = images[product.ID]
+		product.FlashSale = flashSales[product.ID]
+		product.HargaEfektif = entity.EffectivePrice(product.Harga, product.FlashSale)
 		resp.DaftarProduct = append(resp.DaftarProduct, *product)
 	}
-	resp.Meta.Page = page
-	resp.Meta.Paginate = 4
-	resp.Meta.TotalData = len(resp.DaftarProduct)
-	resp.Meta.CountTotalPage(resp.Meta.Page, resp.Meta.Paginate, resp.Meta.TotalData)
+
+	resp.ShopID = req.Id
+
+	if req.Cursor != "" {
+		resp.Meta.Cursor = req.Cursor
+		resp.Meta.Paginate = req.Paginate
+		return resp, nil
+	}
+
+	if len(dataproduct) > 0 {
+		resp.Meta.TotalData = dataproduct[0].TotalData
+	}
+	resp.Meta.CountTotalPage(req.Page, req.Paginate, resp.Meta.TotalData)
 	return resp, nil
 }
 
 func (r *shopRepository) GetAllProduct(ctx context.Context, req *entity.ProductFilter) (*entity.ProductsResponse, error) {
 	type dao struct {
+		TotalData int    `db:"total_data"`
+		SortValue string `db:"sort_value"`
 		entity.ProductResponseDashboard
 	}
 
 	var data []dao
 	var resp = new(entity.ProductsResponse)
+
+	req.SetDefaultFilter()
 	resp.Product = make([]entity.ProductResponseDashboard, 0, req.Pagination)
 
-	var query = `SELECT DISTINCT
-				product.id AS id,
-				product.user_id AS user_id,
-				shops.name AS shop_name,
-				product.name AS name, 
-				product.harga AS harga, 
-				product.penilaian AS penilaian, 
-				product.merek AS merek,
-				product.stok AS stok,
-				kategori.name AS kategori
-			FROM 
-				product
-			JOIN 
-				kategori ON product.id = kategori.product_id
-			JOIN 
-				shops ON shops.id = product.shop_id
-			WHERE
-				product.merek ILIKE '%' || ? || '%'
-				AND product.name ILIKE '%' || ? || '%'
-				AND CAST(product.harga AS numeric) >= ?
-				AND CAST(product.harga AS numeric) <= ? 
-				AND kategori.name ILIKE '%' || ? || '%'
-				AND product.penilaian = ?
-				AND product.deleted_at IS NULL
-			LIMIT ? OFFSET ?;`
+	var (
+		where, args = productFilter(req, true)
+		tsquery     = searchQuery(req.Q)
+		sortKey     = productSortKey(req.Sort, tsquery != "")
+		search      = ""
+		rank        = "0"
+		snippet     = "''"
+		total       = "COUNT(product.id) OVER()"
+		offset      = req.Pagination * (req.Page - 1)
+		searchArgs  []any
+	)
+
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor, req.Sort)
+		if err != nil {
+			log.Warn().Err(err).Any("payload", req).Msg("repository::GetAllProducts - Invalid cursor")
+			return nil, err
+		}
+		where += "\n\t\t\t\tAND " + sortKey.after()
+		args = append(args, cursor.Value, cursor.Id)
+		total = "0"
+		offset = 0
+	}
+
+	if tsquery != "" {
+		search = "CROSS JOIN (SELECT to_tsquery('simple', ?) AS query) search"
+		rank = "ts_rank(product.search_vector, search.query)"
+		snippet = `ts_headline('simple', product.name || ' - ' || product.description, search.query,
+					'StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=10, MaxFragments=2')`
+		searchArgs = []any{tsquery}
+	}
 
-	var query2 = `SELECT DISTINCT
+	// one extra row tells whether there is a next page
+	var query = `SELECT
+				` + total + ` AS total_data,
+				(` + sortKey.key + `)::text AS sort_value,
 				product.id AS id,
 				product.user_id AS user_id,
 				shops.name AS shop_name,
 				product.name AS name, 
-				product.harga AS harga, 
-				product.penilaian AS penilaian, 
-				product.merek AS merek,
+				ROW(product.harga, product.currency) AS harga,
+				ROW(COALESCE(variant_price.min_harga, product.harga), product.currency) AS min_harga,
+				ROW(COALESCE(variant_price.max_harga, product.harga), product.currency) AS max_harga,
+				COALESCE(product.penilaian, 0) AS penilaian, 
+				product.review_count AS review_count,
+				product.terjual AS terjual,
+				COALESCE(product.merek, '') AS merek,
 				product.stok AS stok,
-				kategori.name AS kategori
+				` + shopOpen + ` AS available,
+				` + rank + ` AS rank,
+				` + snippet + ` AS snippet
 			FROM 
 				product
-			JOIN 
-				kategori ON product.id = kategori.product_id
 			JOIN 
 				shops ON shops.id = product.shop_id
+			` + search + `
+			LEFT JOIN LATERAL (
+				SELECT MIN(harga) AS min_harga, MAX(harga) AS max_harga
+				FROM product_variants
+				WHERE product_variants.product_id = product.id AND product_variants.deleted_at IS NULL
+			) variant_price ON TRUE
 			WHERE
-				product.merek ILIKE '%' || ? || '%'
-				AND product.name ILIKE '%' || ? || '%'
-				AND CAST(product.harga AS numeric) >= ?
-				AND CAST(product.harga AS numeric) <= ? 
-				AND kategori.name ILIKE '%' || ? || '%'
-				AND product.deleted_at IS NULL
+				` + where + `
+			ORDER BY ` + sortKey.orderBy() + `
 			LIMIT ? OFFSET ?;`
 
-	req.SetDefaultFilter()
-
-	if req.Penilaian < 1 {
-		err := r.db.SelectContext(ctx, &data, r.db.Rebind(query2),
-			req.Merek,
-			req.Name,
-			req.MinHarga,
-			req.MaxHarga,
-			req.Kategori,
+	args = append(searchArgs, args...)
+	args = append(args, req.Pagination+1, offset)
 
-			req.Pagination,
-			req.Pagination*(req.Page-1))
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::GetAllProducts - Failed to get products")
+		return nil, err
+	}
 
-		if err != nil {
-			log.Error().Err(err).Msg("repository::GetAllProducts - Failed to get products")
-			return nil, err
-		}
-	} else if req.Penilaian > 0 {
-
-		err := r.db.SelectContext(ctx, &data, r.db.Rebind(query),
-			req.Merek,
-			req.Name,
-			req.MinHarga,
-			req.MaxHarga,
-			req.Kategori,
-			req.Penilaian,
-			req.Pagination,
-			req.Pagination*(req.Page-1))
+	if len(data) > req.Pagination {
+		data = data[:req.Pagination]
+		last := data[len(data)-1]
+		resp.Meta.NextCursor = types.Cursor{Sort: req.Sort, Value: last.SortValue, Id: last.ID}.Encode()
+	}
 
-		if err != nil {
-			log.Error().Err(err).Msg("repository::GetAllProducts - Failed to get products")
-			return nil, err
+	if req.Cursor != "" {
+		resp.Meta.Cursor = req.Cursor
+		resp.Meta.Paginate = req.Pagination
+	} else {
+		if len(data) > 0 {
+			resp.Meta.TotalData = data[0].TotalData
 		}
+		resp.Meta.CountTotalPage(req.Page, req.Pagination, resp.Meta.TotalData)
 	}
 
-	productMap := make(map[string]*entity.ProductResponseDashboard)
+	var (
+		productMap = make(map[string]*entity.ProductResponseDashboard)
+		productIDs = make([]string, 0, len(data))
+	)
 
+	// productIDs keeps the database order, productMap only dedups
 	for _, row := range data {
 		if _, exists := productMap[row.ID]; !exists {
 			productMap[row.ID] = &entity.ProductResponseDashboard{
@@ -379,20 +570,61 @@ func (r *shopRepository) GetAllProduct(ctx context.Context, req *entity.ProductF
 				UserID:    row.UserID,
 				ShopID:    row.ShopID,
 				Nama:      row.Nama,
-				Kategori:  row.Kategori,
 				Merek:     row.Merek,
 				Penilaian: row.Penilaian,
+				Ulasan:    row.Ulasan,
+				Terjual:   row.Terjual,
 				Harga:     row.Harga,
 				Stok:      row.Stok,
+				MinHarga:  row.MinHarga,
+				MaxHarga:  row.MaxHarga,
+				Rank:      row.Rank,
+				Snippet:   row.Snippet,
+				Available: row.Available,
 			}
+			productIDs = append(productIDs, row.ID)
 		}
 
 	}
 
-	for _, product := range productMap {
+	_, variants, err := r.getVariants(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := r.getCategories(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := r.getImages(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	flashSales, err := r.getFlashSales(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range productIDs {
+		product := productMap[id]
+		product.Kategori = categories[product.ID]
+		product.Variants = variants[product.ID]
+		product.Images = images[product.ID]
+		product.FlashSale = flashSales[product.ID]
+		product.HargaEfektif = entity.EffectivePrice(product.Harga, product.FlashSale)
 		resp.Product = append(resp.Product, *product)
 	}
 
+	facets, err := r.getFacets(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Facets = *facets
+	resp.Q = req.Q
+	resp.Sort = req.Sort
 	resp.KategoriFilter = req.Kategori
 	resp.MerekFilter = req.Merek
 	resp.Name = req.Name
@@ -409,16 +641,22 @@ func (r *shopRepository) GetDetailProduct(ctx context.Context, id string) (*enti
 	resp := &entity.ProductResponse{}
 
 	type dao struct {
-		ID          string `db:"id_product"`
-		UserID      string `db:"product_user_id"`
-		NamaToko    string `db:"nama_toko"`
-		Name        string `db:"name_product"`
-		Harga       string `db:"harga_product"`
-		Description string `db:"description_product"`
-		Stok        int    `db:"stok_product"`
-		Rating      int    `db:"rating"`
-		Kategori    string `db:"kategori_product"`
-		Merek       string `db:"merek_product"`
+		ID          string        `db:"id_product"`
+		UserID      string        `db:"product_user_id"`
+		NamaToko    string        `db:"nama_toko"`
+		Name        string        `db:"name_product"`
+		Harga       types.Money   `db:"harga_product"`
+		Description string        `db:"description_product"`
+		Stok        int           `db:"stok_product"`
+		Rating      float64       `db:"rating"`
+		ReviewCount int           `db:"review_count"`
+		RatingDist  pq.Int64Array `db:"rating_distribution"`
+		Merek       string        `db:"merek_product"`
+		Threshold   int           `db:"low_stock_threshold"`
+		Terjual     int           `db:"terjual"`
+		Available   bool          `db:"available"`
+		Status      string        `db:"status"`
+		PublishedAt *time.Time    `db:"published_at"`
 	}
 
 	var data []dao
@@ -427,15 +665,20 @@ func (r *shopRepository) GetDetailProduct(ctx context.Context, id string) (*enti
 					product.user_id as product_user_id,
 					 shops.name as nama_toko, 
 					 product.name as name_product, 
-					 product.harga as harga_product, 
+					 ROW(product.harga, product.currency) as harga_product,
 					 product.description as description_product, 
 					 product.stok as stok_product, 
-					 product.penilaian as rating,
+					 COALESCE(product.penilaian, 0) as rating,
+					 product.review_count,
+					 product.rating_distribution,
 					 product.merek as merek_product,
-					 kategori.name as kategori_product
+					 product.low_stock_threshold,
+					 product.terjual,
+					 ` + shopOpen + ` as available,
+					 product.status,
+					 product.published_at
 				from product
 				join shops on shops.id = product.shop_id
-				join kategori on product.id = kategori.product_id
 				where product.id = ? and product.deleted_at is null`
 
 	log.Debug().Str("id", id).Msg("repository::Get Detail Product - ID Value")
@@ -451,60 +694,65 @@ func (r *shopRepository) GetDetailProduct(ctx context.Context, id string) (*enti
 		return nil, err
 	}
 
-	for _, d := range data {
-		resp.Kategori = append(resp.Kategori, entity.KategoriRequest{
-			ProductID: d.ID,
-			Name:      d.Kategori,
-		})
+	if len(data) == 0 {
+		log.Warn().Str("id", id).Msg("repository::GetDetailProduct - Product not found")
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Produk tidak ditemukan"))
 	}
 
-	harga, _ := strconv.Atoi(data[0].Harga)
 	resp.UserID = data[0].UserID
 	resp.Nama = data[0].Name
 	resp.ShopID = data[0].NamaToko
-	resp.Harga = harga
+	resp.Harga = data[0].Harga
 	resp.Description = data[0].Description
 	resp.Merek = data[0].Merek
 	resp.Stok = data[0].Stok
+	resp.LowStockThreshold = data[0].Threshold
+	resp.Rating = ratingSummary(data[0].Rating, data[0].ReviewCount, data[0].RatingDist)
+	resp.Terjual = data[0].Terjual
+	resp.Available = data[0].Available
+	resp.Status = data[0].Status
+	resp.PublishedAt = data[0].PublishedAt
 	resp.ID = data[0].ID
 
+	options, variants, err := r.getVariants(ctx, []string{resp.ID})
+	if err != nil {
+		return nil, err
+	}
+	resp.Options = options[resp.ID]
+	resp.Variants = variants[resp.ID]
+	resp.MinHarga, resp.MaxHarga = priceRange(resp.Harga, resp.Variants)
+
+	categories, err := r.getCategories(ctx, []string{resp.ID})
+	if err != nil {
+		return nil, err
+	}
+	resp.Kategori = categories[resp.ID]
+
+	images, err := r.getImages(ctx, []string{resp.ID})
+	if err != nil {
+		return nil, err
+	}
+	resp.Images = images[resp.ID]
+
+	flashSales, err := r.getFlashSales(ctx, []string{resp.ID})
+	if err != nil {
+		return nil, err
+	}
+	resp.FlashSale = flashSales[resp.ID]
+	resp.HargaEfektif = entity.EffectivePrice(resp.Harga, resp.FlashSale)
+
 	return resp, nil
 
 }
 
 func (r *shopRepository) DeleteProductByID(ctx context.Context, id string) error {
+	// category links are kept so a soft deleted product can be restored as it was
 	queryproduct := `update product set deleted_at = NOW() where id = ?`
-	querykategori := `update kategori set deleted_at = NOW() where product_id = ?`
-
-	errChan := make(chan error, 2)
-
-	// Jalankan querykategori di goroutine
-	go func() {
-		_, err := r.db.ExecContext(ctx, r.db.Rebind(querykategori), id)
-		if err != nil {
-			log.Error().Err(err).Any("payload", id).Msg("repository::DeleteShop - Failed to delete shop")
-			errChan <- err
-			return
-		}
-		errChan <- nil
-	}()
-
-	// Jalankan queryproduct di goroutine
-	go func() {
-		_, err := r.db.ExecContext(ctx, r.db.Rebind(queryproduct), id)
-		if err != nil {
-			log.Error().Err(err).Any("payload", id).Msg("repository::DeleteShop Product - Failed to delete shop product")
-			errChan <- err
-			return
-		}
-		errChan <- nil
-	}()
 
-	// Tunggu hasil dari kedua goroutine
-	for i := 0; i < 2; i++ {
-		if err := <-errChan; err != nil {
-			return err
-		}
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(queryproduct), id)
+	if err != nil {
+		log.Error().Err(err).Any("payload", id).Msg("repository::DeleteProductByID - Failed to delete product")
+		return err
 	}
 
 	return nil
@@ -512,48 +760,138 @@ func (r *shopRepository) DeleteProductByID(ctx context.Context, id string) error
 
 func (r *shopRepository) UpdateProductByID(ctx context.Context, req *entity.UpdateProductRequest) (*entity.UpdateProductRequest, error) {
 	var resp = new(entity.UpdateProductRequest)
-	queryproduct := `update product set name = ?, description = ?, harga = ?, stok = ?, merek = ? where id = ? and deleted_at is null returning id, name, description, harga, stok, merek`
 
-	err1 := r.db.QueryRowContext(ctx, r.db.Rebind(queryproduct),
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateProductByID - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, req.ID); err != nil {
+		return nil, err
+	}
+
+	// keep the product as it was before its first tracked change
+	if err := baselineRevision(ctx, tx, req.ID); err != nil {
+		return nil, err
+	}
+
+	// the stock of a product whose SKUs are kept is theirs, as locked above; the value the
+	// service read before the lock may already be out of date
+	queryproduct := `update product set name = ?, description = ?, harga = ?, currency = ?,
+		stok = case when ? and exists (
+			select 1 from product_variants where product_variants.product_id = product.id and product_variants.deleted_at is null
+		) then stok else ? end,
+		merek = ?, low_stock_threshold = COALESCE(?, low_stock_threshold) where id = ? and deleted_at is null returning id, name, description, ROW(harga, currency), stok, merek, low_stock_threshold`
+
+	err1 := tx.QueryRowContext(ctx, tx.Rebind(queryproduct),
 		req.Name,
 		req.Description,
 		req.Harga,
+		req.Harga.CurrencyCode(),
+		req.Variants == nil,
 		req.Stok,
 		req.Merek,
+		req.LowStockThreshold,
 		req.ID).Scan(
 		&resp.ID,
 		&resp.Name,
 		&resp.Description,
 		&resp.Harga,
 		&resp.Stok,
-		&resp.Merek)
+		&resp.Merek,
+		&resp.LowStockThreshold)
 	if err1 != nil {
 		log.Error().Err(err1).Any("payload", req).Msg("repository::UpdateProductByID - Failed to update product")
 		return nil, err1
 	}
 
-	// Delete existing categories for the product
-	queryDelete := `DELETE FROM kategori WHERE product_id = ?`
-	_, errDelete := r.db.ExecContext(ctx, r.db.Rebind(queryDelete), req.ID)
-	if errDelete != nil {
-		log.Error().Err(errDelete).Any("payload", req).Msg("repository::UpdateProductByID kategori - Failed to delete existing categories")
-		return nil, errDelete
+	// Replace category links only when the request carries them
+	if req.Kategori != nil {
+		if err := r.saveCategories(ctx, tx, req.ID, req.Kategori); err != nil {
+			return nil, err
+		}
+		resp.Kategori = req.Kategori
+	}
+
+	// Replace SKUs only when the request carries them
+	if req.Variants != nil {
+		if len(req.Variants) > 0 {
+			if err := checkFlashSaleVariants(ctx, tx, req.ID); err != nil {
+				return nil, err
+			}
+		}
+		if _, _, err := r.saveVariants(ctx, tx, req.ID, req.Options, req.Variants); err != nil {
+			return nil, err
+		}
+		resp.Options = req.Options
+		resp.Variants = req.Variants
+	}
+
+	if err := checkReservedStock(ctx, tx, req.ID); err != nil {
+		return nil, err
 	}
 
-	// Insert new categories
-	for _, v := range req.Kategori {
-		queryInsert := `INSERT INTO kategori (name, product_id) VALUES (?, ?)`
-		_, errInsert := r.db.ExecContext(ctx, r.db.Rebind(queryInsert), v.Name, req.ID)
-		if errInsert != nil {
-			log.Error().Err(errInsert).Any("payload", req).Msg("repository::UpdateProductByID kategori - Failed to insert new categories")
-			return nil, errInsert
+	if req.Status != "" {
+		var current string
+		if err := tx.GetContext(ctx, &current, tx.Rebind(`SELECT status FROM product WHERE id = ?`), req.ID); err != nil {
+			log.Error().Err(err).Any("payload", req).Msg("repository::UpdateProductByID - Failed to get product status")
+			return nil, err
+		}
+		if current != req.Status {
+			if _, err := setProductStatus(ctx, tx, req.ID, current, req.Status, nil); err != nil {
+				return nil, err
+			}
 		}
-		resp.Kategori = append(resp.Kategori, entity.KategoriRequest{
-			ProductID: req.ID,
-			Name:      v.Name,
-		})
+	}
+
+	_, err = reconcileStock(ctx, tx, req.ID, entity.MovementSource{
+		Reason: entity.MovementAdjustment,
+		Actor:  req.UserID,
+		Note:   "ubah produk",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkLowStock(ctx, tx, req.ID); err != nil {
+		return nil, err
+	}
+
+	if err := checkRestock(ctx, tx, req.ID); err != nil {
+		return nil, err
+	}
+
+	resp.Revision, err = recordRevision(ctx, tx, req.ID, req.UserID, req.RevertedFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateProductByID - Failed to commit transaction")
+		return nil, err
 	}
 
 	return resp, nil
 
 }
+
+// priceRange returns the cheapest and most expensive SKU price, falling back to the product price.
+func priceRange(harga types.Money, variants []entity.ProductVariant) (min types.Money, max types.Money) {
+	if len(variants) == 0 {
+		return harga, harga
+	}
+
+	min, max = variants[0].Harga, variants[0].Harga
+	for _, v := range variants[1:] {
+		if v.Harga.Amount < min.Amount {
+			min = v.Harga
+		}
+		if v.Harga.Amount > max.Amount {
+			max = v.Harga
+		}
+	}
+
+	return min, max
+}
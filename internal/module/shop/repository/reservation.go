@@ -0,0 +1,514 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+const reservationColumns = `id, product_id, variant_id, holder, quantity, status, expires_at`
+
+// lockStock locks the row a hold draws from, the SKU when variantID is set and the product
+// otherwise, and returns its on-hand stock. Every stock change takes this lock first, so
+// checking availability and writing the hold cannot interleave with another change. A SKU
+// locks its product first: confirming a hold takes stock off both, so a product edit must
+// not lower the product's stock while the hold is written, and taking the product before
+// the SKU everywhere keeps two changes from deadlocking.
+func lockStock(ctx context.Context, tx *sqlx.Tx, productID, variantID string) (int, error) {
+	var stok int
+
+	if variantID != "" {
+		if err := lockProduct(ctx, tx, productID); err != nil {
+			return 0, err
+		}
+
+		query := `
+			SELECT product_variants.stok FROM product_variants
+			JOIN product ON product.id = product_variants.product_id AND product.deleted_at IS NULL
+			WHERE product_variants.id = ? AND product_variants.product_id = ? AND product_variants.deleted_at IS NULL
+			FOR UPDATE OF product_variants
+		`
+		err := tx.GetContext(ctx, &stok, tx.Rebind(query), variantID, productID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errmsg.NewCustomErrors(404, errmsg.WithMessage("Varian produk tidak ditemukan"))
+		}
+		if err != nil {
+			log.Error().Err(err).Str("variant_id", variantID).Msg("repository::lockStock - Failed to lock variant")
+			return 0, err
+		}
+
+		return stok, nil
+	}
+
+	var data struct {
+		Stok        int  `db:"stok"`
+		HasVariants bool `db:"has_variants"`
+	}
+
+	query := `
+		SELECT stok, EXISTS (
+			SELECT 1 FROM product_variants
+			WHERE product_variants.product_id = product.id AND product_variants.deleted_at IS NULL
+		) AS has_variants
+		FROM product
+		WHERE id = ? AND deleted_at IS NULL
+		FOR UPDATE
+	`
+	err := tx.GetContext(ctx, &data, tx.Rebind(query), productID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, errmsg.NewCustomErrors(404, errmsg.WithMessage("Produk tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::lockStock - Failed to lock product")
+		return 0, err
+	}
+
+	// stock of a variant product lives on its SKUs
+	if data.HasVariants {
+		return 0, errmsg.NewCustomErrors(400, errmsg.WithErrors("variant_id", "varian harus dipilih."))
+	}
+
+	return data.Stok, nil
+}
+
+// reservedStock sums the live holds on a product or SKU, leaving out the one of exceptHolder.
+func reservedStock(ctx context.Context, tx *sqlx.Tx, productID, variantID, exceptHolder string) (int, error) {
+	var reserved int
+
+	query := `
+		SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations
+		WHERE product_id = ?
+			AND variant_id IS NOT DISTINCT FROM CAST(NULLIF(?, '') AS uuid)
+			AND holder <> ?
+			AND status = ?
+			AND expires_at > NOW()
+	`
+	err := tx.GetContext(ctx, &reserved, tx.Rebind(query), productID, variantID, exceptHolder, entity.ReservationActive)
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::reservedStock - Failed to sum reservations")
+		return 0, err
+	}
+
+	return reserved, nil
+}
+
+func (r *shopRepository) ReserveStock(ctx context.Context, req *entity.ReserveStockRequest) (*entity.StockReservation, error) {
+	var resp = new(entity.StockReservation)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::ReserveStock - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stok, err := lockStock(ctx, tx, req.ProductID, req.VariantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkOnSale(ctx, tx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	reserved, err := reservedStock(ctx, tx, req.ProductID, req.VariantID, req.Holder)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.MaxHolds > 0 {
+		if err := checkHolderLimit(ctx, tx, req); err != nil {
+			return nil, err
+		}
+	}
+
+	if available := stok - reserved; req.Quantity > available {
+		return nil, errmsg.NewCustomErrors(409,
+			errmsg.WithMessage("Stok tidak mencukupi"),
+			errmsg.WithErrors("quantity", fmt.Sprintf("stok tersedia %d.", max(0, available))),
+		)
+	}
+
+	query := `
+		INSERT INTO stock_reservations (product_id, variant_id, holder, quantity, expires_at)
+		VALUES (?, CAST(NULLIF(?, '') AS uuid), ?, ?, NOW() + CAST(? AS interval))
+		ON CONFLICT (product_id, (COALESCE(variant_id, '00000000-0000-0000-0000-000000000000'::uuid)), holder)
+			WHERE status = 'active'
+		DO UPDATE SET
+			quantity = EXCLUDED.quantity,
+			-- a live hold keeps its expiry; only one that lapsed before the sweeper got to it starts over
+			expires_at = CASE WHEN stock_reservations.expires_at > NOW() THEN stock_reservations.expires_at ELSE EXCLUDED.expires_at END,
+			updated_at = NOW()
+		RETURNING ` + reservationColumns
+	err = tx.QueryRowxContext(ctx, tx.Rebind(query),
+		req.ProductID,
+		req.VariantID,
+		req.Holder,
+		req.Quantity,
+		fmt.Sprintf("%d seconds", req.TTL),
+	).StructScan(resp)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::ReserveStock - Failed to save reservation")
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::ReserveStock - Failed to commit transaction")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// checkHolderLimit fails when req.Holder already has req.MaxHolds live holds besides the
+// one req replaces. Holds of one holder are counted one reservation at a time, so two
+// requests of the same buyer cannot both take the last slot.
+func checkHolderLimit(ctx context.Context, tx *sqlx.Tx, req *entity.ReserveStockRequest) error {
+	var count int
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(`SELECT pg_advisory_xact_lock(hashtext(?))`), req.Holder); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::checkHolderLimit - Failed to lock holder")
+		return err
+	}
+
+	query := `
+		SELECT COUNT(id) FROM stock_reservations
+		WHERE holder = ?
+			AND status = ?
+			AND expires_at > NOW()
+			AND NOT (product_id = ? AND variant_id IS NOT DISTINCT FROM CAST(NULLIF(?, '') AS uuid))
+	`
+	err := tx.GetContext(ctx, &count, tx.Rebind(query), req.Holder, entity.ReservationActive, req.ProductID, req.VariantID)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::checkHolderLimit - Failed to count reservations")
+		return err
+	}
+
+	if count >= req.MaxHolds {
+		return errmsg.NewCustomErrors(409, errmsg.WithMessage(fmt.Sprintf("Maksimal %d reservasi aktif", req.MaxHolds)))
+	}
+
+	return nil
+}
+
+// ConfirmHolds confirms every hold of holder inside tx, the transaction of the change
+// that sells them, so either all of them take their stock together with that change or
+// none does. It fails when one of them has lapsed or was released. Holds that are already
+// confirmed are left as they are, which makes confirming the same holder twice harmless.
+func (r *shopRepository) ConfirmHolds(ctx context.Context, tx *sqlx.Tx, holder, actor string) ([]entity.StockReservation, error) {
+	var holds []entity.StockReservation
+
+	// stock rows are locked in a fixed order so two holders cannot deadlock each other
+	query := `
+		SELECT ` + reservationColumns + ` FROM stock_reservations
+		WHERE holder = ?
+		ORDER BY product_id, variant_id NULLS FIRST
+	`
+	err := tx.SelectContext(ctx, &holds, tx.Rebind(query), holder)
+	if err != nil {
+		log.Error().Err(err).Str("holder", holder).Msg("repository::ConfirmHolds - Failed to get reservations")
+		return nil, err
+	}
+	if len(holds) == 0 {
+		return nil, errmsg.NewCustomErrors(409, errmsg.WithMessage("Reservasi sudah tidak aktif"))
+	}
+
+	for i := range holds {
+		switch holds[i].Status {
+		case entity.ReservationConfirmed:
+			continue
+		case entity.ReservationActive:
+		default:
+			return nil, errmsg.NewCustomErrors(409, errmsg.WithMessage("Reservasi sudah tidak aktif"))
+		}
+
+		hold, err := confirmHold(ctx, tx, &holds[i], actor)
+		if err != nil {
+			return nil, err
+		}
+		holds[i] = *hold
+	}
+
+	return holds, nil
+}
+
+// confirmHold takes the stock of a live hold and records the sale, in the ledger as a
+// sale referencing the hold.
+func confirmHold(ctx context.Context, tx *sqlx.Tx, current *entity.StockReservation, actor string) (*entity.StockReservation, error) {
+	var resp = new(entity.StockReservation)
+
+	// same lock order as ReserveStock: stock row first, then the hold
+	if _, err := lockStock(ctx, tx, current.ProductID, deref(current.VariantID)); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE stock_reservations SET status = ?, updated_at = NOW()
+		WHERE id = ? AND status = ? AND expires_at > NOW()
+		RETURNING ` + reservationColumns
+	err := tx.QueryRowxContext(ctx, tx.Rebind(query), entity.ReservationConfirmed, current.ID, entity.ReservationActive).StructScan(resp)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(409, errmsg.WithMessage("Reservasi sudah tidak aktif"))
+	}
+	if err != nil {
+		log.Error().Err(err).Any("reservation", current).Msg("repository::confirmHold - Failed to confirm reservation")
+		return nil, err
+	}
+
+	queryProduct := `UPDATE product SET stok = stok - ?, updated_at = NOW() WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(queryProduct), resp.Quantity, resp.ProductID); err != nil {
+		log.Error().Err(err).Any("reservation", current).Msg("repository::confirmHold - Failed to decrement product stock")
+		return nil, err
+	}
+
+	if resp.VariantID != nil {
+		queryVariant := `UPDATE product_variants SET stok = stok - ?, updated_at = NOW() WHERE id = ?`
+		if _, err := tx.ExecContext(ctx, tx.Rebind(queryVariant), resp.Quantity, *resp.VariantID); err != nil {
+			log.Error().Err(err).Any("reservation", current).Msg("repository::confirmHold - Failed to decrement variant stock")
+			return nil, err
+		}
+	}
+
+	if err := recordSale(ctx, tx, resp.ProductID, resp.Quantity); err != nil {
+		return nil, err
+	}
+
+	_, err = reconcileStock(ctx, tx, resp.ProductID, entity.MovementSource{
+		Reason:    entity.MovementSale,
+		Actor:     actor,
+		Reference: resp.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkLowStock(ctx, tx, resp.ProductID); err != nil {
+		return nil, err
+	}
+
+	if err := checkRestock(ctx, tx, resp.ProductID); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ReturnHolds gives back the stock taken by the confirmed holds of holder and takes
+// their units off the sold counters, e.g. when the order they were sold by is refunded.
+// It runs inside tx, the transaction of that change. Holds already returned are left as
+// they are; stock of a product or SKU deleted since is not given back.
+func (r *shopRepository) ReturnHolds(ctx context.Context, tx *sqlx.Tx, holder, actor string) ([]entity.StockReservation, error) {
+	var holds []entity.StockReservation
+
+	// same lock order as confirmHold: stock rows first, then the holds
+	query := `
+		SELECT ` + reservationColumns + ` FROM stock_reservations
+		WHERE holder = ? AND status = ?
+		ORDER BY product_id, variant_id NULLS FIRST
+	`
+	err := tx.SelectContext(ctx, &holds, tx.Rebind(query), holder, entity.ReservationConfirmed)
+	if err != nil {
+		log.Error().Err(err).Str("holder", holder).Msg("repository::ReturnHolds - Failed to get reservations")
+		return nil, err
+	}
+
+	for i := range holds {
+		hold, err := returnHold(ctx, tx, &holds[i], actor)
+		if err != nil {
+			return nil, err
+		}
+		holds[i] = *hold
+	}
+
+	return holds, nil
+}
+
+// returnHold puts the stock of a confirmed hold back and reverses its sale.
+func returnHold(ctx context.Context, tx *sqlx.Tx, current *entity.StockReservation, actor string) (*entity.StockReservation, error) {
+	var (
+		resp     = new(entity.StockReservation)
+		restored = true
+	)
+
+	queryLock := `SELECT id FROM product WHERE id = ? FOR UPDATE`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(queryLock), current.ProductID); err != nil {
+		log.Error().Err(err).Any("reservation", current).Msg("repository::returnHold - Failed to lock product")
+		return nil, err
+	}
+
+	query := `
+		UPDATE stock_reservations SET status = ?, updated_at = NOW()
+		WHERE id = ? AND status = ?
+		RETURNING ` + reservationColumns
+	err := tx.QueryRowxContext(ctx, tx.Rebind(query), entity.ReservationReturned, current.ID, entity.ReservationConfirmed).StructScan(resp)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(409, errmsg.WithMessage("Reservasi sudah dikembalikan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Any("reservation", current).Msg("repository::returnHold - Failed to return reservation")
+		return nil, err
+	}
+
+	if resp.VariantID != nil {
+		queryVariant := `UPDATE product_variants SET stok = stok + ?, updated_at = NOW() WHERE id = ? AND deleted_at IS NULL`
+		res, err := tx.ExecContext(ctx, tx.Rebind(queryVariant), resp.Quantity, *resp.VariantID)
+		if err != nil {
+			log.Error().Err(err).Any("reservation", current).Msg("repository::returnHold - Failed to increment variant stock")
+			return nil, err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			restored = false
+		}
+	}
+
+	if restored {
+		queryProduct := `UPDATE product SET stok = stok + ?, updated_at = NOW() WHERE id = ? AND deleted_at IS NULL`
+		if _, err := tx.ExecContext(ctx, tx.Rebind(queryProduct), resp.Quantity, resp.ProductID); err != nil {
+			log.Error().Err(err).Any("reservation", current).Msg("repository::returnHold - Failed to increment product stock")
+			return nil, err
+		}
+	}
+
+	if err := recordSale(ctx, tx, resp.ProductID, -resp.Quantity); err != nil {
+		return nil, err
+	}
+
+	_, err = reconcileStock(ctx, tx, resp.ProductID, entity.MovementSource{
+		Reason:    entity.MovementReturn,
+		Actor:     actor,
+		Reference: resp.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkLowStock(ctx, tx, resp.ProductID); err != nil {
+		return nil, err
+	}
+
+	if err := checkRestock(ctx, tx, resp.ProductID); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (r *shopRepository) ReleaseReservation(ctx context.Context, req *entity.ReservationRequest) (*entity.StockReservation, error) {
+	var resp = new(entity.StockReservation)
+
+	query := `
+		UPDATE stock_reservations SET status = ?, updated_at = NOW()
+		WHERE id = ? AND holder = ? AND status = ?
+		RETURNING ` + reservationColumns
+	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query), entity.ReservationReleased, req.Id, req.Holder, entity.ReservationActive).StructScan(resp)
+	if errors.Is(err, sql.ErrNoRows) {
+		// tells a missing hold apart from one that is already settled
+		if _, err := r.getReservation(ctx, req); err != nil {
+			return nil, err
+		}
+		return nil, errmsg.NewCustomErrors(409, errmsg.WithMessage("Reservasi sudah tidak aktif"))
+	}
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::ReleaseReservation - Failed to release reservation")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ReleaseHolds gives back the stock of every live hold of holder.
+func (r *shopRepository) ReleaseHolds(ctx context.Context, holder string) error {
+	query := `UPDATE stock_reservations SET status = ?, updated_at = NOW() WHERE holder = ? AND status = ?`
+
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), entity.ReservationReleased, holder, entity.ReservationActive); err != nil {
+		log.Error().Err(err).Str("holder", holder).Msg("repository::ReleaseHolds - Failed to release reservations")
+		return err
+	}
+
+	return nil
+}
+
+// ExpireReservations marks the holds whose TTL has passed as expired. Expired holds are
+// already ignored when computing availability, this only settles their status.
+func (r *shopRepository) ExpireReservations(ctx context.Context) (int64, error) {
+	query := `UPDATE stock_reservations SET status = ?, updated_at = NOW() WHERE status = ? AND expires_at <= NOW()`
+
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(query), entity.ReservationExpired, entity.ReservationActive)
+	if err != nil {
+		log.Error().Err(err).Msg("repository::ExpireReservations - Failed to expire reservations")
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// getReservation loads a reservation of req.Holder.
+func (r *shopRepository) getReservation(ctx context.Context, req *entity.ReservationRequest) (*entity.StockReservation, error) {
+	var resp = new(entity.StockReservation)
+
+	query := `SELECT ` + reservationColumns + ` FROM stock_reservations WHERE id = ? AND holder = ?`
+	err := r.db.GetContext(ctx, resp, r.db.Rebind(query), req.Id, req.Holder)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Reservasi tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::getReservation - Failed to get reservation")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// checkReservedStock fails when a stock edit left a product or one of its SKUs with
+// less stock than is currently held by live reservations. Holds on a SKU count against
+// the product too, confirming one takes the units off both. The caller holds the
+// product row, so no hold can be added while the check runs.
+func checkReservedStock(ctx context.Context, tx *sqlx.Tx, productID string) error {
+	var short []string
+
+	query := `
+		SELECT COALESCE(CAST(stock_reservations.variant_id AS text), '')
+		FROM stock_reservations
+		WHERE product_id = ? AND status = ? AND expires_at > NOW()
+		GROUP BY variant_id
+		HAVING SUM(quantity) > CASE
+			WHEN variant_id IS NULL THEN (SELECT stok FROM product WHERE id = ?)
+			ELSE COALESCE((
+				SELECT stok FROM product_variants
+				WHERE product_variants.id = stock_reservations.variant_id AND product_variants.deleted_at IS NULL
+			), 0)
+		END
+		UNION ALL
+		SELECT 'product'
+		FROM stock_reservations
+		WHERE product_id = ? AND status = ? AND expires_at > NOW()
+		HAVING SUM(quantity) > (SELECT stok FROM product WHERE id = ?)
+	`
+	err := tx.SelectContext(ctx, &short, tx.Rebind(query),
+		productID, entity.ReservationActive, productID,
+		productID, entity.ReservationActive, productID,
+	)
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::checkReservedStock - Failed to check reservations")
+		return err
+	}
+
+	if len(short) > 0 {
+		return errmsg.NewCustomErrors(409, errmsg.WithErrors("stok", "stok tidak boleh lebih kecil dari jumlah yang sedang direservasi."))
+	}
+
+	return nil
+}
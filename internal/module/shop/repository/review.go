@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+const reviewColumns = `id, product_id, user_id, rating, body, photos, reply, replied_at, hidden_at, created_at`
+
+// refreshRating recomputes the rating of a product from its visible reviews. Callers
+// lock the product first, so concurrent reviews are counted one after another.
+func refreshRating(ctx context.Context, tx *sqlx.Tx, productID string) error {
+	query := `
+		UPDATE product SET
+			penilaian = rating.average,
+			review_count = rating.count,
+			rating_distribution = rating.distribution
+		FROM (
+			SELECT
+				ROUND(AVG(rating), 2) AS average,
+				COUNT(id) AS count,
+				ARRAY[
+					COUNT(id) FILTER (WHERE rating = 1),
+					COUNT(id) FILTER (WHERE rating = 2),
+					COUNT(id) FILTER (WHERE rating = 3),
+					COUNT(id) FILTER (WHERE rating = 4),
+					COUNT(id) FILTER (WHERE rating = 5)
+				] AS distribution
+			FROM product_reviews
+			WHERE product_id = ? AND hidden_at IS NULL
+		) rating
+		WHERE product.id = ?
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), productID, productID); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::refreshRating - Failed to update rating")
+		return err
+	}
+
+	return nil
+}
+
+// ratingSummary builds the rating of a product out of its maintained columns.
+func ratingSummary(average float64, count int, distribution []int64) entity.RatingSummary {
+	resp := entity.RatingSummary{Average: average, Count: count, Distribution: make(map[int]int, 5)}
+
+	for stars := 1; stars <= 5; stars++ {
+		var n int
+		if stars <= len(distribution) {
+			n = int(distribution[stars-1])
+		}
+		resp.Distribution[stars] = n
+	}
+
+	return resp
+}
+
+func (r *shopRepository) CreateReview(ctx context.Context, req *entity.CreateReviewRequest, photos entity.ReviewPhotos) (*entity.ProductReview, error) {
+	var resp = new(entity.ProductReview)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CreateReview - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO product_reviews (product_id, user_id, rating, body, photos)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (product_id, user_id) DO NOTHING
+		RETURNING ` + reviewColumns
+	err = tx.QueryRowxContext(ctx, tx.Rebind(query), req.ProductID, req.UserID, req.Rating, req.Body, photos).StructScan(resp)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(409, errmsg.WithMessage("Anda sudah mengulas produk ini"))
+	}
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CreateReview - Failed to create review")
+		return nil, err
+	}
+
+	if err := refreshRating(ctx, tx, req.ProductID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CreateReview - Failed to commit transaction")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// GetReviews lists the visible reviews of a product, newest first.
+func (r *shopRepository) GetReviews(ctx context.Context, req *entity.ReviewsRequest) (*entity.ReviewsResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.ProductReview
+	}
+
+	var (
+		data []dao
+		resp = &entity.ReviewsResponse{Items: make([]entity.ProductReview, 0, req.Paginate)}
+	)
+
+	query := `
+		SELECT COUNT(id) OVER() AS total_data, ` + reviewColumns + `
+		FROM product_reviews
+		WHERE product_id = ? AND hidden_at IS NULL AND (? = 0 OR rating = ?)
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query),
+		req.ProductID,
+		req.Rating, req.Rating,
+		req.Paginate, req.Paginate*(req.Page-1),
+	)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::GetReviews - Failed to get reviews")
+		return nil, err
+	}
+
+	for _, d := range data {
+		resp.Items = append(resp.Items, d.ProductReview)
+	}
+
+	if len(data) > 0 {
+		resp.Meta.TotalData = data[0].TotalData
+	}
+	resp.Meta.CountTotalPage(req.Page, req.Paginate, resp.Meta.TotalData)
+
+	return resp, nil
+}
+
+func (r *shopRepository) GetReview(ctx context.Context, id string) (*entity.ProductReview, error) {
+	var resp = new(entity.ProductReview)
+
+	query := `SELECT ` + reviewColumns + ` FROM product_reviews WHERE id = ?`
+	err := r.db.GetContext(ctx, resp, r.db.Rebind(query), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Ulasan tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Str("review_id", id).Msg("repository::GetReview - Failed to get review")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ReplyReview sets the seller's reply of a review, replacing an earlier one.
+func (r *shopRepository) ReplyReview(ctx context.Context, req *entity.ReplyReviewRequest) (*entity.ProductReview, error) {
+	var resp = new(entity.ProductReview)
+
+	query := `
+		UPDATE product_reviews SET reply = ?, replied_at = NOW(), updated_at = NOW()
+		WHERE id = ?
+		RETURNING ` + reviewColumns
+	err := r.db.QueryRowxContext(ctx, r.db.Rebind(query), req.Reply, req.Id).StructScan(resp)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Ulasan tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::ReplyReview - Failed to reply review")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// HideReview hides a review from the listing and the product's rating, or shows it again.
+func (r *shopRepository) HideReview(ctx context.Context, req *entity.HideReviewRequest) (*entity.ProductReview, error) {
+	var resp = new(entity.ProductReview)
+
+	current, err := r.GetReview(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::HideReview - Failed to begin transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, current.ProductID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE product_reviews SET
+			hidden_at = CASE WHEN ? THEN COALESCE(hidden_at, NOW()) END,
+			hidden_by = CASE WHEN ? THEN CAST(? AS uuid) END,
+			updated_at = NOW()
+		WHERE id = ?
+		RETURNING ` + reviewColumns
+	err = tx.QueryRowxContext(ctx, tx.Rebind(query), req.Hidden, req.Hidden, req.UserID, req.Id).StructScan(resp)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::HideReview - Failed to hide review")
+		return nil, err
+	}
+
+	if err := refreshRating(ctx, tx, current.ProductID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::HideReview - Failed to commit transaction")
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+const revisionColumns = `product_id, revision, actor, reverted_from, created_at, snapshot`
+
+// snapshotProduct reads the product as it is inside tx, with its live categories,
+// option axes and SKUs in their display order.
+func snapshotProduct(ctx context.Context, tx *sqlx.Tx, productID string) (*entity.ProductSnapshot, error) {
+	var (
+		resp    = new(entity.ProductSnapshot)
+		options []struct {
+			Name   string         `db:"name"`
+			Values pq.StringArray `db:"option_values"`
+		}
+		variants []entity.ProductVariant
+	)
+
+	queryProduct := `
+		SELECT status, name, description, ROW(harga, currency) AS harga, stok, merek, low_stock_threshold
+		FROM product
+		WHERE id = ?
+	`
+	err := tx.QueryRowContext(ctx, tx.Rebind(queryProduct), productID).Scan(
+		&resp.Status, &resp.Name, &resp.Description, &resp.Harga, &resp.Stok, &resp.Merek, &resp.LowStockThreshold)
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::snapshotProduct - Failed to get product")
+		return nil, err
+	}
+
+	resp.Kategori = make([]string, 0)
+	queryCategories := `
+		SELECT categories.id
+		FROM product_categories
+		JOIN categories ON categories.id = product_categories.category_id
+		WHERE product_categories.product_id = ? AND categories.deleted_at IS NULL
+		ORDER BY categories.position, categories.name
+	`
+	if err := tx.SelectContext(ctx, &resp.Kategori, tx.Rebind(queryCategories), productID); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::snapshotProduct - Failed to get categories")
+		return nil, err
+	}
+
+	queryOptions := `SELECT name, option_values FROM product_options WHERE product_id = ? ORDER BY position`
+	if err := tx.SelectContext(ctx, &options, tx.Rebind(queryOptions), productID); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::snapshotProduct - Failed to get options")
+		return nil, err
+	}
+
+	queryVariants := `
+		SELECT id, code, options, ROW(harga, currency) AS harga, stok
+		FROM product_variants
+		WHERE product_id = ? AND deleted_at IS NULL
+		ORDER BY position
+	`
+	if err := tx.SelectContext(ctx, &variants, tx.Rebind(queryVariants), productID); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::snapshotProduct - Failed to get variants")
+		return nil, err
+	}
+
+	resp.Options = make([]entity.ProductOptionRequest, 0, len(options))
+	for _, o := range options {
+		resp.Options = append(resp.Options, entity.ProductOptionRequest{Name: o.Name, Values: o.Values})
+	}
+
+	resp.Variants = make([]entity.ProductVariantRequest, 0, len(variants))
+	for _, v := range variants {
+		resp.Variants = append(resp.Variants, entity.ProductVariantRequest{
+			Code:    v.Code,
+			Options: v.Options,
+			Harga:   v.Harga,
+			Stok:    v.Stok,
+		})
+	}
+
+	return resp, nil
+}
+
+// recordRevision stores the product as it is inside tx as its next revision. The caller
+// holds the product row, so revisions of one product are numbered one at a time.
+func recordRevision(ctx context.Context, tx *sqlx.Tx, productID, actor string, revertedFrom *int) (int, error) {
+	var revision int
+
+	snapshot, err := snapshotProduct(ctx, tx, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO product_revisions (product_id, revision, snapshot, actor, reverted_from)
+		SELECT ?, COALESCE(MAX(revision), 0) + 1, ?, CAST(NULLIF(?, '') AS uuid), ?
+		FROM product_revisions
+		WHERE product_id = ?
+		RETURNING revision
+	`
+	err = tx.GetContext(ctx, &revision, tx.Rebind(query), productID, snapshot, actor, revertedFrom, productID)
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::recordRevision - Failed to insert revision")
+		return 0, err
+	}
+
+	return revision, nil
+}
+
+// baselineRevision records the product as it is before its first tracked change, so
+// products created before revisions were kept can be rolled back too. The actor of
+// such a revision is unknown.
+func baselineRevision(ctx context.Context, tx *sqlx.Tx, productID string) error {
+	var found bool
+
+	query := `SELECT EXISTS (SELECT 1 FROM product_revisions WHERE product_id = ?)`
+	if err := tx.GetContext(ctx, &found, tx.Rebind(query), productID); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::baselineRevision - Failed to check revisions")
+		return err
+	}
+	if found {
+		return nil
+	}
+
+	_, err := recordRevision(ctx, tx, productID, "", nil)
+	return err
+}
+
+func (r *shopRepository) GetProductRevisions(ctx context.Context, req *entity.ProductRevisionsRequest) (*entity.ProductRevisionsResponse, error) {
+	type dao struct {
+		TotalData int `db:"total_data"`
+		entity.ProductRevision
+	}
+
+	var (
+		data []dao
+		resp = &entity.ProductRevisionsResponse{Items: make([]entity.ProductRevision, 0, req.Paginate)}
+	)
+
+	query := `
+		SELECT COUNT(id) OVER() AS total_data, ` + revisionColumns + `
+		FROM product_revisions
+		WHERE product_id = ?
+		ORDER BY revision DESC
+		LIMIT ? OFFSET ?
+	`
+	err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), req.ProductID, req.Paginate, req.Paginate*(req.Page-1))
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::GetProductRevisions - Failed to get revisions")
+		return nil, err
+	}
+
+	for _, d := range data {
+		resp.Items = append(resp.Items, d.ProductRevision)
+	}
+
+	if len(data) > 0 {
+		resp.Meta.TotalData = data[0].TotalData
+	}
+	resp.Meta.CountTotalPage(req.Page, req.Paginate, resp.Meta.TotalData)
+
+	return resp, nil
+}
+
+func (r *shopRepository) GetProductRevision(ctx context.Context, productID string, revision int) (*entity.ProductRevision, error) {
+	var resp = new(entity.ProductRevision)
+
+	query := `SELECT ` + revisionColumns + ` FROM product_revisions WHERE product_id = ? AND revision = ?`
+	err := r.db.GetContext(ctx, resp, r.db.Rebind(query), productID, revision)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Revisi tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Int("revision", revision).Msg("repository::GetProductRevision - Failed to get revision")
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// recordSale adds quantity units to the sold counters of a product and of its shop, or
+// takes them off when quantity is negative, e.g. for a refund. It runs inside the
+// transaction that moves the sold stock, so the counters move together with the stock.
+func recordSale(ctx context.Context, tx *sqlx.Tx, productID string, quantity int) error {
+	query := `
+		WITH sold AS (
+			UPDATE product SET terjual = terjual + ?
+			WHERE id = ?
+			RETURNING shop_id
+		)
+		UPDATE shops SET terjual = terjual + ?, updated_at = NOW()
+		FROM sold
+		WHERE shops.id = sold.shop_id
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), quantity, productID, quantity); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Int("quantity", quantity).Msg("repository::recordSale - Failed to update sales counters")
+		return err
+	}
+
+	return nil
+}
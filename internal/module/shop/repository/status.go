@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog/log"
+)
+
+// shopOpen tells whether a shop takes orders now; it needs shops in the query.
+const shopOpen = `shop_is_open(shops.status, shops.vacation_until, shops.opening_hours, shops.timezone, NOW())`
+
+// shopStatusColumns reads entity.ShopStatus, showing a shop whose vacation is over as open.
+const shopStatusColumns = `
+	CASE WHEN shops.status = 'vacation' AND shops.vacation_until <= NOW() THEN 'open' ELSE shops.status END AS status,
+	CASE WHEN shops.vacation_until > NOW() THEN shops.vacation_until END AS vacation_until,
+	shops.closed_reason,
+	shops.timezone,
+	shops.opening_hours,
+	` + shopOpen + ` AS is_open`
+
+// checkOnSale rejects selling a product that is not published or whose shop is not open.
+func checkOnSale(ctx context.Context, tx *sqlx.Tx, productID string) error {
+	var data struct {
+		ProductStatus string `db:"product_status"`
+		entity.ShopStatus
+	}
+
+	query := `
+		SELECT product.status AS product_status, ` + shopStatusColumns + `
+		FROM product
+		JOIN shops ON shops.id = product.shop_id
+		WHERE product.id = ?
+	`
+	if err := tx.GetContext(ctx, &data, tx.Rebind(query), productID); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::checkOnSale - Failed to get product status")
+		return err
+	}
+
+	if data.ProductStatus != entity.ProductPublished {
+		return errmsg.NewCustomErrors(409, errmsg.WithMessage("Produk tidak dijual"))
+	}
+
+	if !data.IsOpen {
+		return shopClosedError(&data.ShopStatus)
+	}
+
+	return nil
+}
+
+// shopClosedError explains why a shop that is not open does not take orders.
+func shopClosedError(status *entity.ShopStatus) error {
+	switch status.Status {
+	case entity.ShopVacation:
+		return errmsg.NewCustomErrors(409, errmsg.WithMessage("Toko sedang libur"))
+	case entity.ShopClosed:
+		return errmsg.NewCustomErrors(409, errmsg.WithMessage("Toko ditutup"))
+	default:
+		return errmsg.NewCustomErrors(409, errmsg.WithMessage("Toko sedang di luar jam buka"))
+	}
+}
+
+func (r *shopRepository) GetShopStatus(ctx context.Context, shopID string) (*entity.ShopStatus, error) {
+	var resp = new(entity.ShopStatus)
+
+	query := `SELECT ` + shopStatusColumns + ` FROM shops WHERE id = ? AND deleted_at IS NULL`
+	err := r.db.GetContext(ctx, resp, r.db.Rebind(query), shopID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Toko tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Str("shop_id", shopID).Msg("repository::GetShopStatus - Failed to get shop status")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// UpdateShopStatus opens a shop or sends it on vacation. A shop closed by an admin
+// cannot be changed by its owner.
+func (r *shopRepository) UpdateShopStatus(ctx context.Context, req *entity.UpdateShopStatusRequest) (*entity.ShopStatus, error) {
+	query := `
+		UPDATE shops SET status = ?, vacation_until = ?, updated_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL AND status <> ?
+	`
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(query), req.Status, req.VacationUntil, req.ShopID, entity.ShopClosed)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateShopStatus - Failed to update shop status")
+		return nil, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateShopStatus - Failed to read affected rows")
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errmsg.NewCustomErrors(409, errmsg.WithMessage("Toko ditutup oleh admin"))
+	}
+
+	return r.GetShopStatus(ctx, req.ShopID)
+}
+
+func (r *shopRepository) UpdateOpeningHours(ctx context.Context, req *entity.UpdateOpeningHoursRequest) (*entity.ShopStatus, error) {
+	query := `UPDATE shops SET timezone = ?, opening_hours = ?, updated_at = NOW() WHERE id = ? AND deleted_at IS NULL`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), req.Timezone, req.Hours, req.ShopID); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateOpeningHours - Failed to update opening hours")
+		return nil, err
+	}
+
+	return r.GetShopStatus(ctx, req.ShopID)
+}
+
+func (r *shopRepository) CloseShop(ctx context.Context, req *entity.CloseShopRequest) (*entity.ShopStatus, error) {
+	query := `
+		UPDATE shops SET status = ?, closed_reason = ?, vacation_until = NULL, updated_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL
+	`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), entity.ShopClosed, req.Reason, req.ShopID); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::CloseShop - Failed to close shop")
+		return nil, err
+	}
+
+	return r.GetShopStatus(ctx, req.ShopID)
+}
+
+// ReopenShop opens a shop again, ending a vacation as well as a closure.
+func (r *shopRepository) ReopenShop(ctx context.Context, req *entity.ReopenShopRequest) (*entity.ShopStatus, error) {
+	query := `
+		UPDATE shops SET status = ?, closed_reason = NULL, vacation_until = NULL, updated_at = NOW()
+		WHERE id = ? AND deleted_at IS NULL
+	`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), entity.ShopOpen, req.ShopID); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::ReopenShop - Failed to reopen shop")
+		return nil, err
+	}
+
+	return r.GetShopStatus(ctx, req.ShopID)
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+// saveVariants replaces the option axes and SKUs of a product. SKUs are matched by
+// code so existing variant ids survive edits; codes missing from the request are soft deleted.
+func (r *shopRepository) saveVariants(ctx context.Context, tx *sqlx.Tx, productID string, options []entity.ProductOptionRequest, variants []entity.ProductVariantRequest) ([]entity.ProductOption, []entity.ProductVariant, error) {
+	var (
+		names      = make([]string, 0, len(options))
+		codes      = make([]string, 0, len(variants))
+		respOpts   = make([]entity.ProductOption, 0, len(options))
+		respVarian = make([]entity.ProductVariant, 0, len(variants))
+	)
+
+	for _, o := range options {
+		names = append(names, o.Name)
+	}
+	for _, v := range variants {
+		codes = append(codes, v.Code)
+	}
+
+	queryDeleteOptions := `DELETE FROM product_options WHERE product_id = ? AND NOT (name = ANY(?))`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(queryDeleteOptions), productID, pq.Array(names)); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::saveVariants - Failed to delete options")
+		return nil, nil, err
+	}
+
+	queryOption := `
+		INSERT INTO product_options (product_id, name, option_values, position)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (product_id, name) DO UPDATE
+		SET option_values = EXCLUDED.option_values, position = EXCLUDED.position, updated_at = NOW()
+		RETURNING id
+	`
+	for i, o := range options {
+		opt := entity.ProductOption{Name: o.Name, Values: o.Values}
+		err := tx.QueryRowContext(ctx, tx.Rebind(queryOption), productID, o.Name, pq.Array(o.Values), i).Scan(&opt.ID)
+		if err != nil {
+			log.Error().Err(err).Any("payload", o).Msg("repository::saveVariants - Failed to save option")
+			return nil, nil, err
+		}
+		respOpts = append(respOpts, opt)
+	}
+
+	queryDeleteVariants := `
+		UPDATE product_variants SET deleted_at = NOW()
+		WHERE product_id = ? AND deleted_at IS NULL AND NOT (code = ANY(?))
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(queryDeleteVariants), productID, pq.Array(codes)); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::saveVariants - Failed to delete variants")
+		return nil, nil, err
+	}
+
+	queryVariant := `
+		INSERT INTO product_variants (product_id, code, options, harga, currency, stok, position)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (product_id, code) DO UPDATE
+		SET options = EXCLUDED.options, harga = EXCLUDED.harga, currency = EXCLUDED.currency, stok = EXCLUDED.stok,
+			position = EXCLUDED.position, updated_at = NOW(), deleted_at = NULL
+		RETURNING id, code, options, ROW(harga, currency) AS harga, stok
+	`
+	for i, v := range variants {
+		var variant entity.ProductVariant
+		err := tx.QueryRowxContext(ctx, tx.Rebind(queryVariant), productID, v.Code, v.Options, v.Harga, v.Harga.CurrencyCode(), v.Stok, i).StructScan(&variant)
+		if err != nil {
+			log.Error().Err(err).Any("payload", v).Msg("repository::saveVariants - Failed to save variant")
+			return nil, nil, err
+		}
+		respVarian = append(respVarian, variant)
+	}
+
+	return respOpts, respVarian, nil
+}
+
+// getVariants loads the option axes and live SKUs of the given products, keyed by product id.
+func (r *shopRepository) getVariants(ctx context.Context, productIDs []string) (map[string][]entity.ProductOption, map[string][]entity.ProductVariant, error) {
+	type optionDao struct {
+		ProductID string         `db:"product_id"`
+		Values    pq.StringArray `db:"option_values"`
+		entity.ProductOption
+	}
+	type variantDao struct {
+		ProductID string `db:"product_id"`
+		entity.ProductVariant
+	}
+
+	var (
+		options     []optionDao
+		variants    []variantDao
+		respOptions = make(map[string][]entity.ProductOption, len(productIDs))
+		respVariant = make(map[string][]entity.ProductVariant, len(productIDs))
+	)
+
+	if len(productIDs) == 0 {
+		return respOptions, respVariant, nil
+	}
+
+	queryOptions := `
+		SELECT product_id, id, name, option_values
+		FROM product_options
+		WHERE product_id = ANY(?)
+		ORDER BY product_id, position
+	`
+	if err := r.db.SelectContext(ctx, &options, r.db.Rebind(queryOptions), pq.Array(productIDs)); err != nil {
+		log.Error().Err(err).Any("payload", productIDs).Msg("repository::getVariants - Failed to get options")
+		return nil, nil, err
+	}
+
+	queryVariants := `
+		SELECT product_id, id, code, options, ROW(harga, currency) AS harga, stok
+		FROM product_variants
+		WHERE product_id = ANY(?) AND deleted_at IS NULL
+		ORDER BY product_id, position
+	`
+	if err := r.db.SelectContext(ctx, &variants, r.db.Rebind(queryVariants), pq.Array(productIDs)); err != nil {
+		log.Error().Err(err).Any("payload", productIDs).Msg("repository::getVariants - Failed to get variants")
+		return nil, nil, err
+	}
+
+	for _, o := range options {
+		o.ProductOption.Values = o.Values
+		respOptions[o.ProductID] = append(respOptions[o.ProductID], o.ProductOption)
+	}
+	for _, v := range variants {
+		respVariant[v.ProductID] = append(respVariant[v.ProductID], v.ProductVariant)
+	}
+
+	return respOptions, respVariant, nil
+}
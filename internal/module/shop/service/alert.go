@@ -0,0 +1,14 @@
+package service
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"context"
+)
+
+func (s *shopService) GetStockAlerts(ctx context.Context, req *entity.StockAlertsRequest) (*entity.StockAlertsResponse, error) {
+	if err := s.repo.CheckShopOwner(ctx, req.ShopID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetStockAlerts(ctx, req)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"bufio"
+	"codebase-app/internal/module/shop/entity"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportColumns are the CSV columns of an export; kategori_nama and created_at are
+// read-only and skipped when the file is imported back.
+var exportColumns = []string{
+	"id", "name", "description", "kategori", "kategori_nama", "harga", "currency", "stok", "merek", "low_stock_threshold", "created_at",
+}
+
+// ExportProducts writes the catalog of a shop to w as it is read from the database.
+// Output is buffered in small chunks and nothing reaches w when the request is refused,
+// so callers can still answer with an error as long as no byte has arrived.
+func (s *shopService) ExportProducts(ctx context.Context, req *entity.ExportProductsRequest, w io.Writer) error {
+	if err := s.repo.CheckShopOwner(ctx, req.ShopID, req.UserID); err != nil {
+		return err
+	}
+
+	var (
+		buf = bufio.NewWriter(w)
+		enc = newExportEncoder(req.Format, buf)
+	)
+
+	if err := enc.Header(); err != nil {
+		return err
+	}
+
+	err := s.repo.ExportProducts(ctx, req, func(row *entity.ExportProductRow) error {
+		return enc.Encode(row)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+
+	return buf.Flush()
+}
+
+type exportEncoder interface {
+	Header() error
+	Encode(row *entity.ExportProductRow) error
+	Flush() error
+}
+
+func newExportEncoder(format string, w io.Writer) exportEncoder {
+	if format == entity.ImportFormatJSONL {
+		return &jsonlExportEncoder{enc: json.NewEncoder(w)}
+	}
+
+	return &csvExportEncoder{w: csv.NewWriter(w)}
+}
+
+type csvExportEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvExportEncoder) Header() error {
+	return e.w.Write(exportColumns)
+}
+
+func (e *csvExportEncoder) Encode(row *entity.ExportProductRow) error {
+	err := e.w.Write([]string{
+		row.ID,
+		row.Name,
+		row.Description,
+		strings.Join(row.Kategori, "|"),
+		strings.Join(row.KategoriNama, "|"),
+		strconv.FormatInt(row.Harga.Amount, 10),
+		row.Harga.CurrencyCode(),
+		strconv.Itoa(row.Stok),
+		row.Merek,
+		strconv.Itoa(row.LowStockThreshold),
+		row.CreatedAt.Format(time.RFC3339),
+	})
+	return err
+}
+
+func (e *csvExportEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonlExportEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *jsonlExportEncoder) Header() error {
+	return nil
+}
+
+func (e *jsonlExportEncoder) Encode(row *entity.ExportProductRow) error {
+	return e.enc.Encode(row)
+}
+
+func (e *jsonlExportEncoder) Flush() error {
+	return nil
+}
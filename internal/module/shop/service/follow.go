@@ -0,0 +1,18 @@
+package service
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"context"
+)
+
+func (s *shopService) FollowShop(ctx context.Context, req *entity.FollowShopRequest) (*entity.FollowShopResponse, error) {
+	return s.repo.FollowShop(ctx, req)
+}
+
+func (s *shopService) UnfollowShop(ctx context.Context, req *entity.FollowShopRequest) (*entity.FollowShopResponse, error) {
+	return s.repo.UnfollowShop(ctx, req)
+}
+
+func (s *shopService) GetFeed(ctx context.Context, req *entity.FeedRequest) (*entity.FeedResponse, error) {
+	return s.repo.GetFeed(ctx, req)
+}
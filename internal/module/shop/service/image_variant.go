@@ -0,0 +1,134 @@
+package service
+
+import (
+	"bytes"
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	_ "golang.org/x/image/webp"
+)
+
+// imageVariantSizes are the longest sides, in pixels, of the resized copies made of every product image.
+var imageVariantSizes = []int{150, 400, 1024}
+
+const (
+	maxVariantAttempts = 3
+	variantStaleAfter  = 5 * time.Minute
+)
+
+// GenerateNextImageVariants resizes the next pending product image. It reports whether an
+// image was claimed so callers can keep draining the queue before waiting for new uploads.
+// A failed image is retried up to maxVariantAttempts times before it is marked failed.
+func (s *shopService) GenerateNextImageVariants(ctx context.Context) (bool, error) {
+	img, err := s.repo.ClaimPendingImage(ctx, variantStaleAfter)
+	if err != nil || img == nil {
+		return false, err
+	}
+
+	variants, err := s.generateImageVariants(ctx, img)
+	if err != nil {
+		log.Error().Err(err).Str("image_id", img.ID).Int("attempt", img.Attempts).Msg("service::GenerateNextImageVariants - Failed to generate variants")
+		return true, s.repo.ReleaseImage(ctx, img.ID, img.Attempts < maxVariantAttempts)
+	}
+
+	if err := s.repo.SaveImageVariants(ctx, img.ID, variants); err != nil {
+		var errCustom *errmsg.CustomError
+		if errors.As(err, &errCustom) && errCustom.Code == 404 {
+			// the image was deleted while it was being resized
+			img.Variants = variants
+			s.deleteImageFiles(ctx, img)
+			return true, nil
+		}
+		return true, err
+	}
+
+	return true, nil
+}
+
+func (s *shopService) generateImageVariants(ctx context.Context, img *entity.ProductImage) (entity.ImageVariants, error) {
+	original, err := s.storage.Get(ctx, img.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+	defer original.Close()
+
+	src, format, err := image.Decode(io.LimitReader(original, maxImageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", img.StorageKey, err)
+	}
+
+	// there is no pure Go WebP encoder, WebP originals get PNG variants which keep transparency
+	if format != "jpeg" {
+		format = "png"
+	}
+
+	variants := make(entity.ImageVariants, 0, len(imageVariantSizes))
+	for _, size := range imageVariantSizes {
+		var (
+			buf     bytes.Buffer
+			resized = pkg.ResizeToFit(src, size)
+		)
+
+		if format == "jpeg" {
+			err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: 85})
+		} else {
+			err = png.Encode(&buf, resized)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("encode %s at %dpx: %w", img.StorageKey, size, err)
+		}
+
+		url, err := s.storage.Put(ctx, variantKey(img.StorageKey, size, format), &buf, "image/"+format)
+		if err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, entity.ImageVariant{
+			Size:   size,
+			Width:  resized.Bounds().Dx(),
+			Height: resized.Bounds().Dy(),
+			Format: format,
+			URL:    url,
+		})
+	}
+
+	return variants, nil
+}
+
+// deleteImageFiles removes the original and the resized copies of an image from storage.
+// Failures are only logged, a file left behind does not affect the product.
+func (s *shopService) deleteImageFiles(ctx context.Context, img *entity.ProductImage) {
+	keys := []string{img.StorageKey}
+	for _, v := range img.Variants {
+		keys = append(keys, variantKey(img.StorageKey, v.Size, v.Format))
+	}
+
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("service::deleteImageFiles - Failed to remove image file")
+		}
+	}
+}
+
+// variantKey places a resized copy next to its original, e.g.
+// "products/<id>/<ulid>.png" => "products/<id>/<ulid>_400.png".
+func variantKey(key string, size int, format string) string {
+	ext := format
+	if format == "jpeg" {
+		ext = "jpg"
+	}
+
+	return fmt.Sprintf("%s_%d.%s", strings.TrimSuffix(key, path.Ext(key)), size, ext)
+}
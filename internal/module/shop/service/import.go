@@ -0,0 +1,273 @@
+package service
+
+import (
+	"bytes"
+	"codebase-app/internal/adapter"
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxImportSize is the largest import file accepted, in bytes.
+const maxImportSize = 8 << 20
+
+// importStaleAfter is how long an import may stay in processing before it is considered abandoned.
+const importStaleAfter = 30 * time.Minute
+
+func (s *shopService) CreateProductImport(ctx context.Context, req *entity.CreateProductImportRequest) (*entity.ProductImport, error) {
+	if err := s.repo.CheckShopOwner(ctx, req.ShopID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	if req.Format == "" {
+		req.Format = entity.ImportFormatOf(req.File.Filename)
+		if req.Format == "" {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("format", "format harus csv atau jsonl."))
+		}
+	}
+
+	if req.File.Size > maxImportSize {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("file", "ukuran file maksimal 8MB."))
+	}
+
+	f, err := req.File.Open()
+	if err != nil {
+		log.Error().Err(err).Str("shop_id", req.ShopID).Msg("service::CreateProductImport - Failed to open file")
+		return nil, err
+	}
+	defer f.Close()
+
+	source, err := io.ReadAll(io.LimitReader(f, maxImportSize+1))
+	if err != nil {
+		log.Error().Err(err).Str("shop_id", req.ShopID).Msg("service::CreateProductImport - Failed to read file")
+		return nil, err
+	}
+	if len(source) > maxImportSize {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("file", "ukuran file maksimal 8MB."))
+	}
+
+	// rows are read by the import worker, see RunNextProductImport
+	return s.repo.CreateProductImport(ctx, req, source)
+}
+
+func (s *shopService) GetProductImport(ctx context.Context, req *entity.ProductImportRequest) (*entity.ProductImport, error) {
+	return s.repo.GetProductImport(ctx, req)
+}
+
+// RunNextProductImport runs the oldest queued import. It reports false when there was
+// nothing to do.
+func (s *shopService) RunNextProductImport(ctx context.Context) (bool, error) {
+	job, err := s.repo.ClaimPendingImport(ctx, importStaleAfter)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	var failure string
+
+	report, err := s.ImportProducts(ctx, job, bytes.NewReader(job.Source))
+	if err != nil {
+		log.Warn().Err(err).Str("import_id", job.ID).Msg("service::RunNextProductImport - Import stopped")
+		failure = importFailure(err)
+	}
+
+	// the outcome is saved even when the import was stopped by a shutdown
+	if err := s.repo.FinishProductImport(context.WithoutCancel(ctx), job.ID, report, failure); err != nil {
+		return true, err
+	}
+
+	log.Info().
+		Str("import_id", job.ID).
+		Int("rows", len(report)).
+		Int("failed", report.Count(entity.ImportRowFailed)).
+		Msg("service::RunNextProductImport - Import finished")
+
+	return true, nil
+}
+
+// ImportProducts creates or updates a product of job.ShopID for every row of body and
+// reports the outcome of each. Rows are validated the same way as CreateProduct
+// requests; a dry run stops there. Each row is written on its own, so a failed row
+// does not undo the others. An error means the rest of the file could not be read,
+// the rows reported so far are still returned.
+func (s *shopService) ImportProducts(ctx context.Context, job *entity.ProductImport, body io.Reader) (entity.ImportRowResults, error) {
+	var report = entity.ImportRowResults{}
+
+	if err := s.repo.CheckShopOwner(ctx, job.ShopID, job.UserID); err != nil {
+		return report, err
+	}
+
+	reader, err := newImportReader(job.Format, body)
+	if err != nil {
+		return report, err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		line, row, errs, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+		if err != nil {
+			return report, err
+		}
+
+		result := entity.ImportRowResult{Row: line, Status: entity.ImportRowFailed, Errors: errs}
+		if errs == nil {
+			result = s.importRow(ctx, job, row)
+			result.Row = line
+		}
+
+		report = append(report, result)
+	}
+}
+
+func (s *shopService) importRow(ctx context.Context, job *entity.ProductImport, row entity.ImportProductRow) entity.ImportRowResult {
+	var (
+		v      = adapter.Adapters.Validator
+		result = entity.ImportRowResult{Status: entity.ImportRowCreated, ProductID: row.ID}
+	)
+
+	if err := v.Validate(&row); err != nil {
+		return failedRow(result, importRowErrors(err, &row))
+	}
+
+	// updates replace the whole product, so they are held to the same rules as a new one
+	create := &entity.CreateProductRequest{
+		UserID:      job.UserID,
+		ShopID:      job.ShopID,
+		Name:        row.Name,
+		Description: row.Description,
+		Kategori:    row.Kategori,
+		Harga:       row.Harga,
+		Stok:        row.Stok,
+		Merek:       row.Merek,
+	}
+	if row.LowStockThreshold != nil {
+		create.LowStockThreshold = *row.LowStockThreshold
+	}
+
+	if err := v.Validate(create); err != nil {
+		return failedRow(result, importRowErrors(err, create))
+	}
+
+	if row.ID != "" {
+		result.Status = entity.ImportRowUpdated
+
+		update := &entity.UpdateProductRequest{
+			ID:                row.ID,
+			UserID:            job.UserID,
+			ShopID:            job.ShopID,
+			Name:              row.Name,
+			Description:       row.Description,
+			Kategori:          row.Kategori,
+			Harga:             row.Harga,
+			Stok:              row.Stok,
+			Merek:             row.Merek,
+			LowStockThreshold: row.LowStockThreshold,
+		}
+		if err := v.Validate(update); err != nil {
+			return failedRow(result, importRowErrors(err, update))
+		}
+
+		if err := s.checkImportProduct(ctx, job, row.ID); err != nil {
+			return failedRow(result, importRowErrors[error](err))
+		}
+
+		if job.DryRun {
+			return result
+		}
+
+		if _, err := s.UpdateProductByID(ctx, update); err != nil {
+			return failedRow(result, importRowErrors[error](err))
+		}
+		return result
+	}
+
+	if job.DryRun {
+		return result
+	}
+
+	product, err := s.CreateProduct(ctx, create)
+	if err != nil {
+		return failedRow(result, importRowErrors[error](err))
+	}
+	result.ProductID = product.ID
+
+	return result
+}
+
+// checkImportProduct checks that a row updating productID may do so: the product must be
+// owned by the user running the import and sold by the shop the import is for, or a
+// job for one shop could rewrite the products of another shop of the same user.
+func (s *shopService) checkImportProduct(ctx context.Context, job *entity.ProductImport, productID string) error {
+	if err := s.checkProductOwner(ctx, productID, job.UserID); err != nil {
+		return err
+	}
+
+	shopID, err := s.repo.GetProductShopID(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	if shopID != job.ShopID {
+		log.Warn().Str("product_id", productID).Str("shop_id", job.ShopID).Msg("service::checkImportProduct - Product is not sold by the import shop")
+		return errmsg.NewCustomErrors(403, errmsg.WithErrors("id", "produk bukan milik toko ini."))
+	}
+
+	return nil
+}
+
+func failedRow(result entity.ImportRowResult, errs map[string][]string) entity.ImportRowResult {
+	result.Status = entity.ImportRowFailed
+	result.Errors = errs
+	return result
+}
+
+// importRowErrors turns an error into the field errors of a report row, the same way
+// errmsg.Errors does for a response.
+func importRowErrors[T any](err error, payloads ...*T) map[string][]string {
+	code, errs := errmsg.Errors(err, payloads...)
+
+	switch e := errs.(type) {
+	case map[string][]string:
+		if len(e) > 0 {
+			return e
+		}
+	case *errmsg.CustomError:
+		if e.HasErrors() {
+			return e.Errors
+		}
+		return map[string][]string{"row": {e.Msg}}
+	}
+
+	if code >= 500 {
+		log.Error().Err(err).Msg("service::importRow - Failed to import row")
+	}
+
+	return map[string][]string{"row": {"baris gagal diproses."}}
+}
+
+// importFailure is the message stored on an import that could not be read to the end.
+func importFailure(err error) string {
+	var customErr *errmsg.CustomError
+	if errors.As(err, &customErr) {
+		for _, msgs := range customErr.Errors {
+			return msgs[0]
+		}
+		return customErr.Msg
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return "import dihentikan sebelum selesai"
+	}
+
+	return "file gagal dibaca"
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bufio"
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/types"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// importColumns are the columns a CSV import may carry, name is the only one required.
+// The read-only columns of an export are accepted and skipped.
+var importColumns = map[string]bool{
+	"id":                  true,
+	"name":                true,
+	"description":         true,
+	"kategori":            true,
+	"harga":               true,
+	"currency":            true,
+	"stok":                true,
+	"merek":               true,
+	"low_stock_threshold": true,
+	"kategori_nama":       false,
+	"created_at":          false,
+}
+
+// importReader yields the rows of an import file one at a time. Next returns io.EOF
+// after the last row. A row that cannot be read comes back with its field errors, an
+// error means the rest of the file cannot be read either.
+type importReader interface {
+	Next() (line int, row entity.ImportProductRow, errs map[string][]string, err error)
+}
+
+func newImportReader(format string, body io.Reader) (importReader, error) {
+	switch format {
+	case entity.ImportFormatCSV:
+		return newCSVImportReader(body)
+	case entity.ImportFormatJSONL:
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+		return &jsonlImportReader{scanner: scanner}, nil
+	default:
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("format", "format harus csv atau jsonl."))
+	}
+}
+
+type csvImportReader struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+func newCSVImportReader(body io.Reader) (*csvImportReader, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("file", "file kosong."))
+	}
+	if err != nil {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("file", "header csv tidak valid."))
+	}
+
+	columns := make([]string, len(header))
+	for i, name := range header {
+		// spreadsheets like to prepend a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := importColumns[name]; !ok {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("file", fmt.Sprintf("kolom %q tidak dikenal.", name)))
+		}
+		columns[i] = name
+	}
+
+	if !slices.Contains(columns, "name") {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("file", "kolom name harus ada."))
+	}
+
+	return &csvImportReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvImportReader) Next() (int, entity.ImportProductRow, map[string][]string, error) {
+	var (
+		row  entity.ImportProductRow
+		errs = make(map[string][]string)
+	)
+
+	record, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return 0, row, nil, io.EOF
+	}
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return parseErr.StartLine, row, map[string][]string{"row": {"baris csv tidak valid."}}, nil
+		}
+		return 0, row, nil, err
+	}
+	line, _ := r.reader.FieldPos(0)
+
+	var currency string
+	for i, value := range record {
+		if i >= len(r.columns) {
+			errs["row"] = append(errs["row"], "jumlah kolom melebihi header.")
+			break
+		}
+
+		value = strings.TrimSpace(value)
+		switch column := r.columns[i]; column {
+		case "id":
+			row.ID = value
+		case "name":
+			row.Name = value
+		case "description":
+			row.Description = value
+		case "kategori":
+			for _, id := range strings.Split(value, "|") {
+				if id = strings.TrimSpace(id); id != "" {
+					row.Kategori = append(row.Kategori, id)
+				}
+			}
+		case "merek":
+			row.Merek = value
+		case "currency":
+			currency = value
+		case "harga":
+			if value == "" {
+				continue
+			}
+			amount, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				errs[column] = append(errs[column], "harga harus berupa bilangan bulat dalam satuan terkecil.")
+				continue
+			}
+			row.Harga.Amount = amount
+		case "stok", "low_stock_threshold":
+			if value == "" {
+				continue
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				errs[column] = append(errs[column], fmt.Sprintf("%s harus berupa angka.", strings.ReplaceAll(column, "_", " ")))
+				continue
+			}
+			if column == "stok" {
+				row.Stok = n
+			} else {
+				row.LowStockThreshold = &n
+			}
+		}
+	}
+	row.Harga = types.NewMoney(row.Harga.Amount, currency)
+
+	if len(errs) > 0 {
+		return line, row, errs, nil
+	}
+
+	return line, row, nil, nil
+}
+
+type jsonlImportReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *jsonlImportReader) Next() (int, entity.ImportProductRow, map[string][]string, error) {
+	var row entity.ImportProductRow
+
+	for r.scanner.Scan() {
+		r.line++
+
+		text := strings.TrimSpace(r.scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			return r.line, row, map[string][]string{"row": {"baris bukan json yang valid."}}, nil
+		}
+		row.Harga = types.NewMoney(row.Harga.Amount, row.Harga.Currency)
+
+		return r.line, row, nil, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return r.line + 1, row, nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("file", "baris terlalu panjang."))
+		}
+		return r.line, row, nil, err
+	}
+
+	return 0, row, nil, io.EOF
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/types"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type readRow struct {
+	line int
+	row  entity.ImportProductRow
+	errs map[string][]string
+}
+
+// readAll reads every row of body, stopping at the first error.
+func readAll(t *testing.T, format, body string) ([]readRow, error) {
+	t.Helper()
+
+	reader, err := newImportReader(format, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []readRow
+	for {
+		line, row, errs, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return rows, err
+		}
+		rows = append(rows, readRow{line, row, errs})
+	}
+}
+
+func TestCSVImportReader(t *testing.T) {
+	threshold, one := 3, 1
+	body := "\ufeffID, Name,kategori,harga,currency,stok,low_stock_threshold,created_at\n" +
+		"11111111-1111-1111-1111-111111111111,Kaos,c1| c2 |,50000,idr,10,3,2026-01-01\n" +
+		",Topi,,,,,,\n" +
+		",Celana,,12.5,,lima,,\n" +
+		",Jaket,,1,IDR,1,1,x,extra\n"
+
+	rows, err := readAll(t, entity.ImportFormatCSV, body)
+	assert.NoError(t, err)
+	assert.Equal(t, []readRow{
+		{2, entity.ImportProductRow{
+			ID:                "11111111-1111-1111-1111-111111111111",
+			Name:              "Kaos",
+			Kategori:          []string{"c1", "c2"},
+			Harga:             types.NewMoney(50000, "IDR"),
+			Stok:              10,
+			LowStockThreshold: &threshold,
+		}, nil},
+		{3, entity.ImportProductRow{Name: "Topi", Harga: types.NewMoney(0, "")}, nil},
+		{4, entity.ImportProductRow{Name: "Celana", Harga: types.NewMoney(0, "")}, map[string][]string{
+			"harga": {"harga harus berupa bilangan bulat dalam satuan terkecil."},
+			"stok":  {"stok harus berupa angka."},
+		}},
+		{5, entity.ImportProductRow{
+			Name:              "Jaket",
+			Harga:             types.NewMoney(1, "IDR"),
+			Stok:              1,
+			LowStockThreshold: &one,
+		}, map[string][]string{"row": {"jumlah kolom melebihi header."}}},
+	}, rows)
+}
+
+func TestCSVImportReaderHeader(t *testing.T) {
+	cases := map[string]string{
+		"empty":          "",
+		"unknown column": "name,warna\n",
+		"no name":        "id,harga\n",
+	}
+
+	for name, body := range cases {
+		_, err := readAll(t, entity.ImportFormatCSV, body)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestJSONLImportReader(t *testing.T) {
+	body := `{"name": "Kaos", "harga": 50000, "stok": 2}` + "\n" +
+		"\n" +
+		`{"name": "Topi", "harga": {"amount": 1999, "currency": "usd"}}` + "\n" +
+		`{"name": "Celana",` + "\n"
+
+	rows, err := readAll(t, entity.ImportFormatJSONL, body)
+	assert.NoError(t, err)
+	assert.Equal(t, []readRow{
+		{1, entity.ImportProductRow{Name: "Kaos", Harga: types.NewMoney(50000, "IDR"), Stok: 2}, nil},
+		{3, entity.ImportProductRow{Name: "Topi", Harga: types.NewMoney(1999, "USD")}, nil},
+		{4, entity.ImportProductRow{}, map[string][]string{"row": {"baris bukan json yang valid."}}},
+	}, rows)
+}
+
+func TestJSONLImportReaderLongLine(t *testing.T) {
+	body := `{"name": "` + strings.Repeat("a", 2<<20) + `"}` + "\n"
+
+	_, err := readAll(t, entity.ImportFormatJSONL, body)
+	assert.Error(t, err)
+}
+
+func TestImportReaderFormat(t *testing.T) {
+	_, err := newImportReader("xlsx", strings.NewReader(""))
+	assert.Error(t, err)
+}
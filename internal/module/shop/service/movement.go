@@ -0,0 +1,22 @@
+package service
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"context"
+)
+
+func (s *shopService) CreateStockMovement(ctx context.Context, req *entity.CreateStockMovementRequest) (*entity.StockMovement, error) {
+	if err := s.checkProductOwner(ctx, req.ProductID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateStockMovement(ctx, req)
+}
+
+func (s *shopService) GetStockMovements(ctx context.Context, req *entity.StockMovementsRequest) (*entity.StockMovementsResponse, error) {
+	if err := s.checkProductOwner(ctx, req.ProductID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetStockMovements(ctx, req)
+}
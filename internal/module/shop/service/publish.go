@@ -0,0 +1,75 @@
+package service
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (s *shopService) UpdateProductStatus(ctx context.Context, req *entity.UpdateProductStatusRequest) (*entity.ProductStatusResponse, error) {
+	if err := s.checkProductOwner(ctx, req.ProductID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	if err := checkPublishAt(req.Status, req.PublishAt); err != nil {
+		return nil, err
+	}
+
+	return s.repo.UpdateProductStatus(ctx, req)
+}
+
+// PreviewProduct shows a product to its owner as buyers will see it, whatever its status.
+func (s *shopService) PreviewProduct(ctx context.Context, req *entity.PreviewProductRequest) (*entity.ProductResponse, error) {
+	product, err := s.repo.GetDetailProduct(ctx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.UserID != req.UserID {
+		log.Warn().Str("product_id", req.ProductID).Str("user_id", req.UserID).Msg("service::PreviewProduct - Product is not owned by user")
+		return nil, errmsg.NewCustomErrors(403, errmsg.WithMessage("Produk bukan milik anda"))
+	}
+
+	return product, nil
+}
+
+func (s *shopService) GetShopProducts(ctx context.Context, req *entity.ShopProductsRequest) (*entity.ShopProductsResponse, error) {
+	if err := s.repo.CheckShopOwner(ctx, req.ShopID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetShopProducts(ctx, req)
+}
+
+func (s *shopService) PublishScheduled(ctx context.Context) error {
+	n, err := s.repo.PublishScheduled(ctx)
+	if err != nil {
+		return err
+	}
+
+	if n > 0 {
+		log.Info().Int64("count", n).Msg("service::PublishScheduled - Published scheduled products")
+	}
+
+	return nil
+}
+
+// checkPublishAt only lets a draft be scheduled, and only in the future.
+func checkPublishAt(status string, publishAt *time.Time) error {
+	if publishAt == nil {
+		return nil
+	}
+
+	if status != entity.ProductDraft {
+		return errmsg.NewCustomErrors(400, errmsg.WithErrors("publish_at", "hanya untuk draft."))
+	}
+
+	if !publishAt.After(time.Now()) {
+		return errmsg.NewCustomErrors(400, errmsg.WithErrors("publish_at", "harus di masa depan."))
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ReserveStock holds stock for the buyer. The hold only keeps others from buying it;
+// stock is taken and counted as sold when an order holding it is paid.
+func (s *shopService) ReserveStock(ctx context.Context, req *entity.ReserveStockRequest) (*entity.StockReservation, error) {
+	req.Holder = entity.UserHolder(req.UserID)
+	req.MaxHolds = entity.MaxUserHolds
+
+	return s.repo.ReserveStock(ctx, req)
+}
+
+func (s *shopService) ReleaseReservation(ctx context.Context, req *entity.ReservationRequest) (*entity.StockReservation, error) {
+	req.Holder = entity.UserHolder(req.UserID)
+
+	return s.repo.ReleaseReservation(ctx, req)
+}
+
+func (s *shopService) ExpireReservations(ctx context.Context) error {
+	n, err := s.repo.ExpireReservations(ctx)
+	if err != nil {
+		return err
+	}
+
+	if n > 0 {
+		log.Info().Int64("count", n).Msg("service::ExpireReservations - Expired stale reservations")
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"bytes"
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *shopService) CreateReview(ctx context.Context, req *entity.CreateReviewRequest) (*entity.ProductReview, error) {
+	product, err := s.repo.GetDetailProduct(ctx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.UserID == req.UserID {
+		log.Warn().Any("payload", req).Msg("service::CreateReview - Seller reviewing own product")
+		return nil, errmsg.NewCustomErrors(403, errmsg.WithMessage("Tidak dapat mengulas produk sendiri"))
+	}
+
+	photos := make(entity.ReviewPhotos, 0, len(req.Photos))
+	for i, file := range req.Photos {
+		photo, err := s.storeReviewPhoto(ctx, req.ProductID, file)
+		if err != nil {
+			s.deleteReviewPhotos(ctx, photos)
+			if customErr, ok := err.(*errmsg.CustomError); ok {
+				customErr.Errors = map[string][]string{fmt.Sprintf("photos[%d]", i): customErr.Errors["image"]}
+			}
+			return nil, err
+		}
+		photos = append(photos, *photo)
+	}
+
+	review, err := s.repo.CreateReview(ctx, req, photos)
+	if err != nil {
+		s.deleteReviewPhotos(ctx, photos)
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (s *shopService) GetReviews(ctx context.Context, req *entity.ReviewsRequest) (*entity.ReviewsResponse, error) {
+	return s.repo.GetReviews(ctx, req)
+}
+
+func (s *shopService) ReplyReview(ctx context.Context, req *entity.ReplyReviewRequest) (*entity.ProductReview, error) {
+	review, err := s.repo.GetReview(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkProductOwner(ctx, review.ProductID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ReplyReview(ctx, req)
+}
+
+func (s *shopService) HideReview(ctx context.Context, req *entity.HideReviewRequest) (*entity.ProductReview, error) {
+	return s.repo.HideReview(ctx, req)
+}
+
+// storeReviewPhoto uploads one review photo, held to the same rules as product images.
+func (s *shopService) storeReviewPhoto(ctx context.Context, productID string, file *multipart.FileHeader) (*entity.ReviewPhoto, error) {
+	if file.Size > maxImageSize {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("image", "ukuran gambar maksimal 5MB."))
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("service::storeReviewPhoto - Failed to open photo")
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxImageSize+1))
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("service::storeReviewPhoto - Failed to read photo")
+		return nil, err
+	}
+	if len(data) > maxImageSize {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("image", "ukuran gambar maksimal 5MB."))
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("image", "format gambar harus jpg, png, atau webp."))
+	}
+
+	key := fmt.Sprintf("reviews/%s/%s.%s", productID, ulid.Make().String(), ext)
+
+	url, err := s.storage.Put(ctx, key, bytes.NewReader(data), contentType)
+	if err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("service::storeReviewPhoto - Failed to store photo")
+		return nil, err
+	}
+
+	return &entity.ReviewPhoto{URL: url, StorageKey: key}, nil
+}
+
+func (s *shopService) deleteReviewPhotos(ctx context.Context, photos entity.ReviewPhotos) {
+	for _, photo := range photos {
+		if err := s.storage.Delete(ctx, photo.StorageKey); err != nil {
+			log.Warn().Err(err).Str("key", photo.StorageKey).Msg("service::deleteReviewPhotos - Failed to remove orphaned photo")
+		}
+	}
+}
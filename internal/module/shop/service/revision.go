@@ -0,0 +1,113 @@
+package service
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (s *shopService) GetProductRevisions(ctx context.Context, req *entity.ProductRevisionsRequest) (*entity.ProductRevisionsResponse, error) {
+	if err := s.checkProductOwner(ctx, req.ProductID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetProductRevisions(ctx, req)
+}
+
+func (s *shopService) GetProductRevision(ctx context.Context, req *entity.ProductRevisionRequest) (*entity.ProductRevision, error) {
+	if err := s.checkProductOwner(ctx, req.ProductID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetProductRevision(ctx, req.ProductID, req.Revision)
+}
+
+func (s *shopService) DiffProductRevisions(ctx context.Context, req *entity.RevisionDiffRequest) (*entity.RevisionDiffResponse, error) {
+	if err := s.checkProductOwner(ctx, req.ProductID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	from, err := s.repo.GetProductRevision(ctx, req.ProductID, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.repo.GetProductRevision(ctx, req.ProductID, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.RevisionDiffResponse{
+		From:    from.Revision,
+		To:      to.Revision,
+		Changes: entity.DiffSnapshots(from.Snapshot, to.Snapshot),
+	}, nil
+}
+
+// RollbackProduct makes the product look like it did at an earlier revision and stores
+// that as a new revision, status included: rolling back to a draft revision takes the
+// product off sale, unscheduled, and to a published one puts it back on sale. Stock is
+// not rolled back: the product and SKUs that still exist keep their current stock, SKUs
+// brought back start empty.
+func (s *shopService) RollbackProduct(ctx context.Context, req *entity.ProductRevisionRequest) (*entity.UpdateProductRequest, error) {
+	product, err := s.repo.GetDetailProduct(ctx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.UserID != req.UserID {
+		log.Warn().Str("product_id", req.ProductID).Str("user_id", req.UserID).Msg("service::RollbackProduct - Product is not owned by user")
+		return nil, errmsg.NewCustomErrors(403, errmsg.WithMessage("Produk bukan milik anda"))
+	}
+
+	revision, err := s.repo.GetProductRevision(ctx, req.ProductID, req.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		snapshot = revision.Snapshot
+		stok     = make(map[string]int, len(product.Variants))
+		update   = &entity.UpdateProductRequest{
+			ID:                req.ProductID,
+			UserID:            req.UserID,
+			Name:              snapshot.Name,
+			Description:       snapshot.Description,
+			Kategori:          append(make([]string, 0, len(snapshot.Kategori)), snapshot.Kategori...),
+			Harga:             snapshot.Harga,
+			Stok:              product.Stok,
+			Merek:             snapshot.Merek,
+			LowStockThreshold: &snapshot.LowStockThreshold,
+			Options:           append(make([]entity.ProductOptionRequest, 0, len(snapshot.Options)), snapshot.Options...),
+			Variants:          make([]entity.ProductVariantRequest, 0, len(snapshot.Variants)),
+			RevertedFrom:      &revision.Revision,
+			Status:            snapshot.Status,
+		}
+	)
+
+	for _, v := range product.Variants {
+		stok[v.Code] = v.Stok
+	}
+	for _, v := range snapshot.Variants {
+		v.Stok = stok[v.Code]
+		update.Variants = append(update.Variants, v)
+	}
+
+	if update.HasVariants() {
+		if err := validateVariants(update.Options, update.Variants); err != nil {
+			log.Warn().Err(err).Any("payload", req).Msg("service::RollbackProduct - Invalid variants")
+			return nil, err
+		}
+		update.Harga, update.Stok = summarizeVariants(update.Variants)
+	}
+
+	resp, err := s.repo.UpdateProductByID(ctx, update)
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("service::RollbackProduct - Failed to roll back product")
+		return nil, err
+	}
+
+	return resp, nil
+}
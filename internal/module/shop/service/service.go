@@ -3,6 +3,7 @@ package service
 import (
 	"codebase-app/internal/module/shop/entity"
 	"codebase-app/internal/module/shop/ports"
+	"codebase-app/pkg/errmsg"
 	"context"
 
 	"github.com/rs/zerolog/log"
@@ -11,12 +12,14 @@ import (
 var _ ports.ShopService = &shopService{}
 
 type shopService struct {
-	repo ports.ShopRepository
+	repo    ports.ShopRepository
+	storage ports.FileStorage
 }
 
-func NewShopService(repo ports.ShopRepository) *shopService {
+func NewShopService(repo ports.ShopRepository, storage ports.FileStorage) *shopService {
 	return &shopService{
-		repo: repo,
+		repo:    repo,
+		storage: storage,
 	}
 }
 
@@ -40,16 +43,42 @@ func (s *shopService) GetShops(ctx context.Context, req *entity.ShopsRequest) (*
 	return s.repo.GetShops(ctx, req)
 }
 func (s *shopService) CreateProduct(ctx context.Context, req *entity.CreateProductRequest) (*entity.ProductResponse, error) {
+	if req.Status == "" {
+		req.Status = entity.ProductPublished
+	}
+
+	if err := checkPublishAt(req.Status, req.PublishAt); err != nil {
+		return nil, err
+	}
+
+	if req.HasVariants() {
+		if err := validateVariants(req.Options, req.Variants); err != nil {
+			log.Warn().Err(err).Any("payload", req).Msg("service::CreateProduct - Invalid variants")
+			return nil, err
+		}
+		req.Harga, req.Stok = summarizeVariants(req.Variants)
+	}
+
 	return s.repo.CreateProduct(ctx, req)
 }
-func (s *shopService) GetDetailShopAndProduct(ctx context.Context, id string, paginate int, page int) (*entity.DetailShopAndProduct, error) {
-	return s.repo.GetDetailShopAndProduct(ctx, id, paginate, page)
+func (s *shopService) GetDetailShopAndProduct(ctx context.Context, req *entity.DetailShopRequest) (*entity.DetailShopAndProduct, error) {
+	return s.repo.GetDetailShopAndProduct(ctx, req)
 }
 func (s *shopService) GetAllProduct(ctx context.Context, req *entity.ProductFilter) (*entity.ProductsResponse, error) {
 	return s.repo.GetAllProduct(ctx, req)
 }
 func (s *shopService) GetDetailProduct(ctx context.Context, id string) (*entity.ProductResponse, error) {
-	return s.repo.GetDetailProduct(ctx, id)
+	product, err := s.repo.GetDetailProduct(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// drafts and archived products are only shown to their owner, see PreviewProduct
+	if product.Status != entity.ProductPublished {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Produk tidak ditemukan"))
+	}
+
+	return product, nil
 }
 func (s *shopService) DeleteProductByID(ctx context.Context, id string) error {
 	return s.repo.DeleteProductByID(ctx, id)
@@ -62,16 +91,27 @@ func (s *shopService) UpdateProductByID(ctx context.Context, req *entity.UpdateP
 		return nil, err
 	}
 	log.Debug().Str("id", product.UserID).Msg("repository::Get Detail Product - ID User Product")
-	var err1 error
+
 	if product.UserID != req.UserID {
-		log.Error().Err(err).Any("payload", req).Msg("service::UpdateProductByID - UserID is not equal")
-		return nil, err1
+		log.Warn().Any("payload", req).Msg("service::UpdateProductByID - UserID is not equal")
+		return nil, errmsg.NewCustomErrors(403, errmsg.WithMessage("Produk bukan milik anda"))
 	}
 
-	resp, err2 := s.repo.UpdateProductByID(ctx, req)
-	if err2 != nil {
+	if req.HasVariants() {
+		if err := validateVariants(req.Options, req.Variants); err != nil {
+			log.Warn().Err(err).Any("payload", req).Msg("service::UpdateProductByID - Invalid variants")
+			return nil, err
+		}
+		req.Harga, req.Stok = summarizeVariants(req.Variants)
+	} else if req.Variants == nil && len(product.Variants) > 0 {
+		// price and stock of a variant product are owned by its SKUs
+		req.Harga, req.Stok = product.Harga, product.Stok
+	}
+
+	resp, err := s.repo.UpdateProductByID(ctx, req)
+	if err != nil {
 		log.Error().Err(err).Any("payload", req).Msg("service::UpdateProductByID - failed update product")
-		return nil, err1
+		return nil, err
 	}
 
 	return resp, nil
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (s *shopService) UpdateShopStatus(ctx context.Context, req *entity.UpdateShopStatusRequest) (*entity.ShopStatus, error) {
+	if err := s.repo.CheckShopOwner(ctx, req.ShopID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	switch req.Status {
+	case entity.ShopVacation:
+		if req.VacationUntil == nil {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("vacation_until", "wajib diisi saat libur."))
+		}
+		if !req.VacationUntil.After(time.Now()) {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors("vacation_until", "harus di masa depan."))
+		}
+	default:
+		req.VacationUntil = nil
+	}
+
+	return s.repo.UpdateShopStatus(ctx, req)
+}
+
+func (s *shopService) UpdateOpeningHours(ctx context.Context, req *entity.UpdateOpeningHoursRequest) (*entity.ShopStatus, error) {
+	if err := s.repo.CheckShopOwner(ctx, req.ShopID, req.UserID); err != nil {
+		return nil, err
+	}
+
+	for i, hour := range req.Hours {
+		field := fmt.Sprintf("hours[%d]", i)
+
+		open, ok := parseClock(hour.Open)
+		if !ok || open == 24*60 {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors(field+".open", "format jam HH:MM."))
+		}
+
+		close, ok := parseClock(hour.Close)
+		if !ok {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors(field+".close", "format jam HH:MM."))
+		}
+
+		if close <= open {
+			return nil, errmsg.NewCustomErrors(400, errmsg.WithErrors(field+".close", "harus setelah jam buka."))
+		}
+	}
+
+	return s.repo.UpdateOpeningHours(ctx, req)
+}
+
+func (s *shopService) CloseShop(ctx context.Context, req *entity.CloseShopRequest) (*entity.ShopStatus, error) {
+	return s.repo.CloseShop(ctx, req)
+}
+
+func (s *shopService) ReopenShop(ctx context.Context, req *entity.ReopenShopRequest) (*entity.ShopStatus, error) {
+	return s.repo.ReopenShop(ctx, req)
+}
+
+// parseClock reads an "HH:MM" time as minutes since midnight, "24:00" being the end of the day.
+func parseClock(s string) (int, bool) {
+	hh, mm, ok := strings.Cut(s, ":")
+	if !ok || len(hh) != 2 || len(mm) != 2 {
+		return 0, false
+	}
+
+	h, err := strconv.Atoi(hh)
+	if err != nil {
+		return 0, false
+	}
+
+	m, err := strconv.Atoi(mm)
+	if err != nil || m < 0 || m > 59 {
+		return 0, false
+	}
+
+	if h < 0 || h > 24 || (h == 24 && m != 0) {
+		return 0, false
+	}
+
+	return h*60 + m, true
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/types"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// validateVariants checks that option names and the values of an option do not
+// repeat, that every SKU picks exactly one declared value for every option axis,
+// that neither codes nor option combinations repeat, and that all SKUs are priced
+// in the same currency.
+func validateVariants(options []entity.ProductOptionRequest, variants []entity.ProductVariantRequest) error {
+	var (
+		errs    = errmsg.NewCustomErrors(400, errmsg.WithMessage("Varian produk tidak valid"))
+		axes    = make(map[string]map[string]bool, len(options))
+		codes   = make(map[string]bool, len(variants))
+		combos  = make(map[string]bool, len(variants))
+		optKeys = make([]string, 0, len(options))
+	)
+
+	for i, o := range options {
+		if _, exists := axes[o.Name]; exists {
+			errs.Add(fmt.Sprintf("options[%d].name", i), fmt.Sprintf("opsi %s duplikat.", o.Name))
+			continue
+		}
+
+		values := make(map[string]bool, len(o.Values))
+		for j, v := range o.Values {
+			if values[v] {
+				errs.Add(fmt.Sprintf("options[%d].values[%d]", i, j), fmt.Sprintf("nilai %s duplikat.", v))
+			}
+			values[v] = true
+		}
+		axes[o.Name] = values
+		optKeys = append(optKeys, o.Name)
+	}
+	sort.Strings(optKeys)
+
+	for i, v := range variants {
+		field := fmt.Sprintf("variants[%d]", i)
+
+		if codes[v.Code] {
+			errs.Add(field+".code", fmt.Sprintf("kode %s duplikat.", v.Code))
+		}
+		codes[v.Code] = true
+
+		if v.Harga.CurrencyCode() != variants[0].Harga.CurrencyCode() {
+			errs.Add(field+".harga", "mata uang harus sama untuk semua varian.")
+		}
+
+		if len(v.Options) != len(axes) {
+			errs.Add(field+".options", "setiap varian harus memilih satu nilai untuk setiap opsi.")
+			continue
+		}
+
+		combo := make([]string, 0, len(optKeys))
+		for _, name := range optKeys {
+			value, ok := v.Options[name]
+			if !ok {
+				errs.Add(field+".options", fmt.Sprintf("opsi %s harus diisi.", name))
+				continue
+			}
+			if !axes[name][value] {
+				errs.Add(field+".options", fmt.Sprintf("nilai %s tidak terdaftar pada opsi %s.", value, name))
+			}
+			combo = append(combo, name+"="+value)
+		}
+
+		key := strings.Join(combo, "&")
+		if combos[key] {
+			errs.Add(field+".options", "kombinasi opsi duplikat.")
+		}
+		combos[key] = true
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	return nil
+}
+
+// summarizeVariants returns the cheapest SKU price and the total SKU stock, which
+// are mirrored on the product row so listings and filters keep working unchanged.
+func summarizeVariants(variants []entity.ProductVariantRequest) (harga types.Money, stok int) {
+	for i, v := range variants {
+		if i == 0 || v.Harga.Amount < harga.Amount {
+			harga = v.Harga
+		}
+		stok += v.Stok
+	}
+
+	return harga, stok
+}
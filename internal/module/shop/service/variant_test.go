@@ -0,0 +1,127 @@
+package service
+
+import (
+	"codebase-app/internal/module/shop/entity"
+	"codebase-app/pkg/errmsg"
+	"codebase-app/pkg/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sizeColor() []entity.ProductOptionRequest {
+	return []entity.ProductOptionRequest{
+		{Name: "size", Values: []string{"M", "L"}},
+		{Name: "color", Values: []string{"red"}},
+	}
+}
+
+func sku(code, size, color string, harga int64, stok int) entity.ProductVariantRequest {
+	options := entity.VariantOptions{"size": size}
+	if color != "" {
+		options["color"] = color
+	}
+
+	return entity.ProductVariantRequest{Code: code, Options: options, Harga: types.NewMoney(harga, "IDR"), Stok: stok}
+}
+
+func TestValidateVariants(t *testing.T) {
+	cases := map[string]struct {
+		options  []entity.ProductOptionRequest
+		variants []entity.ProductVariantRequest
+		fields   []string
+	}{
+		"valid": {
+			options:  sizeColor(),
+			variants: []entity.ProductVariantRequest{sku("M-RED", "M", "red", 50000, 1), sku("L-RED", "L", "red", 55000, 2)},
+		},
+		"duplicate option name": {
+			options:  append(sizeColor(), entity.ProductOptionRequest{Name: "size", Values: []string{"XL"}}),
+			variants: []entity.ProductVariantRequest{sku("M-RED", "M", "red", 50000, 1)},
+			fields:   []string{"options[2].name"},
+		},
+		"duplicate option value": {
+			options:  []entity.ProductOptionRequest{{Name: "size", Values: []string{"M", "L", "M"}}},
+			variants: []entity.ProductVariantRequest{sku("M", "M", "", 50000, 1)},
+			fields:   []string{"options[0].values[2]"},
+		},
+		"missing option": {
+			options:  sizeColor(),
+			variants: []entity.ProductVariantRequest{sku("M", "M", "", 50000, 1)},
+			fields:   []string{"variants[0].options"},
+		},
+		"option not declared": {
+			options: sizeColor(),
+			variants: []entity.ProductVariantRequest{
+				{Code: "M", Options: entity.VariantOptions{"size": "M", "fit": "slim"}, Harga: types.NewMoney(50000, "IDR")},
+			},
+			fields: []string{"variants[0].options"},
+		},
+		"value not declared": {
+			options:  sizeColor(),
+			variants: []entity.ProductVariantRequest{sku("XL-RED", "XL", "red", 50000, 1)},
+			fields:   []string{"variants[0].options"},
+		},
+		"duplicate code": {
+			options:  sizeColor(),
+			variants: []entity.ProductVariantRequest{sku("KAOS", "M", "red", 50000, 1), sku("KAOS", "L", "red", 50000, 1)},
+			fields:   []string{"variants[1].code"},
+		},
+		"duplicate combination": {
+			options:  sizeColor(),
+			variants: []entity.ProductVariantRequest{sku("A", "M", "red", 50000, 1), sku("B", "M", "red", 50000, 1)},
+			fields:   []string{"variants[1].options"},
+		},
+		"mixed currency": {
+			options: sizeColor(),
+			variants: []entity.ProductVariantRequest{
+				sku("M-RED", "M", "red", 50000, 1),
+				{Code: "L-RED", Options: entity.VariantOptions{"size": "L", "color": "red"}, Harga: types.NewMoney(5, "USD")},
+			},
+			fields: []string{"variants[1].harga"},
+		},
+	}
+
+	for name, c := range cases {
+		err := validateVariants(c.options, c.variants)
+		if c.fields == nil {
+			assert.NoError(t, err, name)
+			continue
+		}
+
+		customErr, ok := err.(*errmsg.CustomError)
+		if assert.True(t, ok, name) {
+			assert.Equal(t, 400, customErr.Code, name)
+			for _, field := range c.fields {
+				assert.Contains(t, customErr.Errors, field, name)
+			}
+			assert.Len(t, customErr.Errors, len(c.fields), name)
+		}
+	}
+}
+
+func TestSummarizeVariants(t *testing.T) {
+	cases := map[string]struct {
+		variants []entity.ProductVariantRequest
+		harga    types.Money
+		stok     int
+	}{
+		"cheapest price, summed stock": {
+			variants: []entity.ProductVariantRequest{sku("L", "L", "", 55000, 3), sku("M", "M", "", 50000, 0), sku("S", "S", "", 52000, 7)},
+			harga:    types.NewMoney(50000, "IDR"),
+			stok:     10,
+		},
+		"first of equal prices": {
+			variants: []entity.ProductVariantRequest{sku("A", "M", "", 50000, 1), sku("B", "L", "", 50000, 1)},
+			harga:    types.NewMoney(50000, "IDR"),
+			stok:     2,
+		},
+		"none": {},
+	}
+
+	for name, c := range cases {
+		harga, stok := summarizeVariants(c.variants)
+		assert.Equal(t, c.harga, harga, name)
+		assert.Equal(t, c.stok, stok, name)
+	}
+}
@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"codebase-app/pkg/types"
+	"time"
+)
+
+// DefaultWishlistName is the name of the list a buyer saves to when no list is given.
+const DefaultWishlistName = "Favorit"
+
+type WishlistRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	Name   string `json:"name" validate:"required,max=100"`
+}
+
+type UpdateWishlistRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	Id     string `params:"id" validate:"uuid"`
+	Name   string `json:"name" validate:"required,max=100"`
+}
+
+type DeleteWishlistRequest struct {
+	UserID string `prop:"user_id" validate:"uuid"`
+	Id     string `params:"id" validate:"uuid"`
+}
+
+// AddWishlistItemRequest saves a product to WishlistID, or to the default list of the
+// buyer when it is empty. Saving a product twice keeps the first entry and its price.
+type AddWishlistItemRequest struct {
+	UserID     string `prop:"user_id" validate:"uuid"`
+	WishlistID string `json:"wishlist_id" validate:"omitempty,uuid"`
+	ProductID  string `json:"product_id" validate:"required,uuid"`
+}
+
+type DeleteWishlistItemRequest struct {
+	UserID     string `prop:"user_id" validate:"uuid"`
+	WishlistID string `query:"wishlist_id" validate:"omitempty,uuid"`
+	ProductID  string `params:"product_id" validate:"uuid"`
+}
+
+// WishlistItemsRequest lists the products of WishlistID, or of the default list when it
+// is empty, last saved first.
+type WishlistItemsRequest struct {
+	UserID     string `prop:"user_id" validate:"uuid"`
+	WishlistID string `query:"wishlist_id" validate:"omitempty,uuid"`
+	Page       int    `query:"page" validate:"required"`
+	Paginate   int    `query:"paginate" validate:"required,max=100"`
+}
+
+func (r *WishlistItemsRequest) SetDefault() {
+	if r.Page < 1 {
+		r.Page = 1
+	}
+
+	if r.Paginate < 1 {
+		r.Paginate = 10
+	}
+}
+
+type Wishlist struct {
+	ID        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	IsDefault bool      `json:"is_default" db:"is_default"`
+	ItemCount int       `json:"item_count" db:"item_count"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// WishlistItem is a saved product as it is now. Harga is its current price, the flash
+// price included, and SavedHarga the price when it was saved. Products deleted or taken
+// off sale since stay on the list as unavailable.
+type WishlistItem struct {
+	ID           string      `json:"id" db:"id"`
+	WishlistID   string      `json:"wishlist_id" db:"wishlist_id"`
+	ProductID    string      `json:"product_id" db:"product_id"`
+	ProductName  string      `json:"product_name" db:"product_name"`
+	ShopID       string      `json:"shop_id" db:"shop_id"`
+	ShopName     string      `json:"shop_name" db:"shop_name"`
+	Harga        types.Money `json:"harga" db:"harga"`
+	SavedHarga   types.Money `json:"saved_harga" db:"saved_harga"`
+	PriceDropped bool        `json:"price_dropped" db:"-"`
+	Stok         int         `json:"stok" db:"stok"`
+	Available    bool        `json:"available" db:"available"`
+	AddedAt      time.Time   `json:"added_at" db:"created_at"`
+}
+
+type WishlistsResponse struct {
+	Items []Wishlist `json:"items"`
+}
+
+type WishlistItemsResponse struct {
+	Wishlist Wishlist       `json:"wishlist"`
+	Items    []WishlistItem `json:"items"`
+	Meta     types.Meta     `json:"meta"`
+}
@@ -0,0 +1,28 @@
+package ports
+
+import (
+	"codebase-app/internal/module/wishlist/entity"
+	"context"
+)
+
+type WishlistRepository interface {
+	GetWishlists(ctx context.Context, userID string) ([]entity.Wishlist, error)
+	GetWishlist(ctx context.Context, userID, id string) (*entity.Wishlist, error)
+	DefaultWishlist(ctx context.Context, userID string) (*entity.Wishlist, error)
+	CreateWishlist(ctx context.Context, req *entity.WishlistRequest) (*entity.Wishlist, error)
+	UpdateWishlist(ctx context.Context, req *entity.UpdateWishlistRequest) (*entity.Wishlist, error)
+	DeleteWishlist(ctx context.Context, req *entity.DeleteWishlistRequest) error
+	AddWishlistItem(ctx context.Context, wishlistID, productID string) (*entity.WishlistItem, error)
+	DeleteWishlistItem(ctx context.Context, wishlistID, productID string) error
+	GetWishlistItems(ctx context.Context, wishlistID string, page, paginate int) ([]entity.WishlistItem, int, error)
+}
+
+type WishlistService interface {
+	GetWishlists(ctx context.Context, userID string) (*entity.WishlistsResponse, error)
+	CreateWishlist(ctx context.Context, req *entity.WishlistRequest) (*entity.Wishlist, error)
+	UpdateWishlist(ctx context.Context, req *entity.UpdateWishlistRequest) (*entity.Wishlist, error)
+	DeleteWishlist(ctx context.Context, req *entity.DeleteWishlistRequest) error
+	AddWishlistItem(ctx context.Context, req *entity.AddWishlistItemRequest) (*entity.WishlistItem, error)
+	DeleteWishlistItem(ctx context.Context, req *entity.DeleteWishlistItemRequest) error
+	GetWishlistItems(ctx context.Context, req *entity.WishlistItemsRequest) (*entity.WishlistItemsResponse, error)
+}
@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"codebase-app/internal/module/wishlist/entity"
+	"codebase-app/internal/module/wishlist/ports"
+	"codebase-app/pkg/errmsg"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+var _ ports.WishlistRepository = &wishlistRepository{}
+
+type wishlistRepository struct {
+	db *sqlx.DB
+}
+
+func NewWishlistRepository(db *sqlx.DB) *wishlistRepository {
+	return &wishlistRepository{
+		db: db,
+	}
+}
+
+const wishlistColumns = `
+	wishlists.id, wishlists.name, wishlists.is_default, wishlists.created_at, wishlists.updated_at,
+	(SELECT COUNT(id) FROM wishlist_items WHERE wishlist_items.wishlist_id = wishlists.id) AS item_count`
+
+// currentPrice is what a product sells for now: the flash price while its sale has quota
+// left, else its cheapest SKU or its own price. It needs the flash join below.
+const currentPrice = `COALESCE(
+	flash.harga,
+	(
+		SELECT MIN(harga) FROM product_variants
+		WHERE product_variants.product_id = product.id AND product_variants.deleted_at IS NULL
+	),
+	product.harga
+)`
+
+const flashJoin = `LEFT JOIN active_flash_sale_items flash ON flash.product_id = product.id AND flash.remaining > 0`
+
+func (r *wishlistRepository) GetWishlists(ctx context.Context, userID string) ([]entity.Wishlist, error) {
+	var resp = make([]entity.Wishlist, 0)
+
+	query := `
+		SELECT ` + wishlistColumns + `
+		FROM wishlists
+		WHERE user_id = ?
+		ORDER BY is_default DESC, created_at, id
+	`
+	if err := r.db.SelectContext(ctx, &resp, r.db.Rebind(query), userID); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("repository::GetWishlists - Failed to get wishlists")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// GetWishlist loads a list of userID; lists of other buyers are not found.
+func (r *wishlistRepository) GetWishlist(ctx context.Context, userID, id string) (*entity.Wishlist, error) {
+	var resp = new(entity.Wishlist)
+
+	query := `SELECT ` + wishlistColumns + ` FROM wishlists WHERE id = ? AND user_id = ?`
+	err := r.db.GetContext(ctx, resp, r.db.Rebind(query), id, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Wishlist tidak ditemukan"))
+	}
+	if err != nil {
+		log.Error().Err(err).Str("id", id).Msg("repository::GetWishlist - Failed to get wishlist")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// DefaultWishlist returns the default list of userID, creating it on first use.
+func (r *wishlistRepository) DefaultWishlist(ctx context.Context, userID string) (*entity.Wishlist, error) {
+	var resp = new(entity.Wishlist)
+
+	queryInsert := `
+		INSERT INTO wishlists (user_id, name, is_default)
+		VALUES (?, ?, true)
+		ON CONFLICT (user_id) WHERE is_default DO NOTHING
+	`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(queryInsert), userID, entity.DefaultWishlistName); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("repository::DefaultWishlist - Failed to create default wishlist")
+		return nil, err
+	}
+
+	query := `SELECT ` + wishlistColumns + ` FROM wishlists WHERE user_id = ? AND is_default`
+	if err := r.db.GetContext(ctx, resp, r.db.Rebind(query), userID); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("repository::DefaultWishlist - Failed to get default wishlist")
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (r *wishlistRepository) CreateWishlist(ctx context.Context, req *entity.WishlistRequest) (*entity.Wishlist, error) {
+	var id string
+
+	query := `INSERT INTO wishlists (user_id, name) VALUES (?, ?) RETURNING id`
+	err := r.db.GetContext(ctx, &id, r.db.Rebind(query), req.UserID, req.Name)
+	if err != nil {
+		return nil, nameError(err, req, "repository::CreateWishlist - Failed to insert wishlist")
+	}
+
+	return r.GetWishlist(ctx, req.UserID, id)
+}
+
+func (r *wishlistRepository) UpdateWishlist(ctx context.Context, req *entity.UpdateWishlistRequest) (*entity.Wishlist, error) {
+	query := `UPDATE wishlists SET name = ?, updated_at = NOW() WHERE id = ? AND user_id = ?`
+
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(query), req.Name, req.Id, req.UserID)
+	if err != nil {
+		return nil, nameError(err, req, "repository::UpdateWishlist - Failed to update wishlist")
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::UpdateWishlist - Failed to read affected rows")
+		return nil, err
+	}
+	if n == 0 {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Wishlist tidak ditemukan"))
+	}
+
+	return r.GetWishlist(ctx, req.UserID, req.Id)
+}
+
+// DeleteWishlist deletes a named list with its items; the default list stays.
+func (r *wishlistRepository) DeleteWishlist(ctx context.Context, req *entity.DeleteWishlistRequest) error {
+	list, err := r.GetWishlist(ctx, req.UserID, req.Id)
+	if err != nil {
+		return err
+	}
+
+	if list.IsDefault {
+		return errmsg.NewCustomErrors(400, errmsg.WithMessage("Wishlist default tidak dapat dihapus"))
+	}
+
+	query := `DELETE FROM wishlists WHERE id = ? AND user_id = ? AND NOT is_default`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), req.Id, req.UserID); err != nil {
+		log.Error().Err(err).Any("payload", req).Msg("repository::DeleteWishlist - Failed to delete wishlist")
+		return err
+	}
+
+	return nil
+}
+
+// AddWishlistItem saves a product to a list at its current price. A product already on
+// the list keeps the price it was first saved at.
+func (r *wishlistRepository) AddWishlistItem(ctx context.Context, wishlistID, productID string) (*entity.WishlistItem, error) {
+	var found bool
+
+	queryProduct := `SELECT EXISTS (SELECT 1 FROM product WHERE id = ? AND deleted_at IS NULL AND status = 'published')`
+	if err := r.db.GetContext(ctx, &found, r.db.Rebind(queryProduct), productID); err != nil {
+		log.Error().Err(err).Str("product_id", productID).Msg("repository::AddWishlistItem - Failed to get product")
+		return nil, err
+	}
+	if !found {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Produk tidak ditemukan"))
+	}
+
+	query := `
+		INSERT INTO wishlist_items (wishlist_id, product_id, harga, currency)
+		SELECT ?, product.id, ` + currentPrice + `, product.currency
+		FROM product
+		` + flashJoin + `
+		WHERE product.id = ?
+		ON CONFLICT (wishlist_id, product_id) DO NOTHING
+	`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), wishlistID, productID); err != nil {
+		log.Error().Err(err).Str("wishlist_id", wishlistID).Str("product_id", productID).Msg("repository::AddWishlistItem - Failed to save item")
+		return nil, err
+	}
+
+	queryTouch := `UPDATE wishlists SET updated_at = NOW() WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(queryTouch), wishlistID); err != nil {
+		log.Error().Err(err).Str("wishlist_id", wishlistID).Msg("repository::AddWishlistItem - Failed to touch wishlist")
+		return nil, err
+	}
+
+	items, err := r.getItems(ctx, `wishlist_items.wishlist_id = ? AND wishlist_items.product_id = ?`, wishlistID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, errmsg.NewCustomErrors(404, errmsg.WithMessage("Produk tidak ditemukan"))
+	}
+
+	return &items[0].WishlistItem, nil
+}
+
+func (r *wishlistRepository) DeleteWishlistItem(ctx context.Context, wishlistID, productID string) error {
+	query := `DELETE FROM wishlist_items WHERE wishlist_id = ? AND product_id = ?`
+
+	res, err := r.db.ExecContext(ctx, r.db.Rebind(query), wishlistID, productID)
+	if err != nil {
+		log.Error().Err(err).Str("wishlist_id", wishlistID).Str("product_id", productID).Msg("repository::DeleteWishlistItem - Failed to delete item")
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		log.Error().Err(err).Str("wishlist_id", wishlistID).Msg("repository::DeleteWishlistItem - Failed to read affected rows")
+		return err
+	}
+	if n == 0 {
+		return errmsg.NewCustomErrors(404, errmsg.WithMessage("Produk tidak ada di wishlist"))
+	}
+
+	return nil
+}
+
+// GetWishlistItems lists the items of a list, last saved first, with the total count.
+func (r *wishlistRepository) GetWishlistItems(ctx context.Context, wishlistID string, page, paginate int) ([]entity.WishlistItem, int, error) {
+	var (
+		resp  = make([]entity.WishlistItem, 0, paginate)
+		total int
+	)
+
+	data, err := r.getItems(ctx, `wishlist_items.wishlist_id = ? ORDER BY wishlist_items.created_at DESC, wishlist_items.id DESC LIMIT ? OFFSET ?`,
+		wishlistID, paginate, paginate*(page-1))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, d := range data {
+		resp = append(resp, d.WishlistItem)
+	}
+
+	if len(data) > 0 {
+		total = data[0].TotalData
+	}
+
+	return resp, total, nil
+}
+
+type itemDao struct {
+	TotalData int `db:"total_data"`
+	entity.WishlistItem
+}
+
+// itemAvailable tells whether a saved product can still be bought: it is published and
+// neither it nor its shop was deleted.
+const itemAvailable = `(product.deleted_at IS NULL AND product.status = 'published' AND shops.deleted_at IS NULL)`
+
+// getItems reads wishlist items with their product as it is now. Products that were
+// deleted or taken off sale, or whose shop was deleted, come back unavailable with no stock.
+func (r *wishlistRepository) getItems(ctx context.Context, where string, args ...any) ([]itemDao, error) {
+	var data []itemDao
+
+	query := `
+		SELECT
+			COUNT(wishlist_items.id) OVER() AS total_data,
+			wishlist_items.id,
+			wishlist_items.wishlist_id,
+			wishlist_items.product_id,
+			product.name AS product_name,
+			product.shop_id,
+			shops.name AS shop_name,
+			ROW(` + currentPrice + `, product.currency) AS harga,
+			ROW(wishlist_items.harga, wishlist_items.currency) AS saved_harga,
+			CASE WHEN ` + itemAvailable + ` THEN COALESCE((
+				SELECT SUM(stok) FROM product_variants
+				WHERE product_variants.product_id = product.id AND product_variants.deleted_at IS NULL
+			), product.stok) ELSE 0 END AS stok,
+			` + itemAvailable + ` AS available,
+			wishlist_items.created_at
+		FROM wishlist_items
+		JOIN product ON product.id = wishlist_items.product_id
+		JOIN shops ON shops.id = product.shop_id
+		` + flashJoin + `
+		WHERE ` + where
+
+	if err := r.db.SelectContext(ctx, &data, r.db.Rebind(query), args...); err != nil {
+		log.Error().Err(err).Any("payload", args).Msg("repository::getItems - Failed to get wishlist items")
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// nameError turns a clash with another list of the same buyer into a 409.
+func nameError(err error, req any, msg string) error {
+	pqErr, ok := err.(*pq.Error)
+	if ok && pqErr.Code.Name() == "unique_violation" {
+		log.Warn().Err(err).Any("payload", req).Msg(msg)
+		return errmsg.NewCustomErrors(409, errmsg.WithErrors("name", "nama wishlist sudah dipakai."))
+	}
+
+	log.Error().Err(err).Any("payload", req).Msg(msg)
+	return err
+}
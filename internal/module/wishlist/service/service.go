@@ -0,0 +1,115 @@
+package service
+
+import (
+	"codebase-app/internal/module/wishlist/entity"
+	"codebase-app/internal/module/wishlist/ports"
+	"context"
+)
+
+var _ ports.WishlistService = &wishlistService{}
+
+type wishlistService struct {
+	repo ports.WishlistRepository
+}
+
+func NewWishlistService(repo ports.WishlistRepository) *wishlistService {
+	return &wishlistService{
+		repo: repo,
+	}
+}
+
+func (s *wishlistService) GetWishlists(ctx context.Context, userID string) (*entity.WishlistsResponse, error) {
+	// the default list is always shown, even before anything was saved to it
+	if _, err := s.repo.DefaultWishlist(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.GetWishlists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.WishlistsResponse{Items: items}, nil
+}
+
+func (s *wishlistService) CreateWishlist(ctx context.Context, req *entity.WishlistRequest) (*entity.Wishlist, error) {
+	// make sure the default list holds its name before a named list can take it
+	if _, err := s.repo.DefaultWishlist(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.CreateWishlist(ctx, req)
+}
+
+func (s *wishlistService) UpdateWishlist(ctx context.Context, req *entity.UpdateWishlistRequest) (*entity.Wishlist, error) {
+	return s.repo.UpdateWishlist(ctx, req)
+}
+
+func (s *wishlistService) DeleteWishlist(ctx context.Context, req *entity.DeleteWishlistRequest) error {
+	return s.repo.DeleteWishlist(ctx, req)
+}
+
+func (s *wishlistService) AddWishlistItem(ctx context.Context, req *entity.AddWishlistItemRequest) (*entity.WishlistItem, error) {
+	list, err := s.resolveList(ctx, req.UserID, req.WishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.repo.AddWishlistItem(ctx, list.ID, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	setPriceDropped(item)
+
+	return item, nil
+}
+
+func (s *wishlistService) DeleteWishlistItem(ctx context.Context, req *entity.DeleteWishlistItemRequest) error {
+	list, err := s.resolveList(ctx, req.UserID, req.WishlistID)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteWishlistItem(ctx, list.ID, req.ProductID)
+}
+
+func (s *wishlistService) GetWishlistItems(ctx context.Context, req *entity.WishlistItemsRequest) (*entity.WishlistItemsResponse, error) {
+	var resp = new(entity.WishlistItemsResponse)
+
+	list, err := s.resolveList(ctx, req.UserID, req.WishlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, total, err := s.repo.GetWishlistItems(ctx, list.ID, req.Page, req.Paginate)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		setPriceDropped(&items[i])
+	}
+
+	resp.Wishlist = *list
+	resp.Items = items
+	resp.Meta.TotalData = total
+	resp.Meta.CountTotalPage(req.Page, req.Paginate, total)
+
+	return resp, nil
+}
+
+// resolveList returns the list wishlistID of userID, or the default list when it is empty.
+func (s *wishlistService) resolveList(ctx context.Context, userID, wishlistID string) (*entity.Wishlist, error) {
+	if wishlistID == "" {
+		return s.repo.DefaultWishlist(ctx, userID)
+	}
+
+	return s.repo.GetWishlist(ctx, userID, wishlistID)
+}
+
+// setPriceDropped flags an item that can be bought now for less than it was saved at.
+func setPriceDropped(item *entity.WishlistItem) {
+	item.PriceDropped = item.Available &&
+		item.Harga.CurrencyCode() == item.SavedHarga.CurrencyCode() &&
+		item.Harga.Amount < item.SavedHarga.Amount
+}
@@ -1,7 +1,13 @@
 package route
 
 import (
+	handlerCart "codebase-app/internal/module/cart/handler/rest"
+	handlerCategory "codebase-app/internal/module/category/handler/rest"
+	handlerFlashSale "codebase-app/internal/module/flashsale/handler/rest"
+	handlerOrder "codebase-app/internal/module/order/handler/rest"
+	handlerPromotion "codebase-app/internal/module/promotion/handler/rest"
 	handlerShop "codebase-app/internal/module/shop/handler/rest"
+	handlerWishlist "codebase-app/internal/module/wishlist/handler/rest"
 	"codebase-app/pkg/response"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,6 +20,12 @@ func SetupRoutes(app *fiber.App) {
 	)
 
 	handlerShop.NewShopHandler().Register(api)
+	handlerCategory.NewCategoryHandler().Register(api)
+	handlerOrder.NewOrderHandler().Register(api)
+	handlerCart.NewCartHandler().Register(api)
+	handlerPromotion.NewPromotionHandler().Register(api)
+	handlerFlashSale.NewFlashSaleHandler().Register(api)
+	handlerWishlist.NewWishlistHandler().Register(api)
 
 	// fallback route
 	app.Use(func(c *fiber.Ctx) error {
@@ -2,24 +2,28 @@ package pkg
 
 import "strings"
 
+// tsqueryOperators are the characters to_tsquery reads as syntax rather than as part of a
+// word: quotes, backslash escapes, the &, |, ! and <-> operators, grouping, and the :*
+// prefix and weight markers.
+const tsqueryOperators = `'\&|!():*<>`
+
+// SanitizeKeyword replaces every tsquery operator character in keyword with a space, so
+// what is left is only ever read as words. The text is meant to be bound as a parameter,
+// not written into SQL.
 func SanitizeKeyword(keyword string) string {
-	keyword = strings.ReplaceAll(keyword, "'", "''")  // handle single quote
-	keyword = strings.ReplaceAll(keyword, "&", "\\&") // escape special FTS characters
-	keyword = strings.ReplaceAll(keyword, "|", "\\|")
-	keyword = strings.ReplaceAll(keyword, "!", "\\!")
-	keyword = strings.ReplaceAll(keyword, "(", "\\(")
-	keyword = strings.ReplaceAll(keyword, ")", "\\)")
-	keyword = strings.ReplaceAll(keyword, ":", "\\:")
-	keyword = strings.ReplaceAll(keyword, "*", "\\*")
-	keyword = strings.ReplaceAll(keyword, "<", "\\<")
-	keyword = strings.ReplaceAll(keyword, ">", "\\>")
-	return keyword
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(tsqueryOperators, r) {
+			return ' '
+		}
+		return r
+	}, keyword)
 }
 
+// FormatKeywords turns free text into a tsquery matching any of its words by prefix, e.g.
+// "kaos pol" => "kaos:* | pol:*". It returns an empty string when no word is left.
 func FormatKeywords(keyword string) string {
-	keywords := strings.Split(keyword, " ")
+	keywords := strings.Fields(SanitizeKeyword(keyword))
 	for i, keyword := range keywords {
-		keyword = SanitizeKeyword(keyword)
 		keywords[i] = keyword + ":*"
 	}
 	return strings.Join(keywords, " | ")
@@ -0,0 +1,29 @@
+package pkg
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// ResizeToFit scales img down so its longest side is at most size pixels, keeping the
+// aspect ratio. Images that already fit are returned unchanged; nothing is upscaled.
+func ResizeToFit(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	if size <= 0 || (w <= size && h <= size) {
+		return img
+	}
+
+	if w >= h {
+		w, h = size, max(1, h*size/w)
+	} else {
+		w, h = max(1, w*size/h), size
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	return dst
+}
@@ -0,0 +1,27 @@
+package pkg
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResizeToFit(t *testing.T) {
+	cases := []struct {
+		w, h, size   int
+		wantW, wantH int
+	}{
+		{2048, 1536, 400, 400, 300},
+		{600, 1200, 150, 75, 150},
+		{100, 80, 400, 100, 80},
+		{3000, 1, 150, 150, 1},
+	}
+
+	for _, c := range cases {
+		got := ResizeToFit(image.NewRGBA(image.Rect(0, 0, c.w, c.h)), c.size).Bounds()
+
+		assert.Equal(t, c.wantW, got.Dx())
+		assert.Equal(t, c.wantH, got.Dy())
+	}
+}
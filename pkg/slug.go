@@ -0,0 +1,29 @@
+package pkg
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify lowercases s and joins its alphanumeric runs with "-", e.g. "Elektronik & Gadget" => "elektronik-gadget".
+// It mirrors the expression used by the category migration so generated slugs match migrated ones.
+func Slugify(s string) string {
+	var (
+		b      strings.Builder
+		hyphen bool
+	)
+
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+
+	return b.String()
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points at the last row of a page in keyset pagination. Sort is the listing
+// order the cursor was produced for, Value the row's sort key and Id its tie-breaker.
+type Cursor struct {
+	Sort  string `json:"s,omitempty"`
+	Value string `json:"v"`
+	Id    string `json:"i"`
+}
+
+// Encode returns the opaque, URL safe form of the cursor handed out to clients.
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses a cursor produced by Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Id == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
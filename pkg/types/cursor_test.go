@@ -0,0 +1,23 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := Cursor{Sort: "price_asc", Value: "150000", Id: "6f1c7a4e-9a43-4a59-8d8e-6c7f0f1b2a11"}
+
+	got, err := DecodeCursor(c.Encode())
+
+	assert.NoError(t, err)
+	assert.Equal(t, c, *got)
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, s := range []string{"", "not-base64!", "e30"} { // "e30" is base64 for "{}"
+		_, err := DecodeCursor(s)
+		assert.ErrorIs(t, err, ErrInvalidCursor, s)
+	}
+}
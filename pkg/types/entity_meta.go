@@ -5,6 +5,11 @@ type Meta struct {
 	Paginate  int `json:"paginate"`
 	TotalData int `json:"total_data"`
 	TotalPage int `json:"total_page"`
+
+	// Cursor echoes the keyset cursor the page was read from and NextCursor points at
+	// the page after it; NextCursor is empty on the last page.
+	Cursor     string `json:"cursor,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func (r *Meta) CountTotalPage(page, paginate, totalData int) {
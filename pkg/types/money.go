@@ -0,0 +1,160 @@
+package types
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is assumed for amounts that come without a currency code.
+const DefaultCurrency = "IDR"
+
+var ErrInvalidMoney = errors.New("invalid money")
+
+// currencyExponents is how many decimal places the minor unit of a currency has, where
+// it differs from ISO 4217 or from the default of 2. Rupiah is stored in whole rupiah,
+// as it is priced in practice, although ISO 4217 gives it 2 decimal places.
+var currencyExponents = map[string]int{
+	"IDR": 0,
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+}
+
+// CurrencyExponent returns the decimal places of the minor unit Money amounts of currency
+// are counted in: 0 for IDR, 2 for USD.
+func CurrencyExponent(currency string) int {
+	if exp, ok := currencyExponents[strings.ToUpper(currency)]; ok {
+		return exp
+	}
+	return 2
+}
+
+// Money is an amount in minor units of its currency, with its ISO 4217 currency code.
+// The minor unit is the one of CurrencyExponent, not always the ISO 4217 one: USD is
+// counted in cents, so Money{Amount: 1999, Currency: "USD"} is $19.99, but IDR is
+// counted in whole rupiah, so Money{Amount: 150000, Currency: "IDR"} is Rp150.000.
+// JSON carries the exponent along so clients never have to guess it.
+//
+// In Postgres the amount lives in a bigint column next to a char(3) currency
+// column. Value only writes the amount; Scan reads either a bare amount, which
+// gets DefaultCurrency, or a ROW(amount, currency) record.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// NewMoney returns amount minor units of currency, defaulting to DefaultCurrency.
+func NewMoney(amount int64, currency string) Money {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// CurrencyCode returns the currency of m, DefaultCurrency when it is unset.
+func (m Money) CurrencyCode() string {
+	if m.Currency == "" {
+		return DefaultCurrency
+	}
+	return m.Currency
+}
+
+// Exponent returns the decimal places of the minor unit Amount is counted in.
+func (m Money) Exponent() int {
+	return CurrencyExponent(m.CurrencyCode())
+}
+
+func (m Money) String() string {
+	return strconv.FormatInt(m.Amount, 10) + " " + m.CurrencyCode()
+}
+
+// Scan implements the sql.Scanner interface.
+func (m *Money) Scan(val any) error {
+	switch v := val.(type) {
+	case nil:
+		*m = Money{}
+		return nil
+	case int64:
+		*m = NewMoney(v, "")
+		return nil
+	case []byte:
+		return m.parse(string(v))
+	case string:
+		return m.parse(v)
+	default:
+		return fmt.Errorf("types: unsupported type %T for Money", val)
+	}
+}
+
+// parse reads "150000" or a record such as "(150000,IDR)".
+func (m *Money) parse(s string) error {
+	var currency string
+
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		amount, code, ok := strings.Cut(s[1:len(s)-1], ",")
+		if !ok {
+			return ErrInvalidMoney
+		}
+		s, currency = amount, strings.TrimSpace(code)
+	}
+
+	amount, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return ErrInvalidMoney
+	}
+
+	*m = NewMoney(amount, currency)
+	return nil
+}
+
+// Value implements the driver.Valuer interface. Only the amount is stored; the
+// currency goes to its own column.
+func (m Money) Value() (driver.Value, error) {
+	return m.Amount, nil
+}
+
+// MarshalJSON writes {"amount": 150000, "currency": "IDR", "exponent": 0}.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   int64  `json:"amount"`
+		Currency string `json:"currency"`
+		Exponent int    `json:"exponent"`
+	}{m.Amount, m.CurrencyCode(), m.Exponent()})
+}
+
+// UnmarshalJSON accepts {"amount": 150000, "currency": "IDR"} as well as a bare
+// amount, which is read in DefaultCurrency so older clients keep working. An exponent
+// sent along is ignored, amounts are always read in the minor unit of CurrencyExponent.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+
+	if len(b) > 0 && b[0] != '{' {
+		var amount int64
+		if err := json.Unmarshal(b, &amount); err != nil {
+			return ErrInvalidMoney
+		}
+		*m = NewMoney(amount, "")
+		return nil
+	}
+
+	type money Money
+	var v money
+	if err := json.Unmarshal(b, &v); err != nil {
+		return ErrInvalidMoney
+	}
+	if v.Currency != "" && len(v.Currency) != 3 {
+		return ErrInvalidMoney
+	}
+
+	*m = NewMoney(v.Amount, v.Currency)
+	return nil
+}
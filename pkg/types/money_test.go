@@ -0,0 +1,60 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoneyScan(t *testing.T) {
+	cases := map[string]struct {
+		val  any
+		want Money
+	}{
+		"bigint":     {int64(150000), Money{Amount: 150000, Currency: "IDR"}},
+		"text":       {[]byte("2500"), Money{Amount: 2500, Currency: "IDR"}},
+		"record":     {[]byte("(1999,USD)"), Money{Amount: 1999, Currency: "USD"}},
+		"null":       {nil, Money{}},
+		"record str": {"(0,SGD)", Money{Amount: 0, Currency: "SGD"}},
+	}
+
+	for name, c := range cases {
+		var m Money
+		assert.NoError(t, m.Scan(c.val), name)
+		assert.Equal(t, c.want, m, name)
+	}
+
+	var m Money
+	assert.ErrorIs(t, m.Scan([]byte("12.5")), ErrInvalidMoney)
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var m Money
+
+	assert.NoError(t, json.Unmarshal([]byte(`150000`), &m))
+	assert.Equal(t, Money{Amount: 150000, Currency: "IDR"}, m)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount": 1999, "currency": "usd"}`), &m))
+	assert.Equal(t, Money{Amount: 1999, Currency: "USD"}, m)
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount": 1, "currency": "RUPIAH"}`), &m), ErrInvalidMoney)
+
+	b, err := json.Marshal(Money{Amount: 500})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount": 500, "currency": "IDR", "exponent": 0}`, string(b))
+
+	b, err = json.Marshal(Money{Amount: 1999, Currency: "USD"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount": 1999, "currency": "USD", "exponent": 2}`, string(b))
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount": 1999, "currency": "USD", "exponent": 2}`), &m))
+	assert.Equal(t, Money{Amount: 1999, Currency: "USD"}, m)
+}
+
+func TestCurrencyExponent(t *testing.T) {
+	assert.Equal(t, 0, CurrencyExponent("IDR"))
+	assert.Equal(t, 0, CurrencyExponent("jpy"))
+	assert.Equal(t, 2, CurrencyExponent("USD"))
+	assert.Equal(t, 0, Money{Amount: 150000}.Exponent())
+}
@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"codebase-app/pkg/types"
 	"reflect"
 	"strings"
 
@@ -63,6 +64,14 @@ func NewValidator() *Validator {
 		log.Fatal().Err(err).Msg("Error while registering unique validator")
 	}
 
+	// money is validated by its amount, so tags like required and min=1 work on it
+	v.RegisterCustomTypeFunc(func(field reflect.Value) any {
+		if m, ok := field.Interface().(types.Money); ok {
+			return m.Amount
+		}
+		return nil
+	}, types.Money{})
+
 	validatorCustom.validator = v
 	// validatorCustom.trans = trans
 